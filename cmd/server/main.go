@@ -10,6 +10,8 @@ import (
 
 	"bookkeeper-backend/config"
 	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/jobs"
+	"bookkeeper-backend/internal/scheduler"
 	"bookkeeper-backend/routes"
 )
 
@@ -35,6 +37,18 @@ func main() {
 		IdleTimeout:  cfg.IdleTimeout,
 	}
 
+	var sched *scheduler.Scheduler
+	if cfg.JobsEnabled {
+		sched = scheduler.New(&db.JobRunStore{DB: gormDB}, logger)
+		for _, job := range jobs.Scheduled(sqlDB, gormDB) {
+			if err := sched.Add(job); err != nil {
+				logger.Error("scheduler setup failed", "error", err)
+				os.Exit(1)
+			}
+		}
+		sched.Start(context.Background())
+	}
+
 	go func() {
 		logger.Info("server starting", "port", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -54,6 +68,12 @@ func main() {
 		logger.Error("graceful shutdown failed", "error", err)
 		os.Exit(1)
 	}
+	if sched != nil {
+		if err := sched.Stop(ctx); err != nil {
+			logger.Error("scheduler shutdown failed", "error", err)
+			os.Exit(1)
+		}
+	}
 	logger.Info("server exited gracefully")
 }
 
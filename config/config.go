@@ -20,6 +20,7 @@ type Config struct {
 	DatabaseTimeout      time.Duration
 	RateLimitWindow      time.Duration
 	RateLimitMaxRequests int
+	JobsEnabled          bool // run the in-process background job scheduler
 
 	AccessTokenTTL        time.Duration
 	RefreshTokenTTL       time.Duration
@@ -45,6 +46,7 @@ func Load() *Config {
 		DatabaseTimeout:      parseDuration("DATABASE_TIMEOUT", "5s"),
 		RateLimitWindow:      parseDuration("RATE_LIMIT_WINDOW", "1m"),
 		RateLimitMaxRequests: parseInt("RATE_LIMIT_MAX_REQUESTS", 100),
+		JobsEnabled:          boolEnv("JOBS_ENABLED", true),
 
 		AccessTokenTTL:       parseDuration("ACCESS_TOKEN_TTL", "15m"),
 		RefreshTokenTTL:      parseDuration("REFRESH_TOKEN_TTL", "720h"),
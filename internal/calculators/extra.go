@@ -150,19 +150,32 @@ func SavingsGoal(goalAmount, currentSavings, monthlyContribution, annualReturn f
 		}
 		return months, 0, nil
 	}
-	// compute required monthly contribution using future value of annuity formula
-	// FV = P*(1+r)^n + PMT*(((1+r)^n -1)/r)
-	// solve for PMT
-	// assume years = 1..100, pick years such that it's reasonable? Instead return required monthly for 1 year horizon as example
-	years := 1
-	n := years * 12
-	factor := math.Pow(1+rate/12, float64(n))
+	// assume a 1 year horizon when no contribution is given
+	required, err := RequiredMonthlyContribution(goalAmount, currentSavings, 12, annualReturn)
+	if err != nil {
+		return 0, 0, err
+	}
+	return 12, required, nil
+}
+
+// RequiredMonthlyContribution returns the monthly contribution needed to grow
+// currentSavings to goalAmount in the given number of months. It is zero when
+// the savings alone already get there.
+func RequiredMonthlyContribution(goalAmount, currentSavings float64, months int, annualReturn float64) (float64, error) {
+	if goalAmount <= 0 {
+		return 0, errors.New("goal must be positive")
+	}
+	if months <= 0 {
+		return 0, errors.New("months must be positive")
+	}
+	// FV = P*(1+r)^n + PMT*(((1+r)^n -1)/r), solved for PMT
+	rate := annualReturn / 100
 	if rate == 0 {
-		required := (goalAmount - currentSavings) / float64(n)
-		return n, required, nil
+		return math.Max(0, (goalAmount-currentSavings)/float64(months)), nil
 	}
-	required := (goalAmount - currentSavings*factor) * (rate/12) / (factor - 1)
-	return n, required, nil
+	factor := math.Pow(1+rate/12, float64(months))
+	required := (goalAmount - currentSavings*factor) * (rate / 12) / (factor - 1)
+	return math.Max(0, required), nil
 }
 
 // CreditCardPayoffPlan computes payoff under snowball or avalanche methods.
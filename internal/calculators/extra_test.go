@@ -65,6 +65,23 @@ func TestSavingsGoal(t *testing.T) {
 	}
 }
 
+func TestRequiredMonthlyContribution(t *testing.T) {
+	req, err := RequiredMonthlyContribution(12000, 0, 24, 0)
+	if err != nil || req != 500 {
+		t.Fatalf("expected 500, got %v (%v)", req, err)
+	}
+	withReturn, _ := RequiredMonthlyContribution(12000, 0, 24, 5)
+	if withReturn <= 0 || withReturn >= 500 {
+		t.Fatalf("returns should lower the contribution, got %v", withReturn)
+	}
+	if done, _ := RequiredMonthlyContribution(1000, 2000, 6, 0); done != 0 {
+		t.Fatalf("expected 0 once savings cover the goal, got %v", done)
+	}
+	if _, err := RequiredMonthlyContribution(1000, 0, 0, 0); err == nil {
+		t.Fatalf("expected error for zero months")
+	}
+}
+
 func TestCreditCardPayoff(t *testing.T) {
 	cards := []CardInput{{Balance: 5000, APR: 18, MinPayment: 150}, {Balance: 2000, APR: 22, MinPayment: 50}}
 	res, err := CreditCardPayoff(cards, 100, "avalanche")
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AccountStore struct {
+	DB *gorm.DB
+}
+
+var (
+	ErrAccountClosed          = errors.New("account is closed")
+	ErrAccountNotEmpty        = errors.New("account balance must be zero to close")
+	ErrAccountHasTransactions = errors.New("account has transactions; archive or close it instead")
+)
+
+// ListByUser returns the open, unarchived accounts of every household the
+// user belongs to, excluding the ledger's external counterparty accounts.
+func (s *AccountStore) ListByUser(userID uint) ([]models.Account, error) {
+	var accounts []models.Account
+	err := s.DB.
+		Joins("JOIN household_members hm ON hm.household_id = accounts.household_id").
+		Where("hm.user_id = ? AND accounts.type <> ? AND accounts.archived_at IS NULL", userID, models.AccountTypeExternal).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// Balance returns the account's opening balance plus every posted leg.
+func (s *AccountStore) Balance(acc *models.Account) (int64, error) {
+	var sum int64
+	err := s.DB.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount_cents),0)").
+		Where("account_id = ?", acc.ID).
+		Scan(&sum).Error
+	if err != nil {
+		return 0, err
+	}
+	return acc.OpeningBalanceCents + sum, nil
+}
+
+// BalanceAsOf returns the balance including only legs that occurred at or before t.
+func (s *AccountStore) BalanceAsOf(acc *models.Account, t time.Time) (int64, error) {
+	var sum int64
+	err := s.DB.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount_cents),0)").
+		Where("account_id = ? AND occurred_at <= ?", acc.ID, t).
+		Scan(&sum).Error
+	if err != nil {
+		return 0, err
+	}
+	return acc.OpeningBalanceCents + sum, nil
+}
+
+// Balances computes current balances for several accounts in one query.
+func (s *AccountStore) Balances(accounts []models.Account) (map[uint]int64, error) {
+	out := make(map[uint]int64, len(accounts))
+	if len(accounts) == 0 {
+		return out, nil
+	}
+	ids := make([]uint, len(accounts))
+	for i, a := range accounts {
+		ids[i] = a.ID
+		out[a.ID] = a.OpeningBalanceCents
+	}
+	var rows []struct {
+		AccountID uint
+		Total     int64
+	}
+	err := s.DB.Model(&models.Transaction{}).
+		Select("account_id, COALESCE(SUM(amount_cents),0) AS total").
+		Where("account_id IN ?", ids).
+		Group("account_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.AccountID] += r.Total
+	}
+	return out, nil
+}
+
+// DailyBalances returns the closing balance for each UTC day from start to
+// end inclusive.
+func (s *AccountStore) DailyBalances(acc *models.Account, start, end time.Time) ([]models.BalancePoint, error) {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	if end.Before(start) {
+		return []models.BalancePoint{}, nil
+	}
+
+	var before int64
+	err := s.DB.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount_cents),0)").
+		Where("account_id = ? AND occurred_at < ?", acc.ID, start).
+		Scan(&before).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var legs []models.Transaction
+	err = s.DB.Select("amount_cents, occurred_at").
+		Where("account_id = ? AND occurred_at >= ? AND occurred_at < ?", acc.ID, start, end.AddDate(0, 0, 1)).
+		Order("occurred_at asc").
+		Find(&legs).Error
+	if err != nil {
+		return nil, err
+	}
+	deltas := map[string]int64{}
+	for _, l := range legs {
+		deltas[l.OccurredAt.UTC().Format("2006-01-02")] += l.AmountCents
+	}
+
+	balance := acc.OpeningBalanceCents + before
+	var points []models.BalancePoint
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		balance += deltas[key]
+		points = append(points, models.BalancePoint{Date: key, BalanceCents: balance})
+	}
+	return points, nil
+}
+
+// ListByHousehold returns the household's accounts, skipping archived ones
+// unless includeArchived is set.
+func (s *AccountStore) ListByHousehold(householdID uint, includeArchived bool) ([]models.Account, error) {
+	q := s.DB.Where("household_id = ? AND type <> ?", householdID, models.AccountTypeExternal)
+	if !includeArchived {
+		q = q.Where("archived_at IS NULL")
+	}
+	var accounts []models.Account
+	if err := q.Order("name asc").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// Archive hides the account from lists, totals and jobs; its history stays.
+func (s *AccountStore) Archive(acc *models.Account) error {
+	now := time.Now()
+	acc.ArchivedAt = &now
+	return s.DB.Model(acc).Update("archived_at", now).Error
+}
+
+// Unarchive restores an archived account, reopening it if it was closed.
+func (s *AccountStore) Unarchive(acc *models.Account) error {
+	acc.ArchivedAt, acc.ClosedAt = nil, nil
+	return s.DB.Model(acc).Updates(map[string]any{"archived_at": nil, "closed_at": nil}).Error
+}
+
+// Close archives the account once its balance is zero. When sweepTo is set
+// any remaining balance is first transferred there by userID; sweepTo must
+// use the account's currency.
+func (s *AccountStore) Close(acc *models.Account, sweepTo *models.Account, userID uint) error {
+	if acc.ClosedAt != nil {
+		return ErrAccountClosed
+	}
+	if sweepTo != nil && sweepTo.Currency != acc.Currency {
+		return ErrMixedCurrency
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		store := AccountStore{DB: tx}
+		balance, err := store.Balance(acc)
+		if err != nil {
+			return err
+		}
+		if balance != 0 {
+			if sweepTo == nil {
+				return ErrAccountNotEmpty
+			}
+			ledger := LedgerStore{DB: tx}
+			leg := &models.Transaction{
+				AccountID:   acc.ID,
+				UserID:      &userID,
+				AmountCents: -balance,
+				Currency:    acc.Currency,
+				Memo:        "Closing balance transfer",
+				OccurredAt:  time.Now(),
+			}
+			if _, err := ledger.PostTransfer(acc.HouseholdID, sweepTo.ID, leg); err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		acc.ArchivedAt, acc.ClosedAt = &now, &now
+		return tx.Model(acc).Updates(map[string]any{"archived_at": now, "closed_at": now}).Error
+	})
+}
+
+// Delete removes an account that never had any transactions.
+func (s *AccountStore) Delete(acc *models.Account) error {
+	var n int64
+	if err := s.DB.Model(&models.Transaction{}).Where("account_id = ?", acc.ID).Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrAccountHasTransactions
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("account_id = ?", acc.ID).Delete(&models.LowBalanceAlert{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(acc).Error
+	})
+}
+
+// ClaimLowBalanceAlert records that the user is being alerted about the
+// account's low balance and reports whether they had not been already. The
+// claim stands until ClearLowBalanceAlert is called.
+func (s *AccountStore) ClaimLowBalanceAlert(userID, accountID uint, balance int64) (bool, error) {
+	alert := models.LowBalanceAlert{UserID: userID, AccountID: accountID, BalanceCents: balance, CreatedAt: time.Now()}
+	res := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// ClearLowBalanceAlert forgets the user's low-balance alert for the account,
+// so the next drop is alerted again.
+func (s *AccountStore) ClearLowBalanceAlert(userID, accountID uint) error {
+	return s.DB.Where("user_id = ? AND account_id = ?", userID, accountID).Delete(&models.LowBalanceAlert{}).Error
+}
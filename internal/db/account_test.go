@@ -0,0 +1,129 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestAccountBalances(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.HouseholdMember{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	gdb.Create(&models.HouseholdMember{HouseholdID: 1, UserID: 7, Role: "owner"})
+	acc := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD", OpeningBalanceCents: 10000}
+	gdb.Create(&acc)
+	ledger := LedgerStore{DB: gdb}
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC) }
+	for _, leg := range []models.Transaction{
+		{AccountID: acc.ID, AmountCents: -2000, Currency: "USD", OccurredAt: day(1)},
+		{AccountID: acc.ID, AmountCents: 5000, Currency: "USD", OccurredAt: day(3)},
+		{AccountID: acc.ID, AmountCents: -500, Currency: "USD", OccurredAt: day(3)},
+	} {
+		leg := leg
+		if _, err := ledger.PostStandard(1, &leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+	store := AccountStore{DB: gdb}
+
+	bal, err := store.Balance(&acc)
+	if err != nil || bal != 12500 {
+		t.Fatalf("expected balance 12500, got %d (%v)", bal, err)
+	}
+
+	accounts, err := store.ListByUser(7)
+	if err != nil || len(accounts) != 1 {
+		t.Fatalf("expected only the real account, got %d (%v)", len(accounts), err)
+	}
+	balances, _ := store.Balances(accounts)
+	if balances[acc.ID] != 12500 {
+		t.Fatalf("batched balance mismatch: %d", balances[acc.ID])
+	}
+
+	points, err := store.DailyBalances(&acc, day(2), day(4))
+	if err != nil {
+		t.Fatalf("daily: %v", err)
+	}
+	want := []int64{8000, 12500, 12500}
+	if len(points) != len(want) {
+		t.Fatalf("expected %d points, got %d", len(want), len(points))
+	}
+	for i, p := range points {
+		if p.BalanceCents != want[i] {
+			t.Errorf("%s: expected %d got %d", p.Date, want[i], p.BalanceCents)
+		}
+	}
+}
+
+func TestAccountCloseRequiresZeroBalance(t *testing.T) {
+	gdb := newTestDB(t)
+	checking := models.Account{HouseholdID: 1, Name: "Old checking", Type: "checking", Currency: "USD", OpeningBalanceCents: 1500}
+	savings := models.Account{HouseholdID: 1, Name: "Savings", Type: "savings", Currency: "USD"}
+	gdb.Create(&checking)
+	gdb.Create(&savings)
+	store := AccountStore{DB: gdb}
+
+	if err := store.Close(&checking, nil, 1); !errors.Is(err, ErrAccountNotEmpty) {
+		t.Fatalf("expected ErrAccountNotEmpty, got %v", err)
+	}
+	euros := models.Account{HouseholdID: 1, Name: "Euro savings", Type: "savings", Currency: "EUR"}
+	gdb.Create(&euros)
+	if err := store.Close(&checking, &euros, 1); !errors.Is(err, ErrMixedCurrency) || checking.ClosedAt != nil {
+		t.Fatalf("expected ErrMixedCurrency sweeping into another currency, got %v", err)
+	}
+	gdb.Delete(&euros)
+	if err := store.Close(&checking, &savings, 1); err != nil {
+		t.Fatalf("close with sweep: %v", err)
+	}
+	if bal, _ := store.Balance(&checking); bal != 0 {
+		t.Fatalf("expected zero final balance, got %d", bal)
+	}
+	if bal, _ := store.Balance(&savings); bal != 1500 {
+		t.Fatalf("expected swept balance on savings, got %d", bal)
+	}
+	open, _ := store.ListByHousehold(1, false)
+	if len(open) != 1 || open[0].ID != savings.ID {
+		t.Fatalf("closed account must be hidden by default, got %+v", open)
+	}
+	ledger := LedgerStore{DB: gdb}
+	if _, err := ledger.PostStandard(1, &models.Transaction{AccountID: checking.ID, AmountCents: 100, Currency: "USD"}); !errors.Is(err, ErrAccountClosed) {
+		t.Fatalf("expected ErrAccountClosed, got %v", err)
+	}
+	if err := store.Delete(&checking); !errors.Is(err, ErrAccountHasTransactions) {
+		t.Fatalf("expected ErrAccountHasTransactions, got %v", err)
+	}
+}
+
+func TestLowBalanceAlertClaimedUntilCleared(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.LowBalanceAlert{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	acc := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&acc)
+	store := AccountStore{DB: gdb}
+
+	if claimed, err := store.ClaimLowBalanceAlert(7, acc.ID, 500); err != nil || !claimed {
+		t.Fatalf("expected first claim, got %v (%v)", claimed, err)
+	}
+	if claimed, _ := store.ClaimLowBalanceAlert(7, acc.ID, 400); claimed {
+		t.Fatalf("a standing alert must not be claimed again")
+	}
+	if claimed, _ := store.ClaimLowBalanceAlert(8, acc.ID, 400); !claimed {
+		t.Fatalf("another member is alerted separately")
+	}
+	if err := store.ClearLowBalanceAlert(7, acc.ID); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if claimed, _ := store.ClaimLowBalanceAlert(7, acc.ID, 300); !claimed {
+		t.Fatalf("expected a new drop to be claimed after recovery")
+	}
+	card := models.Account{Type: "credit"}
+	if !card.IsLiability() || acc.IsLiability() {
+		t.Fatalf("only the credit account is a liability")
+	}
+}
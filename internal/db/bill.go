@@ -1,21 +1,179 @@
 package db
 
 import (
+	"errors"
 	"time"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/recurrence"
+)
+
+var (
+	ErrBillClosed      = errors.New("bill is closed")
+	ErrBillAlreadyPaid = errors.New("this occurrence of the bill is already paid")
+	ErrPaymentLinked   = errors.New("transaction already pays a bill")
 )
 
 type BillStore struct {
 	DB *gorm.DB
 }
 
-func (s *BillStore) ListDueInDays(userID uint, days int) ([]models.Bill, error) {
+// ListDueInDays returns the household's open bills due within days whose
+// next occurrence has not been reminded of yet.
+func (s *BillStore) ListDueInDays(householdID uint, days int) ([]models.Bill, error) {
 	var bills []models.Bill
 	now := time.Now()
 	future := now.Add(time.Duration(days) * 24 * time.Hour)
-	if err := s.DB.Where("user_id = ? AND next_due >= ? AND next_due <= ?", userID, now, future).Find(&bills).Error; err != nil {
+	if err := s.DB.Where("household_id = ? AND closed_at IS NULL AND next_due >= ? AND next_due <= ?", householdID, now, future).
+		Where("NOT EXISTS (SELECT 1 FROM bill_reminders r WHERE r.bill_id = bills.id AND r.due_date = bills.next_due)").
+		Order("next_due asc, id asc").Find(&bills).Error; err != nil {
 		return nil, err
 	}
 	return bills, nil
 }
+
+// RecordReminders marks each bill's next occurrence as reminded.
+func (s *BillStore) RecordReminders(bills []models.Bill) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		for _, b := range bills {
+			r := models.BillReminder{BillID: b.ID, DueDate: b.NextDue, CreatedAt: time.Now()}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&r).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListByHousehold returns the household's bills by next due date. Closed
+// bills are only included when asked for.
+func (s *BillStore) ListByHousehold(householdID uint, includeClosed bool) ([]models.Bill, error) {
+	q := s.DB.Where("household_id = ?", householdID)
+	if !includeClosed {
+		q = q.Where("closed_at IS NULL")
+	}
+	var bills []models.Bill
+	err := q.Order("next_due asc, id asc").Find(&bills).Error
+	return bills, err
+}
+
+func (s *BillStore) Get(householdID, id uint) (*models.Bill, error) {
+	var b models.Bill
+	if err := s.DB.Where("id = ? AND household_id = ?", id, householdID).First(&b).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// SetSchedule sets the bill's rule (empty for a one-time bill) and re-anchors
+// it at nextDue. The rule is stored in canonical form.
+func SetSchedule(b *models.Bill, rrule string, nextDue time.Time) error {
+	b.RRule = ""
+	if rrule != "" {
+		rule, err := recurrence.Parse(rrule)
+		if err != nil {
+			return err
+		}
+		b.RRule = rule.String()
+	}
+	b.Recurring = b.RRule != ""
+	b.NextDue = nextDue
+	b.StartsOn = nextDue
+	b.DueDay = nextDue.Day()
+	return nil
+}
+
+func (s *BillStore) Create(b *models.Bill) error {
+	return s.DB.Create(b).Error
+}
+
+func (s *BillStore) Save(b *models.Bill) error {
+	return s.DB.Save(b).Error
+}
+
+func (s *BillStore) Delete(b *models.Bill) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bill_id = ?", b.ID).Delete(&models.BillPayment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(b).Error
+	})
+}
+
+// Upcoming returns the bill's next n due dates, starting with NextDue.
+func Upcoming(b *models.Bill, n int) []time.Time {
+	if b.ClosedAt != nil || n <= 0 {
+		return []time.Time{}
+	}
+	out := []time.Time{b.NextDue}
+	if rule, err := recurrence.Parse(b.RRule); err == nil && b.Recurring {
+		out = append(out, rule.Occurrences(b.StartsOn, b.NextDue, n-1)...)
+	}
+	return out
+}
+
+// MarkPaid records trx as paying the bill's current occurrence and advances
+// NextDue to the following one; a one-time bill, or a rule with no further
+// occurrences, is closed instead. The advance is conditional on NextDue being
+// unchanged, so concurrent calls cannot pay the same occurrence twice.
+func (s *BillStore) MarkPaid(b *models.Bill, trx *models.Transaction, userID uint) (*models.BillPayment, error) {
+	if b.ClosedAt != nil {
+		return nil, ErrBillClosed
+	}
+	payment := &models.BillPayment{
+		BillID:        b.ID,
+		DueDate:       b.NextDue,
+		TransactionID: trx.ID,
+		UserID:        userID,
+		AmountCents:   trx.AmountCents,
+		PaidAt:        time.Now(),
+	}
+	updates := map[string]any{}
+	next, hasNext := time.Time{}, false
+	if b.Recurring {
+		if rule, err := recurrence.Parse(b.RRule); err == nil {
+			next, hasNext = rule.Next(b.StartsOn, b.NextDue)
+		}
+	}
+	if hasNext {
+		updates["next_due"] = next
+		updates["due_day"] = next.Day()
+	} else {
+		updates["closed_at"] = payment.PaidAt
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&models.BillPayment{}).Where("transaction_id = ?", trx.ID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrPaymentLinked
+		}
+		res := tx.Model(&models.Bill{}).Where("id = ? AND next_due = ? AND closed_at IS NULL", b.ID, b.NextDue).Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrBillAlreadyPaid
+		}
+		return tx.Create(payment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if hasNext {
+		b.NextDue, b.DueDay = next, next.Day()
+	} else {
+		b.ClosedAt = &payment.PaidAt
+	}
+	return payment, nil
+}
+
+// Payments returns the bill's payments, most recent first.
+func (s *BillStore) Payments(billID uint) ([]models.BillPayment, error) {
+	var out []models.BillPayment
+	err := s.DB.Where("bill_id = ?", billID).Order("due_date desc").Find(&out).Error
+	return out, err
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestBillMarkPaidAdvancesSchedule(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Bill{}, &models.BillPayment{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	acc := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&acc)
+	ledger := LedgerStore{DB: gdb}
+	pay := func() *models.Transaction {
+		t.Helper()
+		leg := &models.Transaction{AccountID: acc.ID, AmountCents: -120000, Currency: "USD", OccurredAt: time.Now()}
+		if _, err := ledger.PostStandard(1, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		return leg
+	}
+	store := BillStore{DB: gdb}
+
+	rent := &models.Bill{UserID: 7, HouseholdID: 1, Name: "Rent", AmountCents: 120000}
+	if err := SetSchedule(rent, "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", time.Date(2025, 5, 30, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	store.Create(rent)
+	if up := Upcoming(rent, 3); len(up) != 3 || up[2].Day() != 31 || up[2].Month() != time.July {
+		t.Fatalf("unexpected upcoming dates: %v", up)
+	}
+
+	first := pay()
+	payment, err := store.MarkPaid(rent, first, 7)
+	if err != nil || !payment.DueDate.Equal(time.Date(2025, 5, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("mark paid: %v (%v)", err, payment)
+	}
+	if !rent.NextDue.Equal(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)) || rent.DueDay != 30 {
+		t.Fatalf("expected next due June 30, got %v", rent.NextDue)
+	}
+	if _, err := store.MarkPaid(rent, first, 7); !errors.Is(err, ErrPaymentLinked) {
+		t.Fatalf("expected transaction reuse to be refused, got %v", err)
+	}
+	stale := *rent
+	stale.NextDue = time.Date(2025, 5, 30, 0, 0, 0, 0, time.UTC)
+	if _, err := store.MarkPaid(&stale, pay(), 7); !errors.Is(err, ErrBillAlreadyPaid) {
+		t.Fatalf("expected paid occurrence to be refused, got %v", err)
+	}
+	if payments, _ := store.Payments(rent.ID); len(payments) != 1 {
+		t.Fatalf("expected 1 payment, got %d", len(payments))
+	}
+
+	once := &models.Bill{UserID: 7, HouseholdID: 1, Name: "Car registration", AmountCents: 9000}
+	SetSchedule(once, "", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	store.Create(once)
+	if _, err := store.MarkPaid(once, pay(), 7); err != nil || once.ClosedAt == nil {
+		t.Fatalf("one-time bill should close when paid: %v", err)
+	}
+	if open, _ := store.ListByHousehold(1, false); len(open) != 1 || open[0].ID != rent.ID {
+		t.Fatalf("closed bill should not be listed: %v", open)
+	}
+	if _, err := store.MarkPaid(once, pay(), 7); !errors.Is(err, ErrBillClosed) {
+		t.Fatalf("expected closed bill, got %v", err)
+	}
+}
+
+func TestBillRemindersAreRecordedOnce(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Bill{}, &models.BillReminder{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	store := BillStore{DB: gdb}
+	soon := time.Now().Add(48 * time.Hour)
+	// Created by different members of the same household.
+	rent := &models.Bill{UserID: 7, HouseholdID: 1, Name: "Rent", AmountCents: 120000, NextDue: soon}
+	power := &models.Bill{UserID: 8, HouseholdID: 1, Name: "Power", AmountCents: 9000, NextDue: soon}
+	later := &models.Bill{UserID: 7, HouseholdID: 1, Name: "Water", AmountCents: 4000, NextDue: soon.AddDate(0, 0, 10)}
+	other := &models.Bill{UserID: 9, HouseholdID: 2, Name: "Rent", AmountCents: 80000, NextDue: soon}
+	for _, b := range []*models.Bill{rent, power, later, other} {
+		store.Create(b)
+	}
+
+	due, err := store.ListDueInDays(1, 3)
+	if err != nil || len(due) != 2 {
+		t.Fatalf("expected both household bills, got %+v (%v)", due, err)
+	}
+	if err := store.RecordReminders(due); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if due, _ := store.ListDueInDays(1, 3); len(due) != 0 {
+		t.Fatalf("reminded bills listed again: %+v", due)
+	}
+	// The next occurrence is reminded afresh.
+	gdb.Model(rent).Update("next_due", soon.Add(time.Hour))
+	if due, _ := store.ListDueInDays(1, 3); len(due) != 1 || due[0].ID != rent.ID {
+		t.Fatalf("expected the moved occurrence, got %+v", due)
+	}
+}
@@ -1,10 +1,16 @@
 package db
 
 import (
+	"errors"
+	"sort"
+	"time"
+
 	"gorm.io/gorm"
 	"bookkeeper-backend/internal/models"
 )
 
+var ErrInvalidMove = errors.New("invalid budget move")
+
 type BudgetStore struct {
 	DB *gorm.DB
 }
@@ -16,3 +22,202 @@ func (s *BudgetStore) GetBudgetByID(id uint) (*models.Budget, error) {
 	}
 	return &b, nil
 }
+
+// MonthActuals sums a household's spending per category over [start, end)
+// in a single query: unsplit legs by their own category plus split lines
+// allocated to each category. Only the household's real accounts count.
+func (s *BudgetStore) MonthActuals(householdID uint, start, end time.Time) (map[uint]int64, error) {
+	months, err := s.monthlyActuals(householdID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	out := map[uint]int64{}
+	for _, actuals := range months {
+		for id, cents := range actuals {
+			out[id] += cents
+		}
+	}
+	return out, nil
+}
+
+// monthlyActuals is MonthActuals broken down by UTC month (YYYY-MM), still
+// in a single query.
+func (s *BudgetStore) monthlyActuals(householdID uint, start, end time.Time) (map[string]map[uint]int64, error) {
+	type row struct {
+		Month       string
+		CategoryID  uint
+		ActualCents int64
+	}
+	var rows []row
+	err := s.DB.Raw(`
+		SELECT strftime('%Y-%m', occurred_at) AS month, category_id, COALESCE(SUM(amount_cents), 0) AS actual_cents FROM (
+			SELECT t.occurred_at AS occurred_at, t.category_id AS category_id, t.amount_cents AS amount_cents
+			FROM transactions t
+			JOIN accounts a ON a.id = t.account_id
+			WHERE a.household_id = ? AND a.type <> ? AND t.category_id IS NOT NULL
+				AND t.occurred_at >= ? AND t.occurred_at < ?
+			UNION ALL
+			SELECT t.occurred_at, sp.category_id, sp.amount_cents
+			FROM transaction_splits sp
+			JOIN transactions t ON t.id = sp.transaction_id
+			JOIN accounts a ON a.id = t.account_id
+			WHERE a.household_id = ? AND a.type <> ?
+				AND t.occurred_at >= ? AND t.occurred_at < ?
+		) GROUP BY month, category_id`,
+		householdID, models.AccountTypeExternal, start, end,
+		householdID, models.AccountTypeExternal, start, end,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]map[uint]int64{}
+	for _, r := range rows {
+		if out[r.Month] == nil {
+			out[r.Month] = map[uint]int64{}
+		}
+		out[r.Month][r.CategoryID] = r.ActualCents
+	}
+	return out, nil
+}
+
+// Envelope is one category's budget position for a month. ActualCents is the
+// signed net of its transactions (spending is negative), so
+// AvailableCents = PlannedCents + CarriedCents + MovedCents + ActualCents.
+type Envelope struct {
+	CategoryID     uint   `json:"category_id"`
+	Month          string `json:"month"`
+	Rollover       bool   `json:"rollover"`
+	PlannedCents   int64  `json:"planned_cents"`
+	CarriedCents   int64  `json:"carried_cents"`
+	MovedCents     int64  `json:"moved_cents"`
+	ActualCents    int64  `json:"actual_cents"`
+	AvailableCents int64  `json:"available_cents"`
+}
+
+// Envelopes returns the household's envelopes for month (YYYY-MM): every
+// category that is budgeted, had money moved, or carries a balance in. The
+// carried amount is replayed month by month from the oldest rollover budget,
+// so a month without a rollover budget ends the category's chain; the actuals
+// for the whole range come from one query.
+func (s *BudgetStore) Envelopes(householdID uint, month string) ([]Envelope, error) {
+	var budgets []models.Budget
+	if err := s.DB.Where("household_id = ? AND month <= ?", householdID, month).Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	var moves []models.BudgetMove
+	if err := s.DB.Where("household_id = ? AND month <= ?", householdID, month).Find(&moves).Error; err != nil {
+		return nil, err
+	}
+
+	first := month
+	planned := map[string]map[uint]models.Budget{}
+	for _, b := range budgets {
+		if planned[b.Month] == nil {
+			planned[b.Month] = map[uint]models.Budget{}
+		}
+		planned[b.Month][b.CategoryID] = b
+		if b.Rollover && b.Month < first {
+			first = b.Month
+		}
+	}
+	moved := map[string]map[uint]int64{}
+	for _, m := range moves {
+		if moved[m.Month] == nil {
+			moved[m.Month] = map[uint]int64{}
+		}
+		moved[m.Month][m.FromCategoryID] -= m.AmountCents
+		moved[m.Month][m.ToCategoryID] += m.AmountCents
+	}
+
+	start, err := time.Parse("2006-01", first)
+	if err != nil {
+		return nil, err
+	}
+	last, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, err
+	}
+	actuals, err := s.monthlyActuals(householdID, start, last.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	carry := map[uint]int64{}
+	var out []Envelope
+	for {
+		m := start.Format("2006-01")
+		cats := map[uint]struct{}{}
+		for id := range planned[m] {
+			cats[id] = struct{}{}
+		}
+		for id := range moved[m] {
+			cats[id] = struct{}{}
+		}
+		for id := range carry {
+			cats[id] = struct{}{}
+		}
+		next := map[uint]int64{}
+		for id := range cats {
+			b := planned[m][id]
+			e := Envelope{
+				CategoryID:   id,
+				Month:        m,
+				Rollover:     b.Rollover,
+				PlannedCents: b.PlannedCents,
+				CarriedCents: carry[id],
+				MovedCents:   moved[m][id],
+				ActualCents:  actuals[m][id],
+			}
+			e.AvailableCents = e.PlannedCents + e.CarriedCents + e.MovedCents + e.ActualCents
+			if b.Rollover {
+				next[id] = e.AvailableCents
+			}
+			if m == month {
+				out = append(out, e)
+			}
+		}
+		if m == month {
+			break
+		}
+		carry = next
+		start = start.AddDate(0, 1, 0)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CategoryID < out[j].CategoryID })
+	return out, nil
+}
+
+// MoveMoney records amount moved from one category's envelope to another's
+// for month. Both categories must belong to the household.
+func (s *BudgetStore) MoveMoney(householdID, userID uint, month string, fromID, toID uint, amount int64, memo string) (*models.BudgetMove, error) {
+	if amount <= 0 || fromID == 0 || toID == 0 || fromID == toID {
+		return nil, ErrInvalidMove
+	}
+	var n int64
+	if err := s.DB.Model(&models.Category{}).Where("household_id = ? AND id IN ?", householdID, []uint{fromID, toID}).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n != 2 {
+		return nil, ErrInvalidMove
+	}
+	mv := &models.BudgetMove{
+		HouseholdID:    householdID,
+		Month:          month,
+		FromCategoryID: fromID,
+		ToCategoryID:   toID,
+		AmountCents:    amount,
+		UserID:         userID,
+		Memo:           memo,
+	}
+	if err := s.DB.Create(mv).Error; err != nil {
+		return nil, err
+	}
+	return mv, nil
+}
+
+// ListMoves returns the household's recorded moves for month, newest first.
+func (s *BudgetStore) ListMoves(householdID uint, month string) ([]models.BudgetMove, error) {
+	var out []models.BudgetMove
+	err := s.DB.Where("household_id = ? AND month = ?", householdID, month).
+		Order("created_at desc, id desc").
+		Find(&out).Error
+	return out, err
+}
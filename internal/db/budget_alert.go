@@ -0,0 +1,189 @@
+package db
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidThresholds = errors.New("thresholds must be 1 to 10 distinct percentages between 1 and 1000")
+
+// maxBudgetAlertThresholds caps how many thresholds a household can set.
+const maxBudgetAlertThresholds = 10
+
+// BudgetAlertHit is a category whose spending newly crossed one or more
+// thresholds. Threshold is the highest one crossed.
+type BudgetAlertHit struct {
+	CategoryID   uint   `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Month        string `json:"month"`
+	Threshold    int    `json:"threshold"`
+	SpentCents   int64  `json:"spent_cents"`
+	PlannedCents int64  `json:"planned_cents"`
+}
+
+type BudgetAlertStore struct {
+	DB *gorm.DB
+}
+
+// Thresholds returns the household's alert thresholds in ascending order.
+func (s *BudgetAlertStore) Thresholds(householdID uint) ([]int, error) {
+	var h models.Household
+	if err := s.DB.Select("id", "budget_alert_thresholds").First(&h, householdID).Error; err != nil {
+		return nil, err
+	}
+	return parsePercents(h.BudgetAlertThresholds, models.DefaultBudgetAlertThresholds), nil
+}
+
+// SetThresholds validates and stores the household's alert thresholds. An
+// empty list restores the defaults.
+func (s *BudgetAlertStore) SetThresholds(householdID uint, thresholds []int) ([]int, error) {
+	stored, sorted, ok := formatPercents(thresholds, maxBudgetAlertThresholds, 1000)
+	if !ok {
+		return nil, ErrInvalidThresholds
+	}
+	res := s.DB.Model(&models.Household{}).Where("id = ?", householdID).Update("budget_alert_thresholds", stored)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if len(sorted) == 0 {
+		return append([]int(nil), models.DefaultBudgetAlertThresholds...), nil
+	}
+	return sorted, nil
+}
+
+// Evaluate compares month's spending with its budgets and records every
+// threshold crossed for the first time. Thresholds already recorded are
+// skipped, so repeated evaluation never reports the same crossing twice; a
+// category that drops back below a threshold does not fire it again.
+func (s *BudgetAlertStore) Evaluate(householdID uint, month string) ([]BudgetAlertHit, error) {
+	t, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, err
+	}
+	var budgets []models.Budget
+	if err := s.DB.Where("household_id = ? AND month = ? AND planned_cents > 0", householdID, month).
+		Order("category_id asc").Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+	thresholds, err := s.Thresholds(householdID)
+	if err != nil {
+		return nil, err
+	}
+	budgetStore := BudgetStore{DB: s.DB}
+	actuals, err := budgetStore.MonthActuals(householdID, t, t.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []BudgetAlertHit
+	for _, b := range budgets {
+		spent := -actuals[b.CategoryID]
+		hit := BudgetAlertHit{CategoryID: b.CategoryID, Month: month, SpentCents: spent, PlannedCents: b.PlannedCents}
+		for _, th := range thresholds {
+			if spent*100 < b.PlannedCents*int64(th) {
+				break
+			}
+			alert := models.BudgetAlert{
+				HouseholdID:  householdID,
+				CategoryID:   b.CategoryID,
+				Month:        month,
+				Threshold:    th,
+				SpentCents:   spent,
+				PlannedCents: b.PlannedCents,
+				CreatedAt:    time.Now(),
+			}
+			res := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+			if res.Error != nil {
+				return nil, res.Error
+			}
+			if res.RowsAffected == 1 {
+				hit.Threshold = th
+			}
+		}
+		if hit.Threshold > 0 {
+			hits = append(hits, hit)
+		}
+	}
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.CategoryID)
+	}
+	var cats []models.Category
+	if err := s.DB.Select("id", "name").Where("id IN ?", ids).Find(&cats).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(cats))
+	for _, c := range cats {
+		names[c.ID] = c.Name
+	}
+	for i := range hits {
+		hits[i].CategoryName = names[hits[i].CategoryID]
+	}
+	return hits, nil
+}
+
+// MemberIDs returns the user ids of everyone in the household.
+func (s *BudgetAlertStore) MemberIDs(householdID uint) ([]uint, error) {
+	households := HouseholdStore{DB: s.DB}
+	return households.MemberIDs(householdID)
+}
+
+// BudgetedHouseholds returns the households with a positive budget in month.
+func (s *BudgetAlertStore) BudgetedHouseholds(month string) ([]uint, error) {
+	var ids []uint
+	err := s.DB.Model(&models.Budget{}).Where("month = ? AND planned_cents > 0", month).
+		Distinct("household_id").Order("household_id asc").Pluck("household_id", &ids).Error
+	return ids, err
+}
+
+// parsePercents reads a comma-separated percentage list, falling back to a
+// copy of defaults when it is empty.
+func parsePercents(stored string, defaults []int) []int {
+	if strings.TrimSpace(stored) == "" {
+		return append([]int(nil), defaults...)
+	}
+	var out []int
+	for _, f := range strings.Split(stored, ",") {
+		if v, err := strconv.Atoi(strings.TrimSpace(f)); err == nil {
+			out = append(out, v)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+// formatPercents sorts and validates percentages (distinct, 1..max, at most
+// limit of them) and returns them in their stored form.
+func formatPercents(percents []int, limit, max int) (string, []int, bool) {
+	if len(percents) > limit {
+		return "", nil, false
+	}
+	sorted := append([]int(nil), percents...)
+	sort.Ints(sorted)
+	parts := make([]string, 0, len(sorted))
+	for i, p := range sorted {
+		if p < 1 || p > max || (i > 0 && sorted[i-1] == p) {
+			return "", nil, false
+		}
+		parts = append(parts, strconv.Itoa(p))
+	}
+	return strings.Join(parts, ","), sorted, true
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestBudgetAlertsFireOncePerThreshold(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Budget{}, &models.BudgetAlert{}, &models.HouseholdMember{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	house := models.Household{Name: "Home"}
+	gdb.Create(&house)
+	acc := models.Account{HouseholdID: house.ID, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&acc)
+	groceries := models.Category{HouseholdID: house.ID, Name: "Groceries"}
+	gdb.Create(&groceries)
+	gdb.Create(&models.Budget{HouseholdID: house.ID, Month: "2025-06", CategoryID: groceries.ID, PlannedCents: 10000})
+	gdb.Create(&models.HouseholdMember{HouseholdID: house.ID, UserID: 7, Role: models.RoleOwner})
+	gdb.Create(&models.HouseholdMember{HouseholdID: house.ID, UserID: 8, Role: models.RoleViewer})
+	ledger := LedgerStore{DB: gdb}
+	store := BudgetAlertStore{DB: gdb}
+	day := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	spend := func(cents int64) {
+		t.Helper()
+		leg := &models.Transaction{AccountID: acc.ID, AmountCents: -cents, Currency: "USD", CategoryID: &groceries.ID, OccurredAt: day}
+		if _, err := ledger.PostStandard(house.ID, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+
+	spend(7000)
+	if hits, err := store.Evaluate(house.ID, "2025-06"); err != nil || len(hits) != 0 {
+		t.Fatalf("expected no alerts at 70%%, got %v (%v)", hits, err)
+	}
+
+	spend(1500)
+	hits, err := store.Evaluate(house.ID, "2025-06")
+	if err != nil || len(hits) != 1 || hits[0].Threshold != 80 || hits[0].CategoryName != "Groceries" {
+		t.Fatalf("expected 80%% alert, got %v (%v)", hits, err)
+	}
+	if hits, _ := store.Evaluate(house.ID, "2025-06"); len(hits) != 0 {
+		t.Fatalf("re-evaluation must not fire again, got %v", hits)
+	}
+
+	// Jumping past both remaining thresholds reports the highest once but
+	// records each of them.
+	spend(4000)
+	hits, _ = store.Evaluate(house.ID, "2025-06")
+	if len(hits) != 1 || hits[0].Threshold != 120 || hits[0].SpentCents != 12500 {
+		t.Fatalf("expected 120%% alert, got %v", hits)
+	}
+	var n int64
+	gdb.Model(&models.BudgetAlert{}).Where("household_id = ?", house.ID).Count(&n)
+	if n != 3 {
+		t.Fatalf("expected 3 recorded thresholds, got %d", n)
+	}
+
+	members, err := store.MemberIDs(house.ID)
+	if err != nil || len(members) != 2 {
+		t.Fatalf("expected 2 members, got %v (%v)", members, err)
+	}
+	if ids, _ := store.BudgetedHouseholds("2025-06"); len(ids) != 1 || ids[0] != house.ID {
+		t.Fatalf("unexpected budgeted households: %v", ids)
+	}
+}
+
+func TestBudgetAlertThresholds(t *testing.T) {
+	gdb := newTestDB(t)
+	house := models.Household{Name: "Home"}
+	gdb.Create(&house)
+	store := BudgetAlertStore{DB: gdb}
+
+	got, err := store.Thresholds(house.ID)
+	if err != nil || len(got) != 3 || got[0] != 80 {
+		t.Fatalf("expected defaults, got %v (%v)", got, err)
+	}
+	if _, err := store.SetThresholds(house.ID, []int{90, 90}); !errors.Is(err, ErrInvalidThresholds) {
+		t.Fatalf("expected duplicate to be rejected, got %v", err)
+	}
+	if _, err := store.SetThresholds(house.ID, []int{0}); !errors.Is(err, ErrInvalidThresholds) {
+		t.Fatalf("expected zero to be rejected, got %v", err)
+	}
+	if _, err := store.SetThresholds(house.ID, []int{150, 50}); err != nil {
+		t.Fatalf("set thresholds: %v", err)
+	}
+	if got, _ := store.Thresholds(house.ID); len(got) != 2 || got[0] != 50 || got[1] != 150 {
+		t.Fatalf("expected sorted thresholds, got %v", got)
+	}
+	if got, _ := store.SetThresholds(house.ID, nil); len(got) != 3 {
+		t.Fatalf("empty list should restore defaults, got %v", got)
+	}
+}
@@ -0,0 +1,205 @@
+package db
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidPlan = errors.New("budget lines must reference distinct household categories")
+
+// Budget change actions reported when a plan is applied.
+const (
+	BudgetChangeCreate = "create"
+	BudgetChangeUpdate = "update"
+	BudgetChangeSkip   = "skip"
+)
+
+// BudgetLine is one category's planned amount in a plan applied to a month.
+type BudgetLine struct {
+	CategoryID   uint  `json:"category_id"`
+	PlannedCents int64 `json:"planned_cents"`
+	Rollover     bool  `json:"rollover"`
+}
+
+// BudgetChange is what applying a line did, or in preview would do, to the
+// month's budget for that category.
+type BudgetChange struct {
+	CategoryID    uint   `json:"category_id"`
+	Action        string `json:"action"`
+	PlannedCents  int64  `json:"planned_cents"`
+	PreviousCents *int64 `json:"previous_cents,omitempty"`
+	Rollover      bool   `json:"rollover"`
+}
+
+// CreateTemplate saves a named template after checking its categories.
+func (s *BudgetStore) CreateTemplate(householdID uint, name string, lines []BudgetLine) (*models.BudgetTemplate, error) {
+	t := &models.BudgetTemplate{HouseholdID: householdID, Name: name}
+	for _, l := range lines {
+		t.Lines = append(t.Lines, models.BudgetTemplateLine{CategoryID: l.CategoryID, PlannedCents: l.PlannedCents, Rollover: l.Rollover})
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkPlanCategories(tx, householdID, lines); err != nil {
+			return err
+		}
+		return tx.Create(t).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *BudgetStore) ListTemplates(householdID uint) ([]models.BudgetTemplate, error) {
+	var out []models.BudgetTemplate
+	err := s.DB.Preload("Lines").Where("household_id = ?", householdID).Order("name asc").Find(&out).Error
+	return out, err
+}
+
+func (s *BudgetStore) GetTemplate(householdID, id uint) (*models.BudgetTemplate, error) {
+	var t models.BudgetTemplate
+	if err := s.DB.Preload("Lines").Where("id = ? AND household_id = ?", id, householdID).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *BudgetStore) DeleteTemplate(householdID, id uint) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND household_id = ?", id, householdID).Delete(&models.BudgetTemplate{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("template_id = ?", id).Delete(&models.BudgetTemplateLine{}).Error
+	})
+}
+
+// TemplateLines converts a template into plan lines.
+func TemplateLines(t *models.BudgetTemplate) []BudgetLine {
+	out := make([]BudgetLine, 0, len(t.Lines))
+	for _, l := range t.Lines {
+		out = append(out, BudgetLine{CategoryID: l.CategoryID, PlannedCents: l.PlannedCents, Rollover: l.Rollover})
+	}
+	return out
+}
+
+// MonthLines returns month's budgets as plan lines, for copying forward.
+func (s *BudgetStore) MonthLines(householdID uint, month string) ([]BudgetLine, error) {
+	var budgets []models.Budget
+	if err := s.DB.Where("household_id = ? AND month = ?", householdID, month).Order("category_id asc").Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	out := make([]BudgetLine, 0, len(budgets))
+	for _, b := range budgets {
+		out = append(out, BudgetLine{CategoryID: b.CategoryID, PlannedCents: b.PlannedCents, Rollover: b.Rollover})
+	}
+	return out, nil
+}
+
+// AverageLines plans each category at its average monthly spending over the
+// n full months before month. Categories with net income are left out.
+func (s *BudgetStore) AverageLines(householdID uint, month string, n int) ([]BudgetLine, error) {
+	if n <= 0 {
+		return nil, ErrInvalidPlan
+	}
+	end, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.MonthActuals(householdID, end.AddDate(0, -n, 0), end)
+	if err != nil {
+		return nil, err
+	}
+	var out []BudgetLine
+	for id, net := range totals {
+		spent := -net
+		if spent <= 0 {
+			continue
+		}
+		out = append(out, BudgetLine{CategoryID: id, PlannedCents: (spent + int64(n)/2) / int64(n)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CategoryID < out[j].CategoryID })
+	return out, nil
+}
+
+// ApplyLines writes lines as month's budgets in one transaction. Existing
+// budgets are only replaced when overwrite is set. With preview nothing is
+// written and the changes that would be made are returned.
+func (s *BudgetStore) ApplyLines(householdID uint, month string, lines []BudgetLine, overwrite, preview bool) ([]BudgetChange, error) {
+	var changes []BudgetChange
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkPlanCategories(tx, householdID, lines); err != nil {
+			return err
+		}
+		for _, l := range lines {
+			ch := BudgetChange{CategoryID: l.CategoryID, PlannedCents: l.PlannedCents, Rollover: l.Rollover}
+			var existing models.Budget
+			err := tx.Where("household_id = ? AND month = ? AND category_id = ?", householdID, month, l.CategoryID).First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				ch.Action = BudgetChangeCreate
+			case err != nil:
+				return err
+			default:
+				prev := existing.PlannedCents
+				ch.PreviousCents = &prev
+				ch.Action = BudgetChangeSkip
+				if overwrite {
+					ch.Action = BudgetChangeUpdate
+				}
+			}
+			changes = append(changes, ch)
+			if preview {
+				continue
+			}
+			switch ch.Action {
+			case BudgetChangeCreate:
+				b := models.Budget{HouseholdID: householdID, Month: month, CategoryID: l.CategoryID, PlannedCents: l.PlannedCents, Rollover: l.Rollover}
+				if err := tx.Create(&b).Error; err != nil {
+					return err
+				}
+			case BudgetChangeUpdate:
+				if err := tx.Model(&existing).Updates(map[string]any{"planned_cents": l.PlannedCents, "rollover": l.Rollover}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// checkPlanCategories rejects duplicate categories and categories from other
+// households.
+func checkPlanCategories(tx *gorm.DB, householdID uint, lines []BudgetLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(lines))
+	seen := map[uint]struct{}{}
+	for _, l := range lines {
+		if _, dup := seen[l.CategoryID]; dup || l.CategoryID == 0 {
+			return ErrInvalidPlan
+		}
+		seen[l.CategoryID] = struct{}{}
+		ids = append(ids, l.CategoryID)
+	}
+	var n int64
+	if err := tx.Model(&models.Category{}).Where("household_id = ? AND id IN ?", householdID, ids).Count(&n).Error; err != nil {
+		return err
+	}
+	if int(n) != len(ids) {
+		return ErrInvalidPlan
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestApplyBudgetTemplate(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Budget{}, &models.BudgetTemplate{}, &models.BudgetTemplateLine{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	rent := models.Category{HouseholdID: 1, Name: "Rent"}
+	food := models.Category{HouseholdID: 1, Name: "Food"}
+	foreign := models.Category{HouseholdID: 2, Name: "Elsewhere"}
+	gdb.Create(&rent)
+	gdb.Create(&food)
+	gdb.Create(&foreign)
+	store := BudgetStore{DB: gdb}
+
+	if _, err := store.CreateTemplate(1, "Bad", []BudgetLine{{CategoryID: foreign.ID, PlannedCents: 1}}); !errors.Is(err, ErrInvalidPlan) {
+		t.Fatalf("expected ErrInvalidPlan, got %v", err)
+	}
+	tmpl, err := store.CreateTemplate(1, "Standard", []BudgetLine{
+		{CategoryID: rent.ID, PlannedCents: 150000, Rollover: false},
+		{CategoryID: food.ID, PlannedCents: 60000, Rollover: true},
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	gdb.Create(&models.Budget{HouseholdID: 1, Month: "2025-07", CategoryID: rent.ID, PlannedCents: 140000})
+
+	loaded, _ := store.GetTemplate(1, tmpl.ID)
+	lines := TemplateLines(loaded)
+	preview, err := store.ApplyLines(1, "2025-07", lines, false, true)
+	if err != nil || len(preview) != 2 {
+		t.Fatalf("preview: %v %+v", err, preview)
+	}
+	var count int64
+	gdb.Model(&models.Budget{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("preview must not write, found %d budgets", count)
+	}
+	if preview[0].Action != BudgetChangeSkip || *preview[0].PreviousCents != 140000 || preview[1].Action != BudgetChangeCreate {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+
+	if _, err := store.ApplyLines(1, "2025-07", lines, true, false); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	july, _ := store.MonthLines(1, "2025-07")
+	if len(july) != 2 || july[0].PlannedCents != 150000 || !july[1].Rollover {
+		t.Fatalf("unexpected July budgets: %+v", july)
+	}
+
+	// A bad line aborts the whole copy.
+	bad := append(july, BudgetLine{CategoryID: foreign.ID, PlannedCents: 5})
+	if _, err := store.ApplyLines(1, "2025-08", bad, false, false); !errors.Is(err, ErrInvalidPlan) {
+		t.Fatalf("expected ErrInvalidPlan, got %v", err)
+	}
+	if aug, _ := store.MonthLines(1, "2025-08"); len(aug) != 0 {
+		t.Fatalf("failed apply must not leave budgets behind: %+v", aug)
+	}
+}
+
+func TestAverageLines(t *testing.T) {
+	gdb := newTestDB(t)
+	acc := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&acc)
+	ledger := LedgerStore{DB: gdb}
+	food, salary := uint(3), uint(4)
+	post := func(cat uint, cents int64, month time.Month) {
+		leg := &models.Transaction{AccountID: acc.ID, AmountCents: cents, Currency: "USD", CategoryID: &cat, OccurredAt: time.Date(2025, month, 5, 0, 0, 0, 0, time.UTC)}
+		if _, err := ledger.PostStandard(1, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+	post(food, -30000, time.January) // outside the window
+	post(food, -40000, time.February)
+	post(food, -50000, time.March)
+	post(food, -60001, time.April)
+	post(salary, 500000, time.April)
+
+	store := BudgetStore{DB: gdb}
+	lines, err := store.AverageLines(1, "2025-05", 3)
+	if err != nil {
+		t.Fatalf("average: %v", err)
+	}
+	if len(lines) != 1 || lines[0].CategoryID != food || lines[0].PlannedCents != 50000 {
+		t.Fatalf("unexpected average lines: %+v", lines)
+	}
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestMonthActualsScopedToHousehold(t *testing.T) {
+	gdb := newTestDB(t)
+	ours := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	theirs := models.Account{HouseholdID: 2, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&ours)
+	gdb.Create(&theirs)
+	ledger := LedgerStore{DB: gdb}
+	day := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	dining, rent := uint(4), uint(5)
+
+	for _, leg := range []*models.Transaction{
+		{AccountID: ours.ID, AmountCents: -2500, Currency: "USD", CategoryID: &dining, OccurredAt: day},
+		{AccountID: ours.ID, AmountCents: -1500, Currency: "USD", CategoryID: &dining, OccurredAt: day.AddDate(0, 0, 3)},
+		{AccountID: ours.ID, AmountCents: -90000, Currency: "USD", CategoryID: &rent, OccurredAt: day},
+		{AccountID: ours.ID, AmountCents: -999, Currency: "USD", CategoryID: &dining, OccurredAt: day.AddDate(0, 1, 0)},
+		// Same category id in another household must not leak in.
+		{AccountID: theirs.ID, AmountCents: -7000, Currency: "USD", CategoryID: &dining, OccurredAt: day},
+	} {
+		hID := uint(1)
+		if leg.AccountID == theirs.ID {
+			hID = 2
+		}
+		if _, err := ledger.PostStandard(hID, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+
+	store := BudgetStore{DB: gdb}
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	actuals, err := store.MonthActuals(1, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("actuals: %v", err)
+	}
+	if len(actuals) != 2 || actuals[dining] != -4000 || actuals[rent] != -90000 {
+		t.Fatalf("unexpected actuals: %v", actuals)
+	}
+}
+
+func TestEnvelopesRollOverAndMoves(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Budget{}, &models.BudgetMove{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	acc := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&acc)
+	groceries := models.Category{HouseholdID: 1, Name: "Groceries"}
+	dining := models.Category{HouseholdID: 1, Name: "Dining"}
+	gdb.Create(&groceries)
+	gdb.Create(&dining)
+	ledger := LedgerStore{DB: gdb}
+	spend := func(cat uint, cents int64, month time.Month) {
+		leg := &models.Transaction{AccountID: acc.ID, AmountCents: -cents, Currency: "USD", CategoryID: &cat, OccurredAt: time.Date(2025, month, 10, 0, 0, 0, 0, time.UTC)}
+		if _, err := ledger.PostStandard(1, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+	gdb.Create(&models.Budget{HouseholdID: 1, Month: "2025-04", CategoryID: groceries.ID, PlannedCents: 30000, Rollover: true})
+	gdb.Create(&models.Budget{HouseholdID: 1, Month: "2025-05", CategoryID: groceries.ID, PlannedCents: 30000, Rollover: true})
+	gdb.Create(&models.Budget{HouseholdID: 1, Month: "2025-05", CategoryID: dining.ID, PlannedCents: 20000})
+	gdb.Create(&models.Budget{HouseholdID: 1, Month: "2025-06", CategoryID: groceries.ID, PlannedCents: 30000})
+	spend(groceries.ID, 25000, time.April)
+	spend(groceries.ID, 40000, time.May)
+
+	store := BudgetStore{DB: gdb}
+	if _, err := store.MoveMoney(1, 7, "2025-05", dining.ID, dining.ID, 100, ""); !errors.Is(err, ErrInvalidMove) {
+		t.Fatalf("expected ErrInvalidMove for same category, got %v", err)
+	}
+	if _, err := store.MoveMoney(1, 7, "2025-05", dining.ID, groceries.ID, 10000, "cover groceries"); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+
+	may, err := store.Envelopes(1, "2025-05")
+	if err != nil {
+		t.Fatalf("envelopes: %v", err)
+	}
+	byCat := map[uint]Envelope{}
+	for _, e := range may {
+		byCat[e.CategoryID] = e
+	}
+	if g := byCat[groceries.ID]; g.CarriedCents != 5000 || g.MovedCents != 10000 || g.AvailableCents != 5000 {
+		t.Fatalf("unexpected May groceries envelope: %+v", g)
+	}
+	if d := byCat[dining.ID]; d.MovedCents != -10000 || d.AvailableCents != 10000 {
+		t.Fatalf("unexpected May dining envelope: %+v", d)
+	}
+
+	june, _ := store.Envelopes(1, "2025-06")
+	if len(june) != 1 || june[0].CarriedCents != 5000 || june[0].AvailableCents != 35000 {
+		t.Fatalf("unexpected June envelopes: %+v", june)
+	}
+	if moves, _ := store.ListMoves(1, "2025-05"); len(moves) != 1 || moves[0].UserID != 7 {
+		t.Fatalf("expected the move to be recorded, got %+v", moves)
+	}
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"errors"
+	"sort"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryCycle  = errors.New("category cannot be moved under itself or its descendants")
+	ErrInvalidParent  = errors.New("parent category not found in household")
+	ErrCategoryInUse  = errors.New("category is still used by transactions, budgets or rules; merge it instead")
+	ErrMergeIntoChild = errors.New("cannot merge a category into one of its descendants")
+	ErrInvalidMerge   = errors.New("merge needs two different categories from the same household")
+)
+
+// CategoryNode is a category with its children, for the tree endpoint.
+type CategoryNode struct {
+	ID       uint            `json:"id"`
+	Name     string          `json:"name"`
+	ParentID *uint           `json:"parent_id"`
+	Children []*CategoryNode `json:"children"`
+}
+
+type CategoryStore struct {
+	DB *gorm.DB
+}
+
+// Tree returns the household's categories as a forest sorted by name.
+// Categories whose parent is missing are treated as roots.
+func (s *CategoryStore) Tree(householdID uint) ([]*CategoryNode, error) {
+	var cats []models.Category
+	if err := s.DB.Where("household_id = ?", householdID).Order("name asc").Find(&cats).Error; err != nil {
+		return nil, err
+	}
+	nodes := make(map[uint]*CategoryNode, len(cats))
+	for _, c := range cats {
+		nodes[c.ID] = &CategoryNode{ID: c.ID, Name: c.Name, ParentID: c.ParentID, Children: []*CategoryNode{}}
+	}
+	roots := []*CategoryNode{}
+	for _, c := range cats {
+		n := nodes[c.ID]
+		if c.ParentID != nil {
+			if p, ok := nodes[*c.ParentID]; ok {
+				p.Children = append(p.Children, n)
+				continue
+			}
+		}
+		roots = append(roots, n)
+	}
+	return roots, nil
+}
+
+// Get loads a category belonging to the household.
+func (s *CategoryStore) Get(householdID, id uint) (*models.Category, error) {
+	var c models.Category
+	if err := s.DB.Where("id = ? AND household_id = ?", id, householdID).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CheckParent verifies parentID can be the parent of category id (0 for a
+// new category): it must be in the household and not id or a descendant.
+func (s *CategoryStore) CheckParent(householdID, id uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	parents, err := s.parents(householdID)
+	if err != nil {
+		return err
+	}
+	if _, ok := parents[*parentID]; !ok {
+		return ErrInvalidParent
+	}
+	if id != 0 && isDescendant(parents, *parentID, id) {
+		return ErrCategoryCycle
+	}
+	return nil
+}
+
+// Update renames and/or moves a category. setParent distinguishes moving to
+// the top level (parentID nil) from leaving the parent unchanged.
+func (s *CategoryStore) Update(c *models.Category, name *string, setParent bool, parentID *uint) error {
+	if setParent {
+		if err := s.CheckParent(c.HouseholdID, c.ID, parentID); err != nil {
+			return err
+		}
+		c.ParentID = parentID
+	}
+	if name != nil {
+		c.Name = *name
+	}
+	return s.DB.Model(c).Updates(map[string]any{"name": c.Name, "parent_id": c.ParentID}).Error
+}
+
+// Delete removes an unused category; its children move up to its parent.
+func (s *CategoryStore) Delete(c *models.Category) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		for _, q := range []*gorm.DB{
+			tx.Model(&models.Transaction{}).Where("category_id = ?", c.ID),
+			tx.Model(&models.TransactionSplit{}).Where("category_id = ?", c.ID),
+			tx.Model(&models.Budget{}).Where("category_id = ?", c.ID),
+			tx.Model(&models.CategoryRule{}).Where("category_id = ?", c.ID),
+		} {
+			var n int64
+			if err := q.Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				return ErrCategoryInUse
+			}
+		}
+		if err := tx.Where("category_id = ?", c.ID).Delete(&models.BudgetTemplateLine{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("from_category_id = ? OR to_category_id = ?", c.ID, c.ID).Delete(&models.BudgetMove{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("category_id = ?", c.ID).Delete(&models.BudgetAlert{}).Error; err != nil {
+			return err
+		}
+		// Goals tracking the category fall back to manual progress.
+		if err := tx.Model(&models.Goal{}).Where("category_id = ?", c.ID).
+			Updates(map[string]any{"category_id": nil, "household_id": nil}).Error; err != nil {
+			return err
+		}
+		for _, m := range []any{&models.SubscriptionCandidate{}, &models.ScheduledTransaction{}, &models.Bill{}} {
+			if err := tx.Model(m).Where("category_id = ?", c.ID).Update("category_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", c.ID).Update("parent_id", c.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(c).Error
+	})
+}
+
+// Merge folds source into target: transactions, splits, rules, goals,
+// subscriptions, scheduled transactions, bills, budgets, templates and moves
+// are reassigned (planned amounts for the same month are added together),
+// source's children move under target, and source is deleted. Each
+// recategorized transaction is recorded in its audit trail as userID's.
+func (s *CategoryStore) Merge(userID uint, source, target *models.Category) error {
+	if source.ID == target.ID || source.HouseholdID != target.HouseholdID {
+		return ErrInvalidMerge
+	}
+	parents, err := s.parents(source.HouseholdID)
+	if err != nil {
+		return err
+	}
+	if isDescendant(parents, target.ID, source.ID) {
+		return ErrMergeIntoChild
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		var legIDs, splitLegIDs []uint
+		if err := tx.Model(&models.Transaction{}).Where("category_id = ?", source.ID).Order("id asc").Pluck("id", &legIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TransactionSplit{}).Where("category_id = ?", source.ID).Distinct("transaction_id").Order("transaction_id asc").Pluck("transaction_id", &splitLegIDs).Error; err != nil {
+			return err
+		}
+		for _, m := range []any{&models.Transaction{}, &models.TransactionSplit{}, &models.CategoryRule{}, &models.Goal{}, &models.SubscriptionCandidate{}, &models.ScheduledTransaction{}, &models.Bill{}} {
+			if err := tx.Model(m).Where("category_id = ?", source.ID).Update("category_id", target.ID).Error; err != nil {
+				return err
+			}
+		}
+		audit := TransactionStore{DB: tx}
+		for _, id := range legIDs {
+			if err := audit.RecordAudit(source.HouseholdID, userID, id, models.AuditUpdate, map[string][2]any{"category_id": {source.ID, target.ID}}); err != nil {
+				return err
+			}
+		}
+		for _, id := range splitLegIDs {
+			if err := audit.RecordAudit(source.HouseholdID, userID, id, models.AuditUpdate, map[string][2]any{"split_category_id": {source.ID, target.ID}}); err != nil {
+				return err
+			}
+		}
+
+		var budgets []models.Budget
+		if err := tx.Where("household_id = ? AND category_id = ?", source.HouseholdID, source.ID).Find(&budgets).Error; err != nil {
+			return err
+		}
+		for _, b := range budgets {
+			var existing models.Budget
+			err := tx.Where("household_id = ? AND month = ? AND category_id = ?", b.HouseholdID, b.Month, target.ID).First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Model(&b).Update("category_id", target.ID).Error; err != nil {
+					return err
+				}
+			case err != nil:
+				return err
+			default:
+				if err := tx.Model(&existing).Update("planned_cents", existing.PlannedCents+b.PlannedCents).Error; err != nil {
+					return err
+				}
+				if err := tx.Delete(&b).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		var lines []models.BudgetTemplateLine
+		if err := tx.Where("category_id = ?", source.ID).Find(&lines).Error; err != nil {
+			return err
+		}
+		for _, l := range lines {
+			var existing models.BudgetTemplateLine
+			err := tx.Where("template_id = ? AND category_id = ?", l.TemplateID, target.ID).First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Model(&l).Update("category_id", target.ID).Error; err != nil {
+					return err
+				}
+			case err != nil:
+				return err
+			default:
+				if err := tx.Model(&existing).Update("planned_cents", existing.PlannedCents+l.PlannedCents).Error; err != nil {
+					return err
+				}
+				if err := tx.Delete(&l).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.Model(&models.BudgetMove{}).Where("from_category_id = ?", source.ID).Update("from_category_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.BudgetMove{}).Where("to_category_id = ?", source.ID).Update("to_category_id", target.ID).Error; err != nil {
+			return err
+		}
+		// A move between the two merged categories is now a no-op.
+		if err := tx.Where("household_id = ? AND from_category_id = to_category_id", source.HouseholdID).Delete(&models.BudgetMove{}).Error; err != nil {
+			return err
+		}
+
+		// Target keeps its own alert history; source's thresholds are dropped.
+		if err := tx.Where("category_id = ?", source.ID).Delete(&models.BudgetAlert{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", source.ID).Update("parent_id", target.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(source).Error
+	})
+}
+
+// RollUp adds every category's amount into each of its ancestors, so a
+// parent's total covers its whole subtree. Categories outside the household
+// keep their own amount only.
+func (s *CategoryStore) RollUp(householdID uint, amounts map[uint]int64) (map[uint]int64, error) {
+	parents, err := s.parents(householdID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uint]int64, len(amounts))
+	ids := make([]uint, 0, len(amounts))
+	for id := range amounts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		v := amounts[id]
+		out[id] += v
+		seen := map[uint]bool{id: true}
+		for p := parents[id]; p != 0 && !seen[p]; p = parents[p] {
+			seen[p] = true
+			out[p] += v
+		}
+	}
+	return out, nil
+}
+
+// parents maps each household category to its parent id (0 for roots).
+func (s *CategoryStore) parents(householdID uint) (map[uint]uint, error) {
+	var cats []models.Category
+	if err := s.DB.Select("id", "parent_id").Where("household_id = ?", householdID).Find(&cats).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[uint]uint, len(cats))
+	for _, c := range cats {
+		out[c.ID] = 0
+		if c.ParentID != nil {
+			out[c.ID] = *c.ParentID
+		}
+	}
+	return out, nil
+}
+
+// isDescendant reports whether id is ancestor itself or sits below it.
+func isDescendant(parents map[uint]uint, id, ancestor uint) bool {
+	seen := map[uint]bool{}
+	for cur := id; cur != 0 && !seen[cur]; cur = parents[cur] {
+		if cur == ancestor {
+			return true
+		}
+		seen[cur] = true
+	}
+	return false
+}
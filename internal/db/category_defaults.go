@@ -0,0 +1,133 @@
+package db
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+//go:embed seeds/categories/*.json
+var defaultCategoriesFS embed.FS
+
+// DefaultCategoryLocale is used when a household asks for no locale or one
+// without its own category set.
+const DefaultCategoryLocale = "en"
+
+// DefaultCategory is one node of a built-in category tree.
+type DefaultCategory struct {
+	Name     string            `json:"name"`
+	Children []DefaultCategory `json:"children,omitempty"`
+}
+
+// DefaultCategoryDiff lists the default categories a household is missing,
+// as "Parent/Child" paths. After SeedDefaultCategories they have been created.
+type DefaultCategoryDiff struct {
+	Locale  string   `json:"locale"`
+	Missing []string `json:"missing"`
+	Present int      `json:"present"`
+}
+
+// DefaultCategoryLocales lists the locales with a built-in category set.
+func DefaultCategoryLocales() []string {
+	entries, _ := defaultCategoriesFS.ReadDir("seeds/categories")
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// DefaultCategories returns the category tree for locale ("es-MX" falls back
+// to "es", then to DefaultCategoryLocale) and the locale actually used.
+func DefaultCategories(locale string) ([]DefaultCategory, string, error) {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	candidates := []string{locale}
+	if lang, _, ok := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-"); ok {
+		candidates = append(candidates, lang)
+	}
+	candidates = append(candidates, DefaultCategoryLocale)
+	for _, c := range candidates {
+		if c == "" || strings.ContainsAny(c, "/.") {
+			continue
+		}
+		raw, err := defaultCategoriesFS.ReadFile(path.Join("seeds/categories", c+".json"))
+		if err != nil {
+			continue
+		}
+		var tree []DefaultCategory
+		if err := json.Unmarshal(raw, &tree); err != nil {
+			return nil, "", err
+		}
+		return tree, c, nil
+	}
+	return nil, "", nil
+}
+
+// SeedDefaultCategories creates whichever default categories the household
+// lacks, matching existing ones by name (case-insensitive) under the same
+// parent, so it is safe to run repeatedly. With dryRun it only reports.
+func (s *CategoryStore) SeedDefaultCategories(householdID uint, locale string, dryRun bool) (*DefaultCategoryDiff, error) {
+	tree, used, err := DefaultCategories(locale)
+	if err != nil {
+		return nil, err
+	}
+	diff := &DefaultCategoryDiff{Locale: used, Missing: []string{}}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var cats []models.Category
+		if err := tx.Where("household_id = ?", householdID).Find(&cats).Error; err != nil {
+			return err
+		}
+		existing := map[string]uint{}
+		for _, c := range cats {
+			existing[categoryKey(c.ParentID, c.Name)] = c.ID
+		}
+		var walk func(nodes []DefaultCategory, parentID *uint, prefix string, parentMissing bool) error
+		walk = func(nodes []DefaultCategory, parentID *uint, prefix string, parentMissing bool) error {
+			for _, n := range nodes {
+				p := prefix + n.Name
+				id, found := uint(0), false
+				if !parentMissing {
+					id, found = existing[categoryKey(parentID, n.Name)]
+				}
+				if found {
+					diff.Present++
+				} else {
+					diff.Missing = append(diff.Missing, p)
+					if !dryRun {
+						c := models.Category{HouseholdID: householdID, Name: n.Name, ParentID: parentID}
+						if err := tx.Create(&c).Error; err != nil {
+							return err
+						}
+						id = c.ID
+					}
+				}
+				missing := !found && dryRun
+				if err := walk(n.Children, &id, p+"/", missing); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return walk(tree, nil, "", false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+func categoryKey(parentID *uint, name string) string {
+	var p uint
+	if parentID != nil {
+		p = *parentID
+	}
+	return strconv.FormatUint(uint64(p), 10) + "/" + strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/rules"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRuleStore struct {
+	DB *gorm.DB
+}
+
+func (s *CategoryRuleStore) ListByHousehold(householdID uint) ([]models.CategoryRule, error) {
+	var out []models.CategoryRule
+	err := s.DB.Where("household_id = ?", householdID).Order("priority asc, id asc").Find(&out).Error
+	return out, err
+}
+
+// Engine compiles the household's enabled rules for matching.
+func (s *CategoryRuleStore) Engine(householdID uint) (*rules.Engine, error) {
+	var rs []models.CategoryRule
+	if err := s.DB.Where("household_id = ? AND enabled = ?", householdID, true).Find(&rs).Error; err != nil {
+		return nil, err
+	}
+	return rules.Compile(rs)
+}
+
+// Reapply runs the household's rules over its existing legs and returns how
+// many were recategorized. Only uncategorized legs are touched unless
+// overwrite is set; transfers, split legs and the external account are never
+// categorized. Each change is recorded in the leg's audit trail as userID's.
+func (s *CategoryRuleStore) Reapply(householdID, userID uint, overwrite bool) (int, error) {
+	engine, err := s.Engine(householdID)
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		q := tx.Model(&models.Transaction{}).
+			Joins("JOIN accounts a ON a.id = transactions.account_id").
+			Joins("LEFT JOIN journal_entries je ON je.id = transactions.entry_id").
+			Where("a.household_id = ? AND a.type <> ?", householdID, models.AccountTypeExternal).
+			Where("je.kind IS NULL OR je.kind <> ?", models.EntryKindTransfer).
+			Where("NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = transactions.id)")
+		if !overwrite {
+			q = q.Where("transactions.category_id IS NULL")
+		}
+		var legs []models.Transaction
+		if err := q.Select("transactions.*").Find(&legs).Error; err != nil {
+			return err
+		}
+		audit := TransactionStore{DB: tx}
+		for i := range legs {
+			r := engine.Match(&legs[i])
+			if r == nil || (legs[i].CategoryID != nil && *legs[i].CategoryID == r.CategoryID) {
+				continue
+			}
+			if err := tx.Model(&models.Transaction{}).Where("id = ?", legs[i].ID).
+				Update("category_id", r.CategoryID).Error; err != nil {
+				return err
+			}
+			changes := map[string][2]any{"category_id": {legs[i].CategoryID, r.CategoryID}}
+			if err := audit.RecordAudit(householdID, userID, legs[i].ID, models.AuditUpdate, changes); err != nil {
+				return err
+			}
+			changed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestCategoryRuleReapply(t *testing.T) {
+	gdb := newTestDB(t)
+	gdb.AutoMigrate(&models.CategoryRule{})
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	savings := models.Account{HouseholdID: 1, Name: "Savings", Type: "savings", Currency: "USD"}
+	gdb.Create(&checking)
+	gdb.Create(&savings)
+	ledger := LedgerStore{DB: gdb}
+	now := time.Now()
+
+	groceries := uint(5)
+	gdb.Create(&models.CategoryRule{HouseholdID: 1, Name: "Groceries", MemoContains: "market", CategoryID: groceries, Enabled: true})
+	gdb.Create(&models.CategoryRule{HouseholdID: 1, Name: "Anything", MinAmountCents: new(int64), CategoryID: 6, Enabled: true})
+
+	ledger.PostStandard(1, &models.Transaction{AccountID: checking.ID, AmountCents: -3000, Currency: "USD", Memo: "Corner Market", OccurredAt: now})
+	other := uint(8)
+	ledger.PostStandard(1, &models.Transaction{AccountID: checking.ID, AmountCents: -1000, Currency: "USD", Memo: "Farmers market", CategoryID: &other, OccurredAt: now})
+	ledger.PostTransfer(1, savings.ID, &models.Transaction{AccountID: checking.ID, AmountCents: 500, Currency: "USD", Memo: "market fund", OccurredAt: now})
+
+	store := CategoryRuleStore{DB: gdb}
+	n, err := store.Reapply(1, 9, false)
+	if err != nil {
+		t.Fatalf("reapply: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 leg categorized, got %d", n)
+	}
+	if n, _ = store.Reapply(1, 9, true); n != 1 {
+		t.Fatalf("expected overwrite to recategorize 1 more leg, got %d", n)
+	}
+	var categorized int64
+	gdb.Model(&models.Transaction{}).Where("category_id = ?", groceries).Count(&categorized)
+	if categorized != 2 {
+		t.Fatalf("expected 2 grocery legs, got %d", categorized)
+	}
+	var audited int64
+	gdb.Model(&models.TransactionAudit{}).Where("user_id = ? AND action = ?", 9, models.AuditUpdate).Count(&audited)
+	if audited != 2 {
+		t.Fatalf("expected both recategorizations audited, got %d", audited)
+	}
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestCategoryTreeMoveAndRollUp(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Budget{}, &models.BudgetMove{}, &models.BudgetTemplateLine{}, &models.CategoryRule{}, &models.BudgetAlert{}, &models.Goal{}, &models.SubscriptionCandidate{}, &models.ScheduledTransaction{}, &models.Bill{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	food := models.Category{HouseholdID: 1, Name: "Food"}
+	gdb.Create(&food)
+	groceries := models.Category{HouseholdID: 1, Name: "Groceries", ParentID: &food.ID}
+	gdb.Create(&groceries)
+	produce := models.Category{HouseholdID: 1, Name: "Produce", ParentID: &groceries.ID}
+	gdb.Create(&produce)
+	store := CategoryStore{DB: gdb}
+
+	tree, err := store.Tree(1)
+	if err != nil || len(tree) != 1 || len(tree[0].Children) != 1 || len(tree[0].Children[0].Children) != 1 {
+		t.Fatalf("unexpected tree: %+v (%v)", tree, err)
+	}
+
+	if err := store.Update(&food, nil, true, &produce.ID); !errors.Is(err, ErrCategoryCycle) {
+		t.Fatalf("expected ErrCategoryCycle, got %v", err)
+	}
+	if err := store.Update(&food, nil, true, &food.ID); !errors.Is(err, ErrCategoryCycle) {
+		t.Fatalf("expected ErrCategoryCycle for self-parent, got %v", err)
+	}
+	other := uint(999)
+	if err := store.Update(&produce, nil, true, &other); !errors.Is(err, ErrInvalidParent) {
+		t.Fatalf("expected ErrInvalidParent, got %v", err)
+	}
+
+	rolled, err := store.RollUp(1, map[uint]int64{produce.ID: -500, groceries.ID: -1000, food.ID: -100})
+	if err != nil {
+		t.Fatalf("rollup: %v", err)
+	}
+	if rolled[food.ID] != -1600 || rolled[groceries.ID] != -1500 || rolled[produce.ID] != -500 {
+		t.Fatalf("unexpected rollup: %v", rolled)
+	}
+
+	gdb.Create(&models.Budget{HouseholdID: 1, Month: "2025-01", CategoryID: groceries.ID})
+	if err := store.Delete(&groceries); !errors.Is(err, ErrCategoryInUse) {
+		t.Fatalf("expected ErrCategoryInUse, got %v", err)
+	}
+	if err := store.Delete(&produce); err != nil {
+		t.Fatalf("delete unused: %v", err)
+	}
+}
+
+func TestCategoryMerge(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Budget{}, &models.BudgetMove{}, &models.BudgetTemplateLine{}, &models.CategoryRule{}, &models.BudgetAlert{}, &models.Goal{}, &models.SubscriptionCandidate{}, &models.ScheduledTransaction{}, &models.Bill{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	acc := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&acc)
+	dining := models.Category{HouseholdID: 1, Name: "Dining"}
+	gdb.Create(&dining)
+	restaurants := models.Category{HouseholdID: 1, Name: "Restaurants"}
+	gdb.Create(&restaurants)
+	takeout := models.Category{HouseholdID: 1, Name: "Takeout", ParentID: &restaurants.ID}
+	gdb.Create(&takeout)
+
+	ledger := LedgerStore{DB: gdb}
+	leg := &models.Transaction{AccountID: acc.ID, AmountCents: -1200, Currency: "USD", CategoryID: &restaurants.ID, OccurredAt: time.Now()}
+	ledger.PostStandard(1, leg)
+	gdb.Create(&models.Budget{HouseholdID: 1, Month: "2025-02", CategoryID: restaurants.ID, PlannedCents: 100})
+	gdb.Create(&models.Budget{HouseholdID: 1, Month: "2025-02", CategoryID: dining.ID, PlannedCents: 200})
+	gdb.Create(&models.Budget{HouseholdID: 1, Month: "2025-03", CategoryID: restaurants.ID, PlannedCents: 300})
+	gdb.Create(&models.CategoryRule{HouseholdID: 1, Name: "Cafe", CategoryID: restaurants.ID, Enabled: true})
+	bill := models.Bill{HouseholdID: 1, Name: "Meal kit", AmountCents: 6000, CategoryID: &restaurants.ID}
+	gdb.Create(&bill)
+
+	store := CategoryStore{DB: gdb}
+	if err := store.Merge(9, &restaurants, &takeout); !errors.Is(err, ErrMergeIntoChild) {
+		t.Fatalf("expected ErrMergeIntoChild, got %v", err)
+	}
+	if err := store.Merge(9, &restaurants, &dining); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	var moved models.Transaction
+	gdb.First(&moved, leg.ID)
+	if moved.CategoryID == nil || *moved.CategoryID != dining.ID {
+		t.Fatalf("transaction not reassigned: %+v", moved.CategoryID)
+	}
+	audit := TransactionStore{DB: gdb}
+	if trail, _ := audit.ListAudit(leg.ID); len(trail) != 1 || !strings.Contains(trail[0].Changes, "category_id") {
+		t.Fatalf("expected the reassignment audited, got %+v", trail)
+	}
+	var budgets []models.Budget
+	gdb.Where("category_id = ?", dining.ID).Order("month asc").Find(&budgets)
+	if len(budgets) != 2 || budgets[0].PlannedCents != 300 || budgets[1].PlannedCents != 300 {
+		t.Fatalf("unexpected merged budgets: %+v", budgets)
+	}
+	var rule models.CategoryRule
+	gdb.First(&rule)
+	if rule.CategoryID != dining.ID {
+		t.Fatalf("rule not reassigned")
+	}
+	gdb.First(&bill, bill.ID)
+	if bill.CategoryID == nil || *bill.CategoryID != dining.ID {
+		t.Fatalf("bill not reassigned: %+v", bill.CategoryID)
+	}
+	var child models.Category
+	gdb.First(&child, takeout.ID)
+	if child.ParentID == nil || *child.ParentID != dining.ID {
+		t.Fatalf("child not moved under target")
+	}
+	if _, err := store.Get(1, restaurants.ID); err == nil {
+		t.Fatalf("source category should be deleted")
+	}
+}
+
+func TestSeedDefaultCategories(t *testing.T) {
+	gdb := newTestDB(t)
+	store := CategoryStore{DB: gdb}
+
+	if _, used, _ := DefaultCategories("es-MX"); used != "es" {
+		t.Fatalf("es-MX should fall back to es, got %q", used)
+	}
+	if _, used, _ := DefaultCategories("xx"); used != DefaultCategoryLocale {
+		t.Fatalf("unknown locale should fall back to %q, got %q", DefaultCategoryLocale, used)
+	}
+
+	// An existing top-level category is reused rather than duplicated.
+	gdb.Create(&models.Category{HouseholdID: 1, Name: "food"})
+	first, err := store.SeedDefaultCategories(1, "", false)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if first.Present != 1 || len(first.Missing) == 0 {
+		t.Fatalf("unexpected first seed: %+v", first)
+	}
+	var foods int64
+	gdb.Model(&models.Category{}).Where("household_id = 1 AND LOWER(name) = 'food'").Count(&foods)
+	if foods != 1 {
+		t.Fatalf("expected the existing Food category to be reused, found %d", foods)
+	}
+
+	again, err := store.SeedDefaultCategories(1, "en", true)
+	if err != nil || len(again.Missing) != 0 {
+		t.Fatalf("re-running should find nothing missing: %+v (%v)", again, err)
+	}
+
+	gdb.Where("household_id = 1 AND name = ?", "Utilities").Delete(&models.Category{})
+	diff, _ := store.SeedDefaultCategories(1, "en", true)
+	if len(diff.Missing) != 6 || diff.Missing[0] != "Utilities" || diff.Missing[1] != "Utilities/Electricity" {
+		t.Fatalf("unexpected dry-run diff: %+v", diff.Missing)
+	}
+	var count int64
+	gdb.Model(&models.Category{}).Where("household_id = 1 AND name = ?", "Utilities").Count(&count)
+	if count != 0 {
+		t.Fatalf("dry run must not create categories")
+	}
+}
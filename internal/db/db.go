@@ -29,6 +29,9 @@ func Initialize(cfg *config.Config) (*sql.DB, *gorm.DB, error) {
 	if err := RunMigrations(gormDB); err != nil {
 		return nil, nil, fmt.Errorf("migrations: %w", err)
 	}
+	if _, err := EnsureTransactionSearch(gormDB); err != nil {
+		return nil, nil, fmt.Errorf("transaction search index: %w", err)
+	}
 
 	sqlDB, err := gormDB.DB()
 	if err != nil {
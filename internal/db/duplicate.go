@@ -0,0 +1,251 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrDuplicateResolved = errors.New("duplicate suspect already resolved")
+
+// DuplicateRules bound how close two legs on the same account must be to
+// be flagged as possible duplicates.
+type DuplicateRules struct {
+	AmountToleranceCents int64
+	WindowDays           int
+	MinMemoSimilarity    float64
+}
+
+var DefaultDuplicateRules = DuplicateRules{AmountToleranceCents: 100, WindowDays: 3, MinMemoSimilarity: 0.5}
+
+type DuplicateStore struct {
+	DB *gorm.DB
+}
+
+// Detect flags earlier legs on leg's account that look like the same
+// real-world transaction and records a pending suspect for each. Legs listed
+// in ignore (e.g. rows from the same import) are never matched.
+func (s *DuplicateStore) Detect(householdID uint, leg *models.Transaction, rules DuplicateRules, ignore ...uint) ([]models.DuplicateSuspect, error) {
+	window := time.Duration(rules.WindowDays) * 24 * time.Hour
+	q := s.DB.Where("account_id = ? AND id <> ? AND entry_id <> ?", leg.AccountID, leg.ID, leg.EntryID).
+		Where("amount_cents BETWEEN ? AND ?", leg.AmountCents-rules.AmountToleranceCents, leg.AmountCents+rules.AmountToleranceCents).
+		Where("occurred_at BETWEEN ? AND ?", leg.OccurredAt.Add(-window), leg.OccurredAt.Add(window))
+	if len(ignore) > 0 {
+		q = q.Where("id NOT IN ?", ignore)
+	}
+	var candidates []models.Transaction
+	if err := q.Order("occurred_at asc").Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	var out []models.DuplicateSuspect
+	for _, c := range candidates {
+		score, ok := duplicateScore(leg, &c, rules)
+		if !ok {
+			continue
+		}
+		var existing int64
+		s.DB.Model(&models.DuplicateSuspect{}).
+			Where("(transaction_id = ? AND duplicate_of_id = ?) OR (transaction_id = ? AND duplicate_of_id = ?)", leg.ID, c.ID, c.ID, leg.ID).
+			Count(&existing)
+		if existing > 0 {
+			continue
+		}
+		sus := models.DuplicateSuspect{
+			HouseholdID:   householdID,
+			TransactionID: leg.ID,
+			DuplicateOfID: c.ID,
+			Score:         score,
+			Status:        models.DuplicatePending,
+		}
+		if err := s.DB.Create(&sus).Error; err != nil {
+			return nil, err
+		}
+		out = append(out, sus)
+	}
+	return out, nil
+}
+
+// ListPending returns unresolved suspects for a household with both legs loaded.
+func (s *DuplicateStore) ListPending(householdID uint) ([]models.DuplicateSuspect, error) {
+	var out []models.DuplicateSuspect
+	err := s.DB.Preload("Transaction").Preload("DuplicateOf").
+		Where("household_id = ? AND status = ?", householdID, models.DuplicatePending).
+		Order("created_at desc").
+		Find(&out).Error
+	return out, err
+}
+
+// Dismiss marks a suspect as not a duplicate; both legs are kept.
+func (s *DuplicateStore) Dismiss(householdID, id uint) (*models.DuplicateSuspect, error) {
+	var sus models.DuplicateSuspect
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := pendingSuspect(tx, householdID, id, &sus); err != nil {
+			return err
+		}
+		return resolveSuspect(tx, &sus, models.DuplicateDismissed)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sus, nil
+}
+
+// Merge deletes the suspected duplicate's journal entry and keeps the
+// original leg, carrying over its category and bank id when the original
+// has none so a re-import still recognises it. The removed legs and the
+// original's changes are recorded in the audit trail as userID's.
+func (s *DuplicateStore) Merge(householdID, userID, id uint) (*models.DuplicateSuspect, error) {
+	var sus models.DuplicateSuspect
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := pendingSuspect(tx, householdID, id, &sus); err != nil {
+			return err
+		}
+		var dup, orig models.Transaction
+		if err := tx.First(&dup, sus.TransactionID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&orig, sus.DuplicateOfID).Error; err != nil {
+			return err
+		}
+
+		legs := []models.Transaction{dup}
+		if dup.EntryID != 0 {
+			if err := tx.Where("entry_id = ?", dup.EntryID).Order("id asc").Find(&legs).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("entry_id = ?", dup.EntryID).Delete(&models.Transaction{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.JournalEntry{}, dup.EntryID).Error; err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Delete(&models.Transaction{}, dup.ID).Error; err != nil {
+				return err
+			}
+		}
+		removed := make([]uint, 0, len(legs))
+		for _, l := range legs {
+			removed = append(removed, l.ID)
+		}
+
+		if err := tx.Where("transaction_id IN ?", removed).Delete(&models.TransactionSplit{}).Error; err != nil {
+			return err
+		}
+
+		var origSplits int64
+		tx.Model(&models.TransactionSplit{}).Where("transaction_id = ?", orig.ID).Count(&origSplits)
+		updates := map[string]any{}
+		changes := map[string][2]any{}
+		if orig.CategoryID == nil && dup.CategoryID != nil && origSplits == 0 {
+			updates["category_id"] = *dup.CategoryID
+			changes["category_id"] = [2]any{nil, *dup.CategoryID}
+		}
+		if orig.ExternalID == nil && dup.ExternalID != nil {
+			updates["external_id"] = *dup.ExternalID
+			changes["external_id"] = [2]any{nil, *dup.ExternalID}
+		}
+		audit := TransactionStore{DB: tx}
+		if len(updates) > 0 {
+			if err := tx.Model(&orig).Updates(updates).Error; err != nil {
+				return err
+			}
+			if err := audit.RecordAudit(householdID, userID, orig.ID, models.AuditUpdate, changes); err != nil {
+				return err
+			}
+		}
+		for i := range legs {
+			if err := audit.RecordAudit(householdID, userID, legs[i].ID, models.AuditDelete, map[string][2]any{"transaction": {&legs[i], nil}}); err != nil {
+				return err
+			}
+		}
+
+		if err := resolveSuspect(tx, &sus, models.DuplicateMerged); err != nil {
+			return err
+		}
+		// Other suspects pointing at the removed legs no longer make sense.
+		return tx.Where("id <> ? AND (transaction_id IN ? OR duplicate_of_id IN ?)", sus.ID, removed, removed).
+			Delete(&models.DuplicateSuspect{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sus, nil
+}
+
+func pendingSuspect(tx *gorm.DB, householdID, id uint, out *models.DuplicateSuspect) error {
+	if err := tx.Where("id = ? AND household_id = ?", id, householdID).First(out).Error; err != nil {
+		return err
+	}
+	if out.Status != models.DuplicatePending {
+		return ErrDuplicateResolved
+	}
+	return nil
+}
+
+func resolveSuspect(tx *gorm.DB, sus *models.DuplicateSuspect, status string) error {
+	now := time.Now()
+	sus.Status = status
+	sus.ResolvedAt = &now
+	return tx.Model(sus).Updates(map[string]any{"status": status, "resolved_at": now}).Error
+}
+
+// duplicateScore reports how alike two legs are. Memos must meet the
+// similarity threshold; when either memo is blank the amounts must match
+// exactly and fall on the same day instead.
+func duplicateScore(a, b *models.Transaction, rules DuplicateRules) (float64, bool) {
+	if (a.AmountCents < 0) != (b.AmountCents < 0) {
+		return 0, false
+	}
+	if a.ExternalID != nil && b.ExternalID != nil && *a.ExternalID != *b.ExternalID {
+		// Both carry distinct bank ids: the bank says they are different.
+		return 0, false
+	}
+	if strings.TrimSpace(a.Memo) == "" || strings.TrimSpace(b.Memo) == "" {
+		sameDay := a.OccurredAt.UTC().Format("2006-01-02") == b.OccurredAt.UTC().Format("2006-01-02")
+		if a.AmountCents == b.AmountCents && sameDay {
+			return 0.5, true
+		}
+		return 0, false
+	}
+	sim := memoSimilarity(a.Memo, b.Memo)
+	if sim < rules.MinMemoSimilarity {
+		return 0, false
+	}
+	return sim, true
+}
+
+// memoSimilarity is the Dice coefficient of the memos' word sets, ignoring
+// case, punctuation and numbers (card suffixes, reference codes).
+func memoSimilarity(a, b string) float64 {
+	ta, tb := memoTokens(a), memoTokens(b)
+	if len(ta) == 0 && len(tb) == 0 {
+		return 1
+	}
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(ta)+len(tb))
+}
+
+func memoTokens(s string) map[string]struct{} {
+	out := map[string]struct{}{}
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len(f) > 1 {
+			out[f] = struct{}{}
+		}
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestMemoSimilarity(t *testing.T) {
+	if s := memoSimilarity("COFFEE SHOP #1234", "Coffee Shop card 5678"); s < 0.75 {
+		t.Errorf("expected similar memos, got %.2f", s)
+	}
+	if s := memoSimilarity("Rent", "Grocery store"); s != 0 {
+		t.Errorf("expected unrelated memos to score 0, got %.2f", s)
+	}
+}
+
+func TestDuplicateDetectAndMerge(t *testing.T) {
+	gdb := newTestDB(t)
+	acc := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&acc)
+	ledger := LedgerStore{DB: gdb}
+	dups := DuplicateStore{DB: gdb}
+	day := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cat := uint(7)
+	orig := &models.Transaction{AccountID: acc.ID, AmountCents: -450, Currency: "USD", Memo: "COFFEE SHOP", CategoryID: &cat, OccurredAt: day}
+	if _, err := ledger.PostStandard(1, orig); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	other := &models.Transaction{AccountID: acc.ID, AmountCents: -450, Currency: "USD", Memo: "BOOKSTORE", OccurredAt: day}
+	ledger.PostStandard(1, other)
+
+	fitid := "A1"
+	dup := &models.Transaction{AccountID: acc.ID, AmountCents: -450, Currency: "USD", Memo: "Coffee Shop 1234", ExternalID: &fitid, OccurredAt: day.AddDate(0, 0, 1)}
+	ledger.PostStandard(1, dup)
+	found, err := dups.Detect(1, dup, DefaultDuplicateRules)
+	if err != nil {
+		t.Fatalf("detect: %v", err)
+	}
+	if len(found) != 1 || found[0].DuplicateOfID != orig.ID {
+		t.Fatalf("expected one suspect against the original, got %+v", found)
+	}
+	if again, _ := dups.Detect(1, dup, DefaultDuplicateRules); len(again) != 0 {
+		t.Fatalf("re-detecting must not duplicate suspects, got %d", len(again))
+	}
+
+	if _, err := dups.Merge(1, 9, found[0].ID); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	var remaining int64
+	gdb.Model(&models.Transaction{}).Where("entry_id = ?", dup.EntryID).Count(&remaining)
+	if remaining != 0 {
+		t.Fatalf("expected duplicate entry removed, %d legs remain", remaining)
+	}
+	var kept models.Transaction
+	gdb.First(&kept, orig.ID)
+	if kept.ExternalID == nil || *kept.ExternalID != "A1" || kept.CategoryID == nil {
+		t.Fatalf("expected original to keep category and gain bank id: %+v", kept)
+	}
+	audit := TransactionStore{DB: gdb}
+	if trail, _ := audit.ListAudit(orig.ID); len(trail) != 1 || trail[0].Action != models.AuditUpdate {
+		t.Fatalf("expected the original's changes audited, got %+v", trail)
+	}
+	if trail, _ := audit.ListAudit(dup.ID); len(trail) != 1 || trail[0].Action != models.AuditDelete {
+		t.Fatalf("expected the duplicate's removal audited, got %+v", trail)
+	}
+	if _, err := dups.Dismiss(1, found[0].ID); err != ErrDuplicateResolved {
+		t.Fatalf("expected ErrDuplicateResolved, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package db
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// DefaultForecastLookbackDays is how much history the forecast averages
+// spending over.
+const DefaultForecastLookbackDays = 90
+
+// Forecast event kinds.
+const (
+	ForecastEventScheduled = "scheduled"
+	ForecastEventBill      = "bill"
+)
+
+// ForecastPoint is an account's projected closing balance for a day.
+type ForecastPoint struct {
+	Date           string `json:"date"` // YYYY-MM-DD
+	BalanceCents   int64  `json:"balance_cents"`
+	BelowThreshold bool   `json:"below_threshold"`
+}
+
+// AccountForecast projects one account day by day.
+type AccountForecast struct {
+	AccountID            uint            `json:"account_id"`
+	Name                 string          `json:"name"`
+	Currency             string          `json:"currency"`
+	StartingBalanceCents int64           `json:"starting_balance_cents"`
+	DailySpendingCents   int64           `json:"daily_spending_cents"`
+	Points               []ForecastPoint `json:"points"`
+}
+
+// ForecastEvent is a dated amount the forecast expects: an occurrence of a
+// scheduled transaction or a bill. A scheduled transfer appears once per
+// account it moves money between.
+type ForecastEvent struct {
+	Date        string `json:"date"`
+	AccountID   uint   `json:"account_id"`
+	Kind        string `json:"kind"`
+	SourceID    uint   `json:"source_id"`
+	Name        string `json:"name"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+// CategorySpending is a category's average spending over the lookback,
+// outside bills and scheduled transactions. CategoryID is nil for
+// uncategorized spending.
+type CategorySpending struct {
+	CategoryID          *uint `json:"category_id"`
+	AverageMonthlyCents int64 `json:"average_monthly_cents"`
+}
+
+// Forecast is a household's projected cash flow. LowBalanceDates lists the
+// days on which at least one account closes below ThresholdCents.
+type Forecast struct {
+	Start           string             `json:"start"`
+	Days            int                `json:"days"`
+	LookbackDays    int                `json:"lookback_days"`
+	ThresholdCents  int64              `json:"threshold_cents"`
+	Accounts        []AccountForecast  `json:"accounts"`
+	Events          []ForecastEvent    `json:"events"`
+	Categories      []CategorySpending `json:"categories"`
+	LowBalanceDates []string           `json:"low_balance_dates"`
+}
+
+type ForecastStore struct {
+	DB *gorm.DB
+}
+
+// Forecast projects each open account's balance for days days starting
+// today. It starts from current balances, applies the household's scheduled
+// transactions and unpaid bills on their dates (anything overdue lands
+// today), and spends each account's average daily spending per category
+// over the last lookback days from tomorrow on. Legs that paid bills or were
+// posted by scheduled transactions are left out of the averages, since those
+// are forecast explicitly. Bills without an account are charged to the
+// account that does the most spending.
+func (s *ForecastStore) Forecast(householdID uint, now time.Time, days, lookback int, threshold int64) (*Forecast, error) {
+	if lookback <= 0 {
+		lookback = DefaultForecastLookbackDays
+	}
+	today := truncateDay(now)
+	end := today.AddDate(0, 0, days-1)
+	f := &Forecast{
+		Start:           today.Format("2006-01-02"),
+		Days:            days,
+		LookbackDays:    lookback,
+		ThresholdCents:  threshold,
+		Accounts:        []AccountForecast{},
+		Events:          []ForecastEvent{},
+		Categories:      []CategorySpending{},
+		LowBalanceDates: []string{},
+	}
+
+	accountStore := AccountStore{DB: s.DB}
+	accounts, err := accountStore.ListByHousehold(householdID, false)
+	if err != nil || len(accounts) == 0 {
+		return f, err
+	}
+	balances, err := accountStore.Balances(accounts)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[uint]int, len(accounts))
+	for i, a := range accounts {
+		index[a.ID] = i
+	}
+
+	rates, err := s.spendingRates(householdID, today.AddDate(0, 0, -lookback), today)
+	if err != nil {
+		return nil, err
+	}
+	daily := make([]float64, len(accounts))
+	byCategory := map[uint]int64{}
+	var uncategorized int64
+	for _, r := range rates {
+		i, ok := index[r.AccountID]
+		if !ok || r.NetCents >= 0 {
+			continue
+		}
+		daily[i] += float64(-r.NetCents) / float64(lookback)
+		if r.CategoryID == nil {
+			uncategorized += -r.NetCents
+		} else {
+			byCategory[*r.CategoryID] += -r.NetCents
+		}
+	}
+	monthly := func(total int64) int64 {
+		return int64(math.Round(float64(total) * 30 / float64(lookback)))
+	}
+	for id, total := range byCategory {
+		id := id
+		f.Categories = append(f.Categories, CategorySpending{CategoryID: &id, AverageMonthlyCents: monthly(total)})
+	}
+	sort.Slice(f.Categories, func(a, b int) bool { return *f.Categories[a].CategoryID < *f.Categories[b].CategoryID })
+	if uncategorized > 0 {
+		f.Categories = append(f.Categories, CategorySpending{AverageMonthlyCents: monthly(uncategorized)})
+	}
+
+	fallback := 0
+	for i := range daily {
+		if daily[i] > daily[fallback] {
+			fallback = i
+		}
+	}
+
+	// deltas[i][d] is the explicit change to account i on day d.
+	deltas := make([][]int64, len(accounts))
+	for i := range deltas {
+		deltas[i] = make([]int64, days)
+	}
+	addEvent := func(date time.Time, accountID uint, kind string, sourceID uint, name string, amount int64) {
+		i, ok := index[accountID]
+		if !ok || date.After(end) {
+			return
+		}
+		if date.Before(today) {
+			date = today
+		}
+		deltas[i][int(date.Sub(today).Hours()/24)] += amount
+		f.Events = append(f.Events, ForecastEvent{
+			Date: date.Format("2006-01-02"), AccountID: accountID, Kind: kind, SourceID: sourceID, Name: name, AmountCents: amount,
+		})
+	}
+
+	scheduledStore := ScheduledTransactionStore{DB: s.DB}
+	scheduled, err := scheduledStore.ListByHousehold(householdID, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range scheduled {
+		st := &scheduled[i]
+		for _, p := range Preview(st, days+1) {
+			date := truncateDay(p.Date)
+			addEvent(date, st.AccountID, ForecastEventScheduled, st.ID, p.Memo, p.AmountCents)
+			if st.CounterAccountID != nil {
+				addEvent(date, *st.CounterAccountID, ForecastEventScheduled, st.ID, p.Memo, -p.AmountCents)
+			}
+		}
+	}
+
+	billStore := BillStore{DB: s.DB}
+	bills, err := billStore.ListByHousehold(householdID, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range bills {
+		b := &bills[i]
+		accountID := accounts[fallback].ID
+		if b.AccountID != nil {
+			accountID = *b.AccountID
+		}
+		for _, due := range Upcoming(b, days+1) {
+			addEvent(truncateDay(due), accountID, ForecastEventBill, b.ID, b.Name, -b.AmountCents)
+		}
+	}
+	sort.SliceStable(f.Events, func(a, b int) bool { return f.Events[a].Date < f.Events[b].Date })
+
+	low := map[string]bool{}
+	for i, acc := range accounts {
+		af := AccountForecast{
+			AccountID:            acc.ID,
+			Name:                 acc.Name,
+			Currency:             acc.Currency,
+			StartingBalanceCents: balances[acc.ID],
+			DailySpendingCents:   int64(math.Round(daily[i])),
+			Points:               make([]ForecastPoint, 0, days),
+		}
+		balance := balances[acc.ID]
+		for d := 0; d < days; d++ {
+			balance += deltas[i][d]
+			// Spending accrues from tomorrow, rounded on the running total so
+			// fractional cents are not lost.
+			projected := balance - int64(math.Round(daily[i]*float64(d)))
+			date := today.AddDate(0, 0, d).Format("2006-01-02")
+			below := projected < threshold
+			if below {
+				low[date] = true
+			}
+			af.Points = append(af.Points, ForecastPoint{Date: date, BalanceCents: projected, BelowThreshold: below})
+		}
+		f.Accounts = append(f.Accounts, af)
+	}
+	for date := range low {
+		f.LowBalanceDates = append(f.LowBalanceDates, date)
+	}
+	sort.Strings(f.LowBalanceDates)
+	return f, nil
+}
+
+type spendingRate struct {
+	AccountID  uint
+	CategoryID *uint
+	NetCents   int64
+}
+
+// spendingRates nets the household's income and spending per account and
+// category over [start, end): unsplit legs by their own category plus split
+// lines. Only standard entries count, so transfers between the household's
+// accounts are ignored, as are bill payments and scheduled postings.
+func (s *ForecastStore) spendingRates(householdID uint, start, end time.Time) ([]spendingRate, error) {
+	var rows []spendingRate
+	err := s.DB.Raw(`
+		SELECT account_id, category_id, COALESCE(SUM(amount_cents), 0) AS net_cents FROM (
+			SELECT t.account_id AS account_id, t.category_id AS category_id, t.amount_cents AS amount_cents
+			FROM transactions t
+			JOIN accounts a ON a.id = t.account_id
+			LEFT JOIN journal_entries je ON je.id = t.entry_id
+			WHERE a.household_id = ? AND a.type <> ? AND (je.id IS NULL OR je.kind = ?)
+				AND t.occurred_at >= ? AND t.occurred_at < ?
+				AND NOT EXISTS (SELECT 1 FROM transaction_splits sp WHERE sp.transaction_id = t.id)
+				AND NOT EXISTS (SELECT 1 FROM bill_payments bp WHERE bp.transaction_id = t.id)
+				AND NOT EXISTS (SELECT 1 FROM scheduled_occurrences so WHERE so.entry_id = t.entry_id)
+			UNION ALL
+			SELECT t.account_id, sp.category_id, sp.amount_cents
+			FROM transaction_splits sp
+			JOIN transactions t ON t.id = sp.transaction_id
+			JOIN accounts a ON a.id = t.account_id
+			LEFT JOIN journal_entries je ON je.id = t.entry_id
+			WHERE a.household_id = ? AND a.type <> ? AND (je.id IS NULL OR je.kind = ?)
+				AND t.occurred_at >= ? AND t.occurred_at < ?
+				AND NOT EXISTS (SELECT 1 FROM bill_payments bp WHERE bp.transaction_id = t.id)
+				AND NOT EXISTS (SELECT 1 FROM scheduled_occurrences so WHERE so.entry_id = t.entry_id)
+		) GROUP BY account_id, category_id`,
+		householdID, models.AccountTypeExternal, models.EntryKindStandard, start, end,
+		householdID, models.AccountTypeExternal, models.EntryKindStandard, start, end,
+	).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestForecast(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Bill{}, &models.BillPayment{}, &models.ScheduledTransaction{}, &models.ScheduledOccurrence{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	savings := models.Account{HouseholdID: 1, Name: "Savings", Type: "savings", Currency: "USD"}
+	gdb.Create(&checking)
+	gdb.Create(&savings)
+	groceries := models.Category{HouseholdID: 1, Name: "Groceries"}
+	salary := models.Category{HouseholdID: 1, Name: "Salary"}
+	housing := models.Category{HouseholdID: 1, Name: "Housing"}
+	gdb.Create(&groceries)
+	gdb.Create(&salary)
+	gdb.Create(&housing)
+
+	ledger := LedgerStore{DB: gdb}
+	at := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 9, 0, 0, 0, time.UTC) }
+	post := func(cents int64, cat *models.Category, when time.Time) *models.Transaction {
+		t.Helper()
+		leg := &models.Transaction{AccountID: checking.ID, AmountCents: cents, Currency: "USD", OccurredAt: when}
+		if cat != nil {
+			leg.CategoryID = &cat.ID
+		}
+		if _, err := ledger.PostStandard(1, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		return leg
+	}
+	post(-4000, &groceries, at(time.April, 10))
+	post(-5000, &groceries, at(time.May, 10))
+	post(300000, &salary, at(time.May, 15))
+	rentPaid := post(-50000, &housing, at(time.May, 5))
+	gdb.Create(&models.BillPayment{BillID: 99, DueDate: at(time.May, 5), TransactionID: rentPaid.ID})
+	if _, err := ledger.PostTransfer(1, savings.ID, &models.Transaction{AccountID: checking.ID, AmountCents: -20000, Currency: "USD", OccurredAt: at(time.May, 20)}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	scheduled := ScheduledTransactionStore{DB: gdb}
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	paycheck := &models.ScheduledTransaction{HouseholdID: 1, UserID: 7, AccountID: checking.ID, AmountCents: 100000, Currency: "USD", Memo: "Paycheck"}
+	SetScheduledRule(paycheck, "FREQ=MONTHLY", day(time.June, 15), nil)
+	scheduled.Reschedule(paycheck, time.Time{})
+	scheduled.Create(paycheck)
+	saving := &models.ScheduledTransaction{HouseholdID: 1, UserID: 7, AccountID: checking.ID, CounterAccountID: &savings.ID, AmountCents: -10000, Currency: "USD", Memo: "Save"}
+	SetScheduledRule(saving, "FREQ=MONTHLY", day(time.June, 10), nil)
+	scheduled.Reschedule(saving, time.Time{})
+	scheduled.Create(saving)
+
+	// No account: charged to checking, which does the spending.
+	rent := &models.Bill{UserID: 7, HouseholdID: 1, Name: "Rent", AmountCents: 250000}
+	SetSchedule(rent, "FREQ=MONTHLY", day(time.June, 5))
+	gdb.Create(rent)
+
+	store := ForecastStore{DB: gdb}
+	f, err := store.Forecast(1, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), 30, 90, DefaultLowBalanceThreshold)
+	if err != nil {
+		t.Fatalf("forecast: %v", err)
+	}
+	if len(f.Accounts) != 2 || len(f.Accounts[0].Points) != 30 {
+		t.Fatalf("unexpected forecast shape %+v", f)
+	}
+	check := f.Accounts[0]
+	if check.StartingBalanceCents != 221000 || check.DailySpendingCents != 100 {
+		t.Fatalf("unexpected checking start %+v", check)
+	}
+	want := map[int]int64{
+		0:  221000,
+		4:  221000 - 400 - 250000,
+		9:  221000 - 900 - 250000 - 10000,
+		14: 221000 - 1400 - 250000 - 10000 + 100000,
+	}
+	for d, cents := range want {
+		if check.Points[d].BalanceCents != cents {
+			t.Errorf("day %d: got %d, want %d", d, check.Points[d].BalanceCents, cents)
+		}
+	}
+	if !check.Points[4].BelowThreshold || check.Points[14].BelowThreshold {
+		t.Fatalf("unexpected threshold flags: %+v / %+v", check.Points[4], check.Points[14])
+	}
+	if got := f.Accounts[1].Points[9].BalanceCents; got != 30000 {
+		t.Fatalf("expected the scheduled transfer to reach savings, got %d", got)
+	}
+	if len(f.LowBalanceDates) != 10 || f.LowBalanceDates[0] != "2025-06-05" || f.LowBalanceDates[9] != "2025-06-14" {
+		t.Fatalf("unexpected low-balance dates %v", f.LowBalanceDates)
+	}
+	if len(f.Categories) != 1 || *f.Categories[0].CategoryID != groceries.ID || f.Categories[0].AverageMonthlyCents != 3000 {
+		t.Fatalf("unexpected category averages %+v", f.Categories)
+	}
+	if len(f.Events) != 4 || f.Events[0].Kind != ForecastEventBill || f.Events[0].Date != "2025-06-05" {
+		t.Fatalf("unexpected events %+v", f.Events)
+	}
+}
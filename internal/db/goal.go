@@ -1,10 +1,22 @@
 package db
 
 import (
+	"errors"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"bookkeeper-backend/internal/models"
 )
 
+var (
+	ErrInvalidMilestones = errors.New("milestones must be 1 to 10 distinct percentages between 1 and 100")
+	ErrGoalLink          = errors.New("a goal can be linked to an account or a category, not both")
+)
+
+// maxGoalMilestones caps how many milestones a goal can have.
+const maxGoalMilestones = 10
+
 type GoalStore struct {
 	DB *gorm.DB
 }
@@ -16,3 +28,132 @@ func (s *GoalStore) ListByUser(userID uint) ([]models.Goal, error) {
 	}
 	return goals, nil
 }
+
+// Get loads one of the user's goals with the milestones it has reached.
+func (s *GoalStore) Get(userID, id uint) (*models.Goal, error) {
+	var g models.Goal
+	err := s.DB.Preload("Reached", func(db *gorm.DB) *gorm.DB { return db.Order("percent asc") }).
+		Where("id = ? AND user_id = ?", id, userID).First(&g).Error
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// ListLinked returns the goals that track an account or category of the
+// household.
+func (s *GoalStore) ListLinked(householdID uint) ([]models.Goal, error) {
+	var goals []models.Goal
+	err := s.DB.Where("household_id = ? AND (account_id IS NOT NULL OR category_id IS NOT NULL)", householdID).
+		Order("id asc").Find(&goals).Error
+	return goals, err
+}
+
+// SetMilestones validates milestones and stores them on g (not saved). An
+// empty list means the defaults.
+func SetMilestones(g *models.Goal, milestones []int) error {
+	stored, _, ok := formatPercents(milestones, maxGoalMilestones, 100)
+	if !ok {
+		return ErrInvalidMilestones
+	}
+	g.Milestones = stored
+	return nil
+}
+
+// Milestones returns the goal's milestones in ascending order.
+func Milestones(g *models.Goal) []int {
+	return parsePercents(g.Milestones, models.DefaultGoalMilestones)
+}
+
+func (s *GoalStore) Create(g *models.Goal) error {
+	if g.AccountID != nil && g.CategoryID != nil {
+		return ErrGoalLink
+	}
+	return s.DB.Create(g).Error
+}
+
+func (s *GoalStore) Save(g *models.Goal) error {
+	if g.AccountID != nil && g.CategoryID != nil {
+		return ErrGoalLink
+	}
+	return s.DB.Omit("Reached").Save(g).Error
+}
+
+func (s *GoalStore) Delete(g *models.Goal) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("goal_id = ?", g.ID).Delete(&models.GoalMilestone{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(g).Error
+	})
+}
+
+// Progress returns how much the goal had saved at asOf: the linked account's
+// balance, the net amount put into the linked category from the day the goal
+// was created through asOf's day, or the manually entered amount for
+// unlinked goals.
+func (s *GoalStore) Progress(g *models.Goal, asOf time.Time) (int64, error) {
+	switch {
+	case g.AccountID != nil:
+		var acc models.Account
+		if err := s.DB.First(&acc, *g.AccountID).Error; err != nil {
+			return 0, err
+		}
+		accountStore := AccountStore{DB: s.DB}
+		return accountStore.BalanceAsOf(&acc, asOf)
+	case g.CategoryID != nil && g.HouseholdID != nil:
+		// Whole days: from the day the goal was created through asOf's day.
+		start := truncateDay(g.CreatedAt)
+		end := truncateDay(asOf).AddDate(0, 0, 1)
+		if !end.After(start) {
+			return 0, nil
+		}
+		budgetStore := BudgetStore{DB: s.DB}
+		actuals, err := budgetStore.MonthActuals(*g.HouseholdID, start, end)
+		if err != nil {
+			return 0, err
+		}
+		// Money set aside leaves the spending accounts, so it is negative.
+		return -actuals[*g.CategoryID], nil
+	}
+	return g.CurrentCents, nil
+}
+
+// Refresh recomputes a linked goal's progress, saves it, and records every
+// milestone reached for the first time. It returns the newly reached
+// milestones in ascending order.
+func (s *GoalStore) Refresh(g *models.Goal) ([]int, error) {
+	current, err := s.Progress(g, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if current != g.CurrentCents {
+		if err := s.DB.Model(g).UpdateColumn("current_cents", current).Error; err != nil {
+			return nil, err
+		}
+		g.CurrentCents = current
+	}
+	if g.TargetCents <= 0 {
+		return nil, nil
+	}
+	var reached []int
+	for _, m := range Milestones(g) {
+		if current*100 < g.TargetCents*int64(m) {
+			break
+		}
+		ms := models.GoalMilestone{GoalID: g.ID, Percent: m, CurrentCents: current, ReachedAt: time.Now()}
+		res := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&ms)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			reached = append(reached, m)
+		}
+	}
+	return reached, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"math"
+	"time"
+
+	"bookkeeper-backend/internal/calculators"
+	"bookkeeper-backend/internal/models"
+)
+
+// Goal projection statuses.
+const (
+	GoalStatusComplete  = "complete"
+	GoalStatusOnTrack   = "on_track"
+	GoalStatusBehind    = "behind"
+	GoalStatusNoDueDate = "no_due_date"
+)
+
+// DefaultGoalLookbackMonths is how much contribution history a projection
+// averages when the caller does not choose.
+const DefaultGoalLookbackMonths = 6
+
+// GoalHistoryPoint is the goal's progress at the end of a lookback step.
+type GoalHistoryPoint struct {
+	AsOf          time.Time `json:"as_of"`
+	ProgressCents int64     `json:"progress_cents"`
+}
+
+// GoalProjection extrapolates a goal from its recent contributions.
+// ExpectedCompletion is nil when nothing is being contributed, and
+// RequiredMonthlyCents is only set for goals with a due date.
+type GoalProjection struct {
+	GoalID               uint               `json:"goal_id"`
+	CurrentCents         int64              `json:"current_cents"`
+	TargetCents          int64              `json:"target_cents"`
+	RemainingCents       int64              `json:"remaining_cents"`
+	LookbackMonths       int                `json:"lookback_months"`
+	AverageMonthlyCents  int64              `json:"average_monthly_cents"`
+	ExpectedCompletion   *time.Time         `json:"expected_completion"`
+	DueDate              *time.Time         `json:"due_date"`
+	MonthsRemaining      int                `json:"months_remaining"`
+	RequiredMonthlyCents int64              `json:"required_monthly_cents"`
+	Status               string             `json:"status"`
+	History              []GoalHistoryPoint `json:"history"`
+}
+
+// Project reports when the goal will be reached at its average monthly
+// contribution over the last lookback months, and what it would take to hit
+// the due date. Account goals use the account's balance history; category
+// and manual goals only look back as far as the goal's creation, and manual
+// goals assume they started from zero.
+func (s *GoalStore) Project(g *models.Goal, now time.Time, lookback int, annualReturn float64) (*GoalProjection, error) {
+	if lookback <= 0 {
+		lookback = DefaultGoalLookbackMonths
+	}
+	current, err := s.Progress(g, now)
+	if err != nil {
+		return nil, err
+	}
+	p := &GoalProjection{
+		GoalID:       g.ID,
+		CurrentCents: current,
+		TargetCents:  g.TargetCents,
+		DueDate:      g.DueDate,
+		History:      []GoalHistoryPoint{},
+	}
+	if remaining := g.TargetCents - current; remaining > 0 {
+		p.RemainingCents = remaining
+	}
+
+	if g.AccountID == nil {
+		lookback = min(lookback, max(1, monthsSince(g.CreatedAt, now)))
+	}
+	p.LookbackMonths = lookback
+	if g.AccountID == nil && g.CategoryID == nil {
+		p.AverageMonthlyCents = current / int64(lookback)
+	} else {
+		for i := lookback; i >= 0; i-- {
+			asOf := now.AddDate(0, -i, 0)
+			v, err := s.Progress(g, asOf)
+			if err != nil {
+				return nil, err
+			}
+			p.History = append(p.History, GoalHistoryPoint{AsOf: asOf, ProgressCents: v})
+		}
+		p.AverageMonthlyCents = (current - p.History[0].ProgressCents) / int64(lookback)
+	}
+
+	if p.RemainingCents == 0 {
+		p.Status = GoalStatusComplete
+		done := now
+		p.ExpectedCompletion = &done
+		return p, nil
+	}
+	if p.AverageMonthlyCents > 0 {
+		months, _, err := calculators.SavingsGoal(float64(g.TargetCents), float64(current), float64(p.AverageMonthlyCents), annualReturn)
+		if err == nil {
+			eta := now.AddDate(0, months, 0)
+			p.ExpectedCompletion = &eta
+		}
+	}
+	if g.DueDate == nil {
+		p.Status = GoalStatusNoDueDate
+		return p, nil
+	}
+
+	p.MonthsRemaining = monthsUntil(now, *g.DueDate)
+	p.RequiredMonthlyCents = p.RemainingCents
+	if p.MonthsRemaining > 0 {
+		required, err := calculators.RequiredMonthlyContribution(float64(g.TargetCents), float64(current), p.MonthsRemaining, annualReturn)
+		if err != nil {
+			return nil, err
+		}
+		p.RequiredMonthlyCents = int64(math.Ceil(required))
+	}
+	p.Status = GoalStatusBehind
+	if p.ExpectedCompletion != nil && !truncateDay(*p.ExpectedCompletion).After(truncateDay(*g.DueDate)) {
+		p.Status = GoalStatusOnTrack
+	}
+	return p, nil
+}
+
+// ListWithDueDate returns every goal that has a due date, for the
+// projection job.
+func (s *GoalStore) ListWithDueDate() ([]models.Goal, error) {
+	var goals []models.Goal
+	err := s.DB.Where("due_date IS NOT NULL").Order("id asc").Find(&goals).Error
+	return goals, err
+}
+
+// SetProjectionStatus saves the status last notified for the goal.
+func (s *GoalStore) SetProjectionStatus(g *models.Goal, status string) error {
+	if err := s.DB.Model(g).UpdateColumn("projection_status", status).Error; err != nil {
+		return err
+	}
+	g.ProjectionStatus = status
+	return nil
+}
+
+// monthsUntil counts the months from from to to, a partial month counting
+// as a whole one.
+func monthsUntil(from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+	m := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	if from.AddDate(0, m, 0).Before(to) {
+		m++
+	}
+	return m
+}
+
+// monthsSince counts the whole months from from to to.
+func monthsSince(from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+	m := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	if from.AddDate(0, m, 0).After(to) {
+		m--
+	}
+	return m
+}
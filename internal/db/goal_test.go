@@ -0,0 +1,146 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestGoalMilestonesRecordedOnce(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Goal{}, &models.GoalMilestone{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	savings := models.Account{HouseholdID: 1, Name: "Savings", Type: "savings", Currency: "USD"}
+	gdb.Create(&savings)
+	ledger := LedgerStore{DB: gdb}
+	deposit := func(cents int64) {
+		t.Helper()
+		leg := &models.Transaction{AccountID: savings.ID, AmountCents: cents, Currency: "USD", OccurredAt: time.Now().Add(-time.Minute)}
+		if _, err := ledger.PostStandard(1, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+	hID := uint(1)
+	store := GoalStore{DB: gdb}
+	goal := &models.Goal{UserID: 7, HouseholdID: &hID, AccountID: &savings.ID, Name: "Trip", TargetCents: 100000}
+	if err := SetMilestones(goal, []int{75, 25, 50}); err != nil {
+		t.Fatalf("set milestones: %v", err)
+	}
+	if err := store.Create(goal); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	deposit(30000)
+	reached, err := store.Refresh(goal)
+	if err != nil || len(reached) != 1 || reached[0] != 25 || goal.CurrentCents != 30000 {
+		t.Fatalf("expected 25%% milestone, got %v (%v), current %d", reached, err, goal.CurrentCents)
+	}
+	if reached, _ := store.Refresh(goal); len(reached) != 0 {
+		t.Fatalf("milestone must not be reported twice, got %v", reached)
+	}
+	deposit(50000)
+	if reached, _ := store.Refresh(goal); len(reached) != 2 || reached[1] != 75 {
+		t.Fatalf("expected 50%% and 75%%, got %v", reached)
+	}
+	loaded, err := store.Get(7, goal.ID)
+	if err != nil || len(loaded.Reached) != 3 {
+		t.Fatalf("expected 3 recorded milestones, got %v (%v)", loaded, err)
+	}
+	if linked, _ := store.ListLinked(1); len(linked) != 1 {
+		t.Fatalf("expected linked goal, got %v", linked)
+	}
+	if _, err := store.Get(8, goal.ID); err == nil {
+		t.Fatalf("another user must not load the goal")
+	}
+}
+
+func TestGoalProgressFromCategory(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Goal{}, &models.GoalMilestone{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&checking)
+	fund := models.Category{HouseholdID: 1, Name: "Emergency fund"}
+	gdb.Create(&fund)
+	hID := uint(1)
+	store := GoalStore{DB: gdb}
+	goal := &models.Goal{UserID: 7, HouseholdID: &hID, CategoryID: &fund.ID, Name: "Emergency", TargetCents: 10000}
+	if err := store.Create(goal); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ledger := LedgerStore{DB: gdb}
+	for _, leg := range []*models.Transaction{
+		// Set aside before the goal existed: not counted.
+		{AccountID: checking.ID, AmountCents: -9000, Currency: "USD", CategoryID: &fund.ID, OccurredAt: goal.CreatedAt.AddDate(0, 0, -2)},
+		{AccountID: checking.ID, AmountCents: -6000, Currency: "USD", CategoryID: &fund.ID, OccurredAt: goal.CreatedAt},
+	} {
+		if _, err := ledger.PostStandard(1, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+	reached, err := store.Refresh(goal)
+	if err != nil || goal.CurrentCents != 6000 || len(reached) != 1 || reached[0] != 50 {
+		t.Fatalf("expected 6000 and the 50%% default milestone, got %d %v (%v)", goal.CurrentCents, reached, err)
+	}
+
+	goal.AccountID = &checking.ID
+	if err := store.Save(goal); !errors.Is(err, ErrGoalLink) {
+		t.Fatalf("expected link error, got %v", err)
+	}
+	if err := SetMilestones(goal, []int{150}); !errors.Is(err, ErrInvalidMilestones) {
+		t.Fatalf("expected invalid milestones, got %v", err)
+	}
+}
+
+func TestGoalProjection(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.Goal{}, &models.GoalMilestone{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	savings := models.Account{HouseholdID: 1, Name: "Savings", Type: "savings", Currency: "USD"}
+	gdb.Create(&savings)
+	ledger := LedgerStore{DB: gdb}
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	// $100 a month for the last six months.
+	for i := 6; i >= 1; i-- {
+		leg := &models.Transaction{AccountID: savings.ID, AmountCents: 10000, Currency: "USD", OccurredAt: now.AddDate(0, -i, 1)}
+		if _, err := ledger.PostStandard(1, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+	hID := uint(1)
+	store := GoalStore{DB: gdb}
+	due := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	goal := &models.Goal{UserID: 7, HouseholdID: &hID, AccountID: &savings.ID, Name: "Car", TargetCents: 120000, DueDate: &due}
+	store.Create(goal)
+
+	p, err := store.Project(goal, now, 6, 0)
+	if err != nil {
+		t.Fatalf("project: %v", err)
+	}
+	if p.CurrentCents != 60000 || p.AverageMonthlyCents != 10000 || len(p.History) != 7 {
+		t.Fatalf("unexpected pace: %+v", p)
+	}
+	if p.ExpectedCompletion == nil || !p.ExpectedCompletion.Equal(now.AddDate(0, 6, 0)) {
+		t.Fatalf("expected completion in 6 months, got %v", p.ExpectedCompletion)
+	}
+	if p.MonthsRemaining != 6 || p.RequiredMonthlyCents != 10000 || p.Status != GoalStatusOnTrack {
+		t.Fatalf("expected on track at $100/month, got %+v", p)
+	}
+
+	earlier := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+	goal.DueDate = &earlier
+	p, _ = store.Project(goal, now, 6, 0)
+	if p.Status != GoalStatusBehind || p.RequiredMonthlyCents != 20000 {
+		t.Fatalf("expected behind needing $200/month, got %+v", p)
+	}
+
+	goal.DueDate = nil
+	if p, _ := store.Project(goal, now, 6, 0); p.Status != GoalStatusNoDueDate || p.RequiredMonthlyCents != 0 {
+		t.Fatalf("expected no due date status, got %+v", p)
+	}
+}
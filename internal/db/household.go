@@ -0,0 +1,244 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInviteNotPending    = errors.New("invite has expired or was already used")
+	ErrInviteEmailMismatch = errors.New("invite was sent to a different email address")
+	ErrAlreadyMember       = errors.New("user is already a household member")
+	ErrNotMember           = errors.New("user is not a household member")
+	ErrLastOwner           = errors.New("the household owner must transfer ownership first")
+)
+
+// InviteTTL is how long an invite token stays valid.
+const InviteTTL = 7 * 24 * time.Hour
+
+// HouseholdMemberInfo is a member row joined with the user's email.
+type HouseholdMemberInfo struct {
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"joined_at"`
+}
+
+type HouseholdStore struct {
+	DB *gorm.DB
+}
+
+// CreateInvite stores a new invite and returns it with the raw token, which
+// is not recoverable afterwards.
+func (s *HouseholdStore) CreateInvite(householdID, invitedBy uint, email, role string) (*models.HouseholdInvite, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	inv := &models.HouseholdInvite{
+		HouseholdID: householdID,
+		InvitedBy:   invitedBy,
+		Email:       strings.TrimSpace(email),
+		Role:        role,
+		TokenHash:   hashInviteToken(token),
+		ExpiresAt:   time.Now().Add(InviteTTL),
+	}
+	if err := s.DB.Create(inv).Error; err != nil {
+		return nil, "", err
+	}
+	return inv, token, nil
+}
+
+// FindInviteByToken looks up an invite from its raw token.
+func (s *HouseholdStore) FindInviteByToken(token string) (*models.HouseholdInvite, error) {
+	var inv models.HouseholdInvite
+	if err := s.DB.Where("token_hash = ?", hashInviteToken(token)).First(&inv).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ListInvitesForEmail returns pending invites addressed to email.
+func (s *HouseholdStore) ListInvitesForEmail(email string) ([]models.HouseholdInvite, error) {
+	var out []models.HouseholdInvite
+	err := s.DB.Where("LOWER(email) = LOWER(?) AND accepted_at IS NULL AND declined_at IS NULL AND revoked_at IS NULL AND expires_at > ?", email, time.Now()).
+		Order("created_at desc").
+		Find(&out).Error
+	return out, err
+}
+
+// ListPendingInvites returns the household's outstanding invites.
+func (s *HouseholdStore) ListPendingInvites(householdID uint) ([]models.HouseholdInvite, error) {
+	var out []models.HouseholdInvite
+	err := s.DB.Where("household_id = ? AND accepted_at IS NULL AND declined_at IS NULL AND revoked_at IS NULL AND expires_at > ?", householdID, time.Now()).
+		Order("created_at desc").
+		Find(&out).Error
+	return out, err
+}
+
+// RevokeInvite cancels a pending invite.
+func (s *HouseholdStore) RevokeInvite(householdID, inviteID uint) error {
+	res := s.DB.Model(&models.HouseholdInvite{}).
+		Where("id = ? AND household_id = ? AND accepted_at IS NULL AND declined_at IS NULL AND revoked_at IS NULL", inviteID, householdID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInviteNotPending
+	}
+	return nil
+}
+
+// AcceptInvite adds user as a member with the invite's role. The invite is
+// consumed atomically so a token can only ever be used once.
+func (s *HouseholdStore) AcceptInvite(inv *models.HouseholdInvite, user *models.User) error {
+	if !strings.EqualFold(strings.TrimSpace(inv.Email), strings.TrimSpace(user.Email)) {
+		return ErrInviteEmailMismatch
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := consumeInvite(tx, inv, "accepted_at"); err != nil {
+			return err
+		}
+		var n int64
+		tx.Model(&models.HouseholdMember{}).Where("household_id = ? AND user_id = ?", inv.HouseholdID, user.ID).Count(&n)
+		if n > 0 {
+			return ErrAlreadyMember
+		}
+		return tx.Create(&models.HouseholdMember{
+			HouseholdID: inv.HouseholdID,
+			UserID:      user.ID,
+			Role:        inv.Role,
+			CreatedAt:   time.Now(),
+		}).Error
+	})
+}
+
+// DeclineInvite consumes the invite without joining.
+func (s *HouseholdStore) DeclineInvite(inv *models.HouseholdInvite, user *models.User) error {
+	if !strings.EqualFold(strings.TrimSpace(inv.Email), strings.TrimSpace(user.Email)) {
+		return ErrInviteEmailMismatch
+	}
+	return consumeInvite(s.DB, inv, "declined_at")
+}
+
+func consumeInvite(tx *gorm.DB, inv *models.HouseholdInvite, column string) error {
+	now := time.Now()
+	res := tx.Model(&models.HouseholdInvite{}).
+		Where("id = ? AND accepted_at IS NULL AND declined_at IS NULL AND revoked_at IS NULL AND expires_at > ?", inv.ID, now).
+		Update(column, now)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInviteNotPending
+	}
+	if column == "accepted_at" {
+		inv.AcceptedAt = &now
+	} else {
+		inv.DeclinedAt = &now
+	}
+	return nil
+}
+
+// ListMembers returns the household's members with their emails.
+func (s *HouseholdStore) ListMembers(householdID uint) ([]HouseholdMemberInfo, error) {
+	var out []HouseholdMemberInfo
+	err := s.DB.Table("household_members hm").
+		Select("hm.user_id, u.email, hm.role, hm.created_at").
+		Joins("JOIN users u ON u.id = hm.user_id").
+		Where("hm.household_id = ?", householdID).
+		Order("hm.created_at asc").
+		Scan(&out).Error
+	return out, err
+}
+
+// MemberIDs returns the user ids of everyone in the household.
+func (s *HouseholdStore) MemberIDs(householdID uint) ([]uint, error) {
+	var ids []uint
+	err := s.DB.Model(&models.HouseholdMember{}).Where("household_id = ?", householdID).
+		Order("user_id asc").Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// ListIDs returns every household id, for jobs that sweep all households.
+func (s *HouseholdStore) ListIDs() ([]uint, error) {
+	var ids []uint
+	err := s.DB.Model(&models.Household{}).Order("id asc").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// RemoveMember deletes a membership. The owner cannot be removed (or leave)
+// until ownership has been transferred. The user's goals linked to the
+// household's accounts or categories are unlinked, keeping the progress last
+// recorded, so they stop following the household's balances.
+func (s *HouseholdStore) RemoveMember(householdID, userID uint) error {
+	var hm models.HouseholdMember
+	if err := s.DB.Where("household_id = ? AND user_id = ?", householdID, userID).First(&hm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotMember
+		}
+		return err
+	}
+	if hm.Role == models.RoleOwner {
+		return ErrLastOwner
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Goal{}).Where("user_id = ? AND household_id = ?", userID, householdID).
+			Updates(map[string]any{"account_id": nil, "category_id": nil, "household_id": nil}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&hm).Error
+	})
+}
+
+// SetRole changes a member's role. The owner's role only changes through
+// TransferOwnership.
+func (s *HouseholdStore) SetRole(householdID, userID uint, role string) error {
+	var hm models.HouseholdMember
+	if err := s.DB.Where("household_id = ? AND user_id = ?", householdID, userID).First(&hm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotMember
+		}
+		return err
+	}
+	if hm.Role == models.RoleOwner {
+		return ErrLastOwner
+	}
+	return s.DB.Model(&hm).Update("role", role).Error
+}
+
+// TransferOwnership makes newOwnerID the owner and demotes the current owner
+// to an editor.
+func (s *HouseholdStore) TransferOwnership(householdID, currentOwnerID, newOwnerID uint) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		tx.Model(&models.HouseholdMember{}).Where("household_id = ? AND user_id = ?", householdID, newOwnerID).Count(&n)
+		if n == 0 {
+			return ErrNotMember
+		}
+		if err := tx.Model(&models.HouseholdMember{}).
+			Where("household_id = ? AND user_id = ?", householdID, currentOwnerID).
+			Update("role", models.RoleEditor).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.HouseholdMember{}).
+			Where("household_id = ? AND user_id = ?", householdID, newOwnerID).
+			Update("role", models.RoleOwner).Error
+	})
+}
+
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestHouseholdInviteLifecycle(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.User{}, &models.HouseholdMember{}, &models.HouseholdInvite{}, &models.Goal{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	owner := models.User{Email: "owner@example.com"}
+	invitee := models.User{Email: "Partner@Example.com"}
+	stranger := models.User{Email: "someone@example.com"}
+	gdb.Create(&owner)
+	gdb.Create(&invitee)
+	gdb.Create(&stranger)
+	gdb.Create(&models.HouseholdMember{HouseholdID: 1, UserID: owner.ID, Role: models.RoleOwner})
+	store := HouseholdStore{DB: gdb}
+
+	inv, token, err := store.CreateInvite(1, owner.ID, "partner@example.com", models.RoleEditor)
+	if err != nil || token == "" {
+		t.Fatalf("create invite: %v", err)
+	}
+	if inv.TokenHash == token {
+		t.Fatalf("raw token must not be stored")
+	}
+	found, err := store.FindInviteByToken(token)
+	if err != nil || found.ID != inv.ID {
+		t.Fatalf("find by token: %v", err)
+	}
+	if err := store.AcceptInvite(found, &stranger); !errors.Is(err, ErrInviteEmailMismatch) {
+		t.Fatalf("expected email mismatch, got %v", err)
+	}
+	if err := store.AcceptInvite(found, &invitee); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	again, _ := store.FindInviteByToken(token)
+	if err := store.AcceptInvite(again, &invitee); !errors.Is(err, ErrInviteNotPending) {
+		t.Fatalf("expected single-use token, got %v", err)
+	}
+
+	members, err := store.ListMembers(1)
+	if err != nil || len(members) != 2 {
+		t.Fatalf("expected 2 members, got %d (%v)", len(members), err)
+	}
+
+	if err := store.RemoveMember(1, owner.ID); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("expected owner removal to be refused, got %v", err)
+	}
+	if err := store.SetRole(1, owner.ID, models.RoleViewer); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("expected owner demotion to be refused, got %v", err)
+	}
+	if err := store.SetRole(1, invitee.ID, models.RoleViewer); err != nil {
+		t.Fatalf("set role: %v", err)
+	}
+	if err := store.TransferOwnership(1, owner.ID, invitee.ID); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	house, other, accountID := uint(1), uint(2), uint(5)
+	linked := models.Goal{UserID: owner.ID, HouseholdID: &house, AccountID: &accountID, Name: "Trip", TargetCents: 1000, CurrentCents: 400}
+	elsewhere := models.Goal{UserID: owner.ID, HouseholdID: &other, AccountID: &accountID, Name: "Car", TargetCents: 1000}
+	gdb.Create(&linked)
+	gdb.Create(&elsewhere)
+	if err := store.RemoveMember(1, owner.ID); err != nil {
+		t.Fatalf("former owner should be able to leave: %v", err)
+	}
+	gdb.First(&linked, linked.ID)
+	gdb.First(&elsewhere, elsewhere.ID)
+	if linked.HouseholdID != nil || linked.AccountID != nil || linked.CurrentCents != 400 || elsewhere.AccountID == nil {
+		t.Fatalf("expected only the household's goal unlinked, got %+v / %+v", linked, elsewhere)
+	}
+	if err := store.RemoveMember(1, owner.ID); !errors.Is(err, ErrNotMember) {
+		t.Fatalf("expected not member, got %v", err)
+	}
+}
+
+func TestHouseholdInviteExpiry(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.User{}, &models.HouseholdMember{}, &models.HouseholdInvite{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	user := models.User{Email: "late@example.com"}
+	gdb.Create(&user)
+	store := HouseholdStore{DB: gdb}
+
+	inv, _, err := store.CreateInvite(1, 99, user.Email, models.RoleEditor)
+	if err != nil {
+		t.Fatalf("create invite: %v", err)
+	}
+	gdb.Model(inv).Update("expires_at", time.Now().Add(-time.Hour))
+	if err := store.AcceptInvite(inv, &user); !errors.Is(err, ErrInviteNotPending) {
+		t.Fatalf("expected expired invite to be rejected, got %v", err)
+	}
+	if pending, _ := store.ListInvitesForEmail(user.Email); len(pending) != 0 {
+		t.Fatalf("expired invite should not be listed")
+	}
+}
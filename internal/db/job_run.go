@@ -0,0 +1,57 @@
+package db
+
+import (
+	"time"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type JobRunStore struct {
+	DB *gorm.DB
+}
+
+// Start records that job began running at startedAt.
+func (s *JobRunStore) Start(job string, startedAt time.Time) (*models.JobRun, error) {
+	run := &models.JobRun{Job: job, Status: models.JobRunRunning, StartedAt: startedAt}
+	if err := s.DB.Create(run).Error; err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Finish records the run's outcome; errMsg is empty on success.
+func (s *JobRunStore) Finish(run *models.JobRun, status, errMsg string, finishedAt time.Time) error {
+	run.Status, run.Error, run.FinishedAt = status, errMsg, &finishedAt
+	run.DurationMs = finishedAt.Sub(run.StartedAt).Milliseconds()
+	return s.DB.Model(run).Updates(map[string]any{
+		"status":      run.Status,
+		"error":       run.Error,
+		"finished_at": finishedAt,
+		"duration_ms": run.DurationMs,
+	}).Error
+}
+
+// MarkInterrupted closes out runs left running by a previous process.
+func (s *JobRunStore) MarkInterrupted() error {
+	return s.DB.Model(&models.JobRun{}).Where("status = ?", models.JobRunRunning).
+		Update("status", models.JobRunInterrupted).Error
+}
+
+// Recent returns up to limit runs, most recent first, optionally only for
+// job.
+func (s *JobRunStore) Recent(job string, limit int) ([]models.JobRun, error) {
+	q := s.DB.Order("started_at desc, id desc").Limit(limit)
+	if job != "" {
+		q = q.Where("job = ?", job)
+	}
+	var out []models.JobRun
+	err := q.Find(&out).Error
+	return out, err
+}
+
+// Prune deletes runs that started before cutoff.
+func (s *JobRunStore) Prune(cutoff time.Time) error {
+	return s.DB.Where("started_at < ?", cutoff).Delete(&models.JobRun{}).Error
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnbalancedEntry = errors.New("journal entry legs must sum to zero")
+	ErrInvalidEntry    = errors.New("journal entry needs at least two legs with accounts")
+	ErrMixedCurrency   = errors.New("journal entry legs must share one currency")
+	ErrForeignAccount  = errors.New("journal entry references an account outside the household")
+)
+
+// LedgerStore writes balanced journal entries. All transaction rows are
+// created through it so that every entry's legs sum to zero.
+type LedgerStore struct {
+	DB *gorm.DB
+}
+
+// ValidateEntry checks the double-entry invariants of e without touching the database.
+func ValidateEntry(e *models.JournalEntry) error {
+	if len(e.Lines) < 2 {
+		return ErrInvalidEntry
+	}
+	var sum int64
+	currency := e.Lines[0].Currency
+	for _, l := range e.Lines {
+		if l.AccountID == 0 {
+			return ErrInvalidEntry
+		}
+		if l.Currency != currency {
+			return ErrMixedCurrency
+		}
+		sum += l.AmountCents
+	}
+	if sum != 0 {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// ExternalAccount returns the household's external counterparty account,
+// creating it on first use.
+func (s *LedgerStore) ExternalAccount(householdID uint, currency string) (*models.Account, error) {
+	return externalAccount(s.DB, householdID, currency)
+}
+
+func externalAccount(tx *gorm.DB, householdID uint, currency string) (*models.Account, error) {
+	acc := models.Account{}
+	err := tx.Where(models.Account{HouseholdID: householdID, Type: models.AccountTypeExternal}).
+		Attrs(models.Account{Name: "External", Currency: currency}).
+		FirstOrCreate(&acc).Error
+	if err != nil {
+		return nil, fmt.Errorf("external account: %w", err)
+	}
+	return &acc, nil
+}
+
+// Post validates e and inserts the entry and its legs atomically. Legs
+// inherit the entry's user, memo and date when they do not set their own.
+func (s *LedgerStore) Post(e *models.JournalEntry) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		return postEntry(tx, e)
+	})
+}
+
+func postEntry(tx *gorm.DB, e *models.JournalEntry) error {
+	for i := range e.Lines {
+		l := &e.Lines[i]
+		if l.UserID == nil {
+			l.UserID = e.UserID
+		}
+		if l.Memo == "" {
+			l.Memo = e.Memo
+		}
+		if l.OccurredAt.IsZero() {
+			l.OccurredAt = e.OccurredAt
+		}
+	}
+	if err := ValidateEntry(e); err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(e.Lines))
+	seen := map[uint]struct{}{}
+	for _, l := range e.Lines {
+		if _, ok := seen[l.AccountID]; !ok {
+			seen[l.AccountID] = struct{}{}
+			ids = append(ids, l.AccountID)
+		}
+	}
+	var owned int64
+	if err := tx.Model(&models.Account{}).Where("id IN ? AND household_id = ?", ids, e.HouseholdID).Count(&owned).Error; err != nil {
+		return err
+	}
+	if int(owned) != len(ids) {
+		return ErrForeignAccount
+	}
+	var closed int64
+	if err := tx.Model(&models.Account{}).Where("id IN ? AND closed_at IS NOT NULL", ids).Count(&closed).Error; err != nil {
+		return err
+	}
+	if closed > 0 {
+		return ErrAccountClosed
+	}
+	if e.Kind == "" {
+		e.Kind = models.EntryKindCompound
+	}
+	return tx.Create(e).Error
+}
+
+// PostStandard records an income or expense on a single account, balanced
+// against the household's external account. leg is updated with its new ID.
+func (s *LedgerStore) PostStandard(householdID uint, leg *models.Transaction) (*models.JournalEntry, error) {
+	var entry *models.JournalEntry
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		ext, err := externalAccount(tx, householdID, leg.Currency)
+		if err != nil {
+			return err
+		}
+		entry = &models.JournalEntry{
+			HouseholdID: householdID,
+			UserID:      leg.UserID,
+			Kind:        models.EntryKindStandard,
+			Memo:        leg.Memo,
+			OccurredAt:  leg.OccurredAt,
+			Lines: []models.Transaction{
+				*leg,
+				{AccountID: ext.ID, AmountCents: -leg.AmountCents, Currency: leg.Currency},
+			},
+		}
+		return postEntry(tx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	*leg = entry.Lines[0]
+	return entry, nil
+}
+
+// PostTransfer moves money between leg.AccountID and counterAccountID; the
+// counter leg receives the opposite of leg.AmountCents. Both accounts must
+// hold leg.Currency: entries are single-currency, so transfers between
+// accounts in different currencies fail with ErrMixedCurrency.
+func (s *LedgerStore) PostTransfer(householdID, counterAccountID uint, leg *models.Transaction) (*models.JournalEntry, error) {
+	var entry *models.JournalEntry
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var accounts []models.Account
+		if err := tx.Where("id IN ?", []uint{leg.AccountID, counterAccountID}).Find(&accounts).Error; err != nil {
+			return err
+		}
+		for _, acc := range accounts {
+			if acc.Currency != leg.Currency {
+				return ErrMixedCurrency
+			}
+		}
+		entry = &models.JournalEntry{
+			HouseholdID: householdID,
+			UserID:      leg.UserID,
+			Kind:        models.EntryKindTransfer,
+			Memo:        leg.Memo,
+			OccurredAt:  leg.OccurredAt,
+			Lines: []models.Transaction{
+				*leg,
+				{AccountID: counterAccountID, AmountCents: -leg.AmountCents, Currency: leg.Currency},
+			},
+		}
+		return postEntry(tx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	*leg = entry.Lines[0]
+	return entry, nil
+}
+
+// GetEntry loads an entry with its legs, scoped to a household.
+func (s *LedgerStore) GetEntry(householdID, entryID uint) (*models.JournalEntry, error) {
+	var e models.JournalEntry
+	if err := s.DB.Preload("Lines").Where("id = ? AND household_id = ?", entryID, householdID).First(&e).Error; err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
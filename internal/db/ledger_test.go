@@ -0,0 +1,105 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, _ := gdb.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := gdb.AutoMigrate(&models.Household{}, &models.Account{}, &models.Category{}, &models.JournalEntry{}, &models.Transaction{}, &models.DuplicateSuspect{}, &models.TransactionSplit{}, &models.TransactionAudit{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	return gdb
+}
+
+func TestPostStandardBalancesAgainstExternal(t *testing.T) {
+	gdb := newTestDB(t)
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&checking)
+	store := LedgerStore{DB: gdb}
+
+	leg := &models.Transaction{AccountID: checking.ID, AmountCents: -2500, Currency: "USD", OccurredAt: time.Now()}
+	entry, err := store.PostStandard(1, leg)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if leg.ID == 0 || leg.EntryID != entry.ID {
+		t.Fatalf("leg not persisted with entry id: %+v", leg)
+	}
+	var sum int64
+	gdb.Model(&models.Transaction{}).Where("entry_id = ?", entry.ID).Select("COALESCE(SUM(amount_cents),0)").Scan(&sum)
+	if sum != 0 {
+		t.Fatalf("entry legs sum to %d", sum)
+	}
+	ext, _ := store.ExternalAccount(1, "USD")
+	if _, err := store.PostStandard(1, &models.Transaction{AccountID: checking.ID, AmountCents: 100, Currency: "USD"}); err != nil {
+		t.Fatalf("second post: %v", err)
+	}
+	var externals int64
+	gdb.Model(&models.Account{}).Where("household_id = ? AND type = ?", 1, models.AccountTypeExternal).Count(&externals)
+	if externals != 1 || ext.ID == 0 {
+		t.Fatalf("expected a single external account, got %d", externals)
+	}
+}
+
+func TestPostRejectsInvalidEntries(t *testing.T) {
+	gdb := newTestDB(t)
+	a := models.Account{HouseholdID: 1, Name: "A", Currency: "USD"}
+	b := models.Account{HouseholdID: 1, Name: "B", Currency: "USD"}
+	other := models.Account{HouseholdID: 2, Name: "Other", Currency: "USD"}
+	gdb.Create(&a)
+	gdb.Create(&b)
+	gdb.Create(&other)
+	store := LedgerStore{DB: gdb}
+
+	cases := []struct {
+		name  string
+		lines []models.Transaction
+		want  error
+	}{
+		{"unbalanced", []models.Transaction{{AccountID: a.ID, AmountCents: 100, Currency: "USD"}, {AccountID: b.ID, AmountCents: -90, Currency: "USD"}}, ErrUnbalancedEntry},
+		{"single leg", []models.Transaction{{AccountID: a.ID, AmountCents: 0, Currency: "USD"}}, ErrInvalidEntry},
+		{"mixed currency", []models.Transaction{{AccountID: a.ID, AmountCents: 100, Currency: "USD"}, {AccountID: b.ID, AmountCents: -100, Currency: "EUR"}}, ErrMixedCurrency},
+		{"foreign account", []models.Transaction{{AccountID: a.ID, AmountCents: 100, Currency: "USD"}, {AccountID: other.ID, AmountCents: -100, Currency: "USD"}}, ErrForeignAccount},
+	}
+	for _, tc := range cases {
+		err := store.Post(&models.JournalEntry{HouseholdID: 1, Lines: tc.lines})
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+	var count int64
+	gdb.Model(&models.Transaction{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("rejected entries must not write legs, found %d", count)
+	}
+
+	if _, err := store.PostTransfer(1, b.ID, &models.Transaction{AccountID: a.ID, AmountCents: 5000, Currency: "USD"}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	var bBal int64
+	gdb.Model(&models.Transaction{}).Where("account_id = ?", b.ID).Select("COALESCE(SUM(amount_cents),0)").Scan(&bBal)
+	if bBal != -5000 {
+		t.Fatalf("expected counter leg -5000, got %d", bBal)
+	}
+
+	euros := models.Account{HouseholdID: 1, Name: "Euro savings", Type: "savings", Currency: "EUR"}
+	gdb.Create(&euros)
+	if _, err := store.PostTransfer(1, euros.ID, &models.Transaction{AccountID: a.ID, AmountCents: 5000, Currency: "USD"}); !errors.Is(err, ErrMixedCurrency) {
+		t.Fatalf("expected ErrMixedCurrency for a cross-currency transfer, got %v", err)
+	}
+}
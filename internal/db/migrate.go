@@ -34,7 +34,7 @@ func RunMigrations(gdb *gorm.DB) error {
 		if err != nil {
 			return fmt.Errorf("read file %s: %w", f.Name(), err)
 		}
-		migs = append(migs, Migration{Name: f.Name(), SQL: string(b)})
+		migs = append(migs, Migration{Name: f.Name(), SQL: upSection(string(b))})
 	}
 	sort.Slice(migs, func(i, j int) bool { return migs[i].Name < migs[j].Name })
 
@@ -67,4 +67,13 @@ func RunMigrations(gdb *gorm.DB) error {
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// upSection returns the part of a migration that should be applied. Files
+// using "-- +migrate Up" / "-- +migrate Down" markers only run their Up half.
+func upSection(sql string) string {
+	if i := strings.Index(sql, "-- +migrate Down"); i >= 0 {
+		return sql[:i]
+	}
+	return sql
+}
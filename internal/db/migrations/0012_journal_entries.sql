@@ -0,0 +1,44 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    kind TEXT NOT NULL DEFAULT 'standard',
+    memo TEXT,
+    occurred_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE transactions ADD COLUMN entry_id INTEGER REFERENCES journal_entries(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_household ON journal_entries(household_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_entry ON transactions(entry_id);
+
+-- Backfill: every legacy single-row transaction becomes its own entry (same id),
+-- balanced against a per-household external counterparty account.
+INSERT INTO journal_entries (id, household_id, user_id, kind, memo, occurred_at)
+SELECT t.id, a.household_id, t.user_id, 'standard', t.memo, t.occurred_at
+FROM transactions t JOIN accounts a ON a.id = t.account_id
+WHERE t.entry_id IS NULL;
+
+UPDATE transactions SET entry_id = id WHERE entry_id IS NULL;
+
+INSERT INTO accounts (household_id, name, type, currency, opening_balance_cents)
+SELECT a.household_id, 'External', 'external', MIN(a.currency), 0
+FROM transactions t JOIN accounts a ON a.id = t.account_id
+WHERE NOT EXISTS (SELECT 1 FROM accounts x WHERE x.household_id = a.household_id AND x.type = 'external')
+GROUP BY a.household_id;
+
+INSERT INTO transactions (entry_id, account_id, user_id, amount_cents, currency, memo, occurred_at)
+SELECT t.entry_id, ext.id, t.user_id, -t.amount_cents, t.currency, t.memo, t.occurred_at
+FROM transactions t
+JOIN accounts a ON a.id = t.account_id
+JOIN accounts ext ON ext.household_id = a.household_id AND ext.type = 'external'
+WHERE a.type <> 'external';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_transactions_entry;
+DROP INDEX IF EXISTS idx_journal_entries_household;
+DELETE FROM transactions WHERE account_id IN (SELECT id FROM accounts WHERE type = 'external');
+DELETE FROM accounts WHERE type = 'external';
+DROP TABLE IF EXISTS journal_entries;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE user_settings ADD COLUMN low_balance_threshold BIGINT NOT NULL DEFAULT 10000;
+
+-- +migrate Down
+ALTER TABLE user_settings DROP COLUMN low_balance_threshold;
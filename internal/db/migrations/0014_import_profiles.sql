@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    has_header BOOLEAN NOT NULL DEFAULT TRUE,
+    delimiter TEXT NOT NULL DEFAULT ',',
+    date_column TEXT NOT NULL,
+    date_format TEXT NOT NULL,
+    amount_column TEXT,
+    debit_column TEXT,
+    credit_column TEXT,
+    memo_column TEXT,
+    sign_convention TEXT NOT NULL DEFAULT 'positive_inflow',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_profiles_household ON import_profiles(household_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS import_profiles;
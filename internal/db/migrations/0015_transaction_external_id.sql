@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE transactions ADD COLUMN external_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_account_external
+    ON transactions(account_id, external_id) WHERE external_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_transactions_account_external;
+ALTER TABLE transactions DROP COLUMN external_id;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS duplicate_suspects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    duplicate_of_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    score REAL NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_duplicate_suspects_pair ON duplicate_suspects(transaction_id, duplicate_of_id);
+CREATE INDEX IF NOT EXISTS idx_duplicate_suspects_household_status ON duplicate_suspects(household_id, status);
+
+-- +migrate Down
+DROP TABLE IF EXISTS duplicate_suspects;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS transaction_audits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    transaction_id INTEGER NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    changes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- No foreign key on transaction_id: the trail must outlive deleted legs.
+CREATE INDEX IF NOT EXISTS idx_transaction_audits_transaction ON transaction_audits(transaction_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transaction_audits_household ON transaction_audits(household_id, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS transaction_audits;
@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE accounts ADD COLUMN closed_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_accounts_household_archived ON accounts(household_id, archived_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_accounts_household_archived;
+ALTER TABLE accounts DROP COLUMN closed_at;
@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS household_invites (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    declined_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_household_invites_household ON household_invites(household_id);
+CREATE INDEX IF NOT EXISTS idx_household_invites_email ON household_invites(email);
+CREATE INDEX IF NOT EXISTS idx_household_members_household_user ON household_members(household_id, user_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_household_members_household_user;
+DROP TABLE IF EXISTS household_invites;
@@ -0,0 +1,8 @@
+-- +migrate Up
+-- "member" predates the editor/viewer split and keeps full write access.
+UPDATE household_members SET role = 'editor' WHERE role = 'member';
+UPDATE household_invites SET role = 'editor' WHERE role = 'member';
+
+-- +migrate Down
+UPDATE household_members SET role = 'member' WHERE role IN ('editor', 'viewer');
+UPDATE household_invites SET role = 'member' WHERE role IN ('editor', 'viewer');
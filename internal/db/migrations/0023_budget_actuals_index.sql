@@ -0,0 +1,7 @@
+-- +migrate Up
+-- Covers the per-household month aggregation in BudgetStore.MonthActuals so
+-- it never has to visit the transactions table itself.
+CREATE INDEX IF NOT EXISTS idx_transactions_account_occurred_category ON transactions(account_id, occurred_at, category_id, amount_cents);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_transactions_account_occurred_category;
@@ -0,0 +1,20 @@
+-- +migrate Up
+ALTER TABLE budgets ADD COLUMN rollover BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS budget_moves (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    month TEXT NOT NULL,
+    from_category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    to_category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    amount_cents BIGINT NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    memo TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_moves_household_month ON budget_moves(household_id, month);
+
+-- +migrate Down
+DROP TABLE IF EXISTS budget_moves;
+ALTER TABLE budgets DROP COLUMN rollover;
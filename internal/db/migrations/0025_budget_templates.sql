@@ -0,0 +1,24 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS budget_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_templates_household ON budget_templates(household_id);
+
+CREATE TABLE IF NOT EXISTS budget_template_lines (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    template_id INTEGER NOT NULL REFERENCES budget_templates(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    planned_cents BIGINT NOT NULL DEFAULT 0,
+    rollover BOOLEAN NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budget_template_lines_category ON budget_template_lines(template_id, category_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS budget_template_lines;
+DROP TABLE IF EXISTS budget_templates;
@@ -0,0 +1,19 @@
+-- +migrate Up
+ALTER TABLE households ADD COLUMN budget_alert_thresholds TEXT;
+
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    month TEXT NOT NULL,
+    threshold INTEGER NOT NULL,
+    spent_cents BIGINT NOT NULL DEFAULT 0,
+    planned_cents BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budget_alerts_threshold ON budget_alerts(household_id, category_id, month, threshold);
+
+-- +migrate Down
+DROP TABLE IF EXISTS budget_alerts;
+ALTER TABLE households DROP COLUMN budget_alert_thresholds;
@@ -0,0 +1,25 @@
+-- +migrate Up
+ALTER TABLE goals ADD COLUMN household_id INTEGER REFERENCES households(id) ON DELETE CASCADE;
+ALTER TABLE goals ADD COLUMN account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL;
+ALTER TABLE goals ADD COLUMN category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+ALTER TABLE goals ADD COLUMN milestones TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_goals_household ON goals(household_id);
+
+CREATE TABLE IF NOT EXISTS goal_milestones (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    goal_id INTEGER NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    percent INTEGER NOT NULL,
+    current_cents BIGINT NOT NULL DEFAULT 0,
+    reached_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_goal_milestones_percent ON goal_milestones(goal_id, percent);
+
+-- +migrate Down
+DROP TABLE IF EXISTS goal_milestones;
+DROP INDEX IF EXISTS idx_goals_household;
+ALTER TABLE goals DROP COLUMN milestones;
+ALTER TABLE goals DROP COLUMN category_id;
+ALTER TABLE goals DROP COLUMN account_id;
+ALTER TABLE goals DROP COLUMN household_id;
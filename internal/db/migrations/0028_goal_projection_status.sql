@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE goals ADD COLUMN projection_status TEXT;
+
+-- +migrate Down
+ALTER TABLE goals DROP COLUMN projection_status;
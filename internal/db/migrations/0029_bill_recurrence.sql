@@ -0,0 +1,45 @@
+-- +migrate Up
+ALTER TABLE bills ADD COLUMN household_id INTEGER REFERENCES households(id) ON DELETE CASCADE;
+ALTER TABLE bills ADD COLUMN account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL;
+ALTER TABLE bills ADD COLUMN category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+ALTER TABLE bills ADD COLUMN rrule TEXT;
+ALTER TABLE bills ADD COLUMN starts_on TIMESTAMP;
+ALTER TABLE bills ADD COLUMN closed_at TIMESTAMP;
+
+-- Existing bills belong to their creator's household: the one they own,
+-- else the one they joined first.
+UPDATE bills SET household_id = (
+    SELECT hm.household_id FROM household_members hm
+    WHERE hm.user_id = bills.user_id
+    ORDER BY hm.role = 'owner' DESC, hm.id ASC LIMIT 1
+) WHERE household_id IS NULL;
+
+-- Existing recurring bills were monthly on their due day, which was never
+-- validated.
+UPDATE bills SET rrule = 'FREQ=MONTHLY;BYMONTHDAY=' || MIN(MAX(due_day, 1), 31), starts_on = next_due WHERE recurring AND rrule IS NULL;
+UPDATE bills SET starts_on = next_due WHERE starts_on IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bills_household_next_due ON bills(household_id, next_due);
+
+CREATE TABLE IF NOT EXISTS bill_payments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bill_id INTEGER NOT NULL REFERENCES bills(id) ON DELETE CASCADE,
+    due_date TIMESTAMP NOT NULL,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    user_id INTEGER,
+    amount_cents BIGINT NOT NULL DEFAULT 0,
+    paid_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bill_payments_due ON bill_payments(bill_id, due_date);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bill_payments_transaction_id ON bill_payments(transaction_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS bill_payments;
+DROP INDEX IF EXISTS idx_bills_household_next_due;
+ALTER TABLE bills DROP COLUMN closed_at;
+ALTER TABLE bills DROP COLUMN starts_on;
+ALTER TABLE bills DROP COLUMN rrule;
+ALTER TABLE bills DROP COLUMN category_id;
+ALTER TABLE bills DROP COLUMN account_id;
+ALTER TABLE bills DROP COLUMN household_id;
@@ -0,0 +1,25 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS subscription_candidates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    payee TEXT NOT NULL,
+    memo TEXT,
+    account_id INTEGER REFERENCES accounts(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+    interval TEXT NOT NULL,
+    amount_cents BIGINT NOT NULL DEFAULT 0,
+    previous_amount_cents BIGINT NOT NULL DEFAULT 0,
+    occurrences INTEGER NOT NULL DEFAULT 0,
+    last_transaction_id INTEGER,
+    last_charged_at TIMESTAMP,
+    next_expected TIMESTAMP,
+    status TEXT NOT NULL DEFAULT 'pending',
+    bill_id INTEGER REFERENCES bills(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscription_candidates_payee ON subscription_candidates(household_id, payee);
+
+-- +migrate Down
+DROP TABLE IF EXISTS subscription_candidates;
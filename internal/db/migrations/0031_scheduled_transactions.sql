@@ -0,0 +1,40 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS scheduled_transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    counter_account_id INTEGER REFERENCES accounts(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+    amount_cents BIGINT NOT NULL,
+    currency TEXT NOT NULL,
+    memo TEXT,
+    rrule TEXT NOT NULL,
+    starts_on TIMESTAMP NOT NULL,
+    ends_on TIMESTAMP,
+    next_due TIMESTAMP NOT NULL,
+    next_date TIMESTAMP,
+    next_amount_cents BIGINT,
+    next_memo TEXT,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transactions_household_id ON scheduled_transactions(household_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transactions_next_due ON scheduled_transactions(next_due);
+
+CREATE TABLE IF NOT EXISTS scheduled_occurrences (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    scheduled_transaction_id INTEGER NOT NULL REFERENCES scheduled_transactions(id) ON DELETE CASCADE,
+    due_date TIMESTAMP NOT NULL,
+    entry_id INTEGER REFERENCES journal_entries(id) ON DELETE SET NULL,
+    skipped BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scheduled_occurrences_due ON scheduled_occurrences(scheduled_transaction_id, due_date);
+
+-- +migrate Down
+DROP TABLE IF EXISTS scheduled_occurrences;
+DROP TABLE IF EXISTS scheduled_transactions;
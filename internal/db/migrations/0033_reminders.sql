@@ -0,0 +1,21 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS bill_reminders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bill_id INTEGER NOT NULL REFERENCES bills(id) ON DELETE CASCADE,
+    due_date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bill_reminders_due ON bill_reminders(bill_id, due_date);
+
+CREATE TABLE IF NOT EXISTS uncategorized_reminders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_uncategorized_reminders_transaction_id ON uncategorized_reminders(transaction_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS uncategorized_reminders;
+DROP TABLE IF EXISTS bill_reminders;
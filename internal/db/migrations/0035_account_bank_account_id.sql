@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE accounts ADD COLUMN bank_account_id TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE accounts DROP COLUMN bank_account_id;
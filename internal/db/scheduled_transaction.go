@@ -0,0 +1,309 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/recurrence"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrScheduleEnded      = errors.New("scheduled transaction has ended")
+	ErrScheduleEndsBefore = errors.New("ends_on must not be before starts_on")
+	ErrOccurrenceHandled  = errors.New("this occurrence was already posted or skipped")
+)
+
+// ScheduledPreview is one upcoming occurrence of a scheduled transaction.
+// Date differs from DueDate when the occurrence was moved.
+type ScheduledPreview struct {
+	DueDate     time.Time `json:"due_date"`
+	Date        time.Time `json:"date"`
+	AmountCents int64     `json:"amount_cents"`
+	Memo        string    `json:"memo"`
+	Overridden  bool      `json:"overridden"`
+}
+
+type ScheduledTransactionStore struct {
+	DB *gorm.DB
+}
+
+// ListByHousehold returns the household's scheduled transactions by next due
+// date. Ended ones are only included when asked for.
+func (s *ScheduledTransactionStore) ListByHousehold(householdID uint, includeClosed bool) ([]models.ScheduledTransaction, error) {
+	q := s.DB.Where("household_id = ?", householdID)
+	if !includeClosed {
+		q = q.Where("closed_at IS NULL")
+	}
+	var out []models.ScheduledTransaction
+	err := q.Order("next_due asc, id asc").Find(&out).Error
+	return out, err
+}
+
+func (s *ScheduledTransactionStore) Get(householdID, id uint) (*models.ScheduledTransaction, error) {
+	var st models.ScheduledTransaction
+	if err := s.DB.Where("id = ? AND household_id = ?", id, householdID).First(&st).Error; err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// ListDue returns every open scheduled transaction whose next occurrence
+// (or its moved date) is on or before now, across all households.
+func (s *ScheduledTransactionStore) ListDue(now time.Time) ([]models.ScheduledTransaction, error) {
+	var out []models.ScheduledTransaction
+	err := s.DB.Where("closed_at IS NULL AND COALESCE(next_date, next_due) <= ?", now).
+		Order("id asc").Find(&out).Error
+	return out, err
+}
+
+// SetScheduledRule sets st's rule, start and optional end date, storing the
+// rule in canonical form. NextDue is re-derived with Reschedule.
+func SetScheduledRule(st *models.ScheduledTransaction, rrule string, startsOn time.Time, endsOn *time.Time) error {
+	rule, err := recurrence.Parse(rrule)
+	if err != nil {
+		return err
+	}
+	if endsOn != nil && endsOn.Before(startsOn) {
+		return ErrScheduleEndsBefore
+	}
+	st.RRule = rule.String()
+	st.StartsOn = startsOn
+	st.EndsOn = endsOn
+	return nil
+}
+
+// ScheduledBackfillDays is how far back a scheduled transaction may post
+// occurrences that were already due when it was created or rescheduled.
+const ScheduledBackfillDays = 31
+
+// Reschedule points NextDue at the first occurrence of the rule after the
+// last one already posted or skipped and on or after notBefore, clearing any
+// override, and closes st if there is none before its end date. Occurrences
+// before notBefore are passed over without being posted or recorded; pass the
+// zero time to keep them. Call it after changing the rule, start or end date.
+func (s *ScheduledTransactionStore) Reschedule(st *models.ScheduledTransaction, notBefore time.Time) error {
+	rule, err := recurrence.Parse(st.RRule)
+	if err != nil {
+		return err
+	}
+	after := st.StartsOn.Add(-time.Nanosecond)
+	if st.ID != 0 {
+		var last models.ScheduledOccurrence
+		err := s.DB.Where("scheduled_transaction_id = ?", st.ID).Order("due_date desc").First(&last).Error
+		if err == nil && !last.DueDate.Before(st.StartsOn) {
+			after = last.DueDate
+		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	if floor := notBefore.Add(-time.Nanosecond); floor.After(after) {
+		after = floor
+	}
+	st.NextDate, st.NextAmountCents, st.NextMemo = nil, nil, nil
+	next, ok := rule.Next(st.StartsOn, after)
+	if !ok || (st.EndsOn != nil && next.After(*st.EndsOn)) {
+		now := time.Now()
+		st.ClosedAt = &now
+		return nil
+	}
+	st.NextDue, st.ClosedAt = next, nil
+	return nil
+}
+
+func (s *ScheduledTransactionStore) Create(st *models.ScheduledTransaction) error {
+	return s.DB.Create(st).Error
+}
+
+// Update writes only the given columns of st, and only while the template
+// still has the next occurrence and open state it was read with (read), so an
+// edit racing the scheduler cannot move next_due back onto an occurrence that
+// was just posted or skipped. ErrOccurrenceHandled is returned in that case.
+func (s *ScheduledTransactionStore) Update(st *models.ScheduledTransaction, read models.ScheduledTransaction, columns ...string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	q := s.DB.Model(st).Where("next_due = ?", read.NextDue)
+	if read.ClosedAt == nil {
+		q = q.Where("closed_at IS NULL")
+	}
+	res := q.Select(columns).Updates(st)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrOccurrenceHandled
+	}
+	return nil
+}
+
+// Delete removes the template and its occurrence history; transactions it
+// already posted are kept.
+func (s *ScheduledTransactionStore) Delete(st *models.ScheduledTransaction) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scheduled_transaction_id = ?", st.ID).Delete(&models.ScheduledOccurrence{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(st).Error
+	})
+}
+
+// Preview returns the next n occurrences, the first with any override
+// applied.
+func Preview(st *models.ScheduledTransaction, n int) []ScheduledPreview {
+	out := []ScheduledPreview{}
+	if st.ClosedAt != nil || n <= 0 {
+		return out
+	}
+	first := ScheduledPreview{DueDate: st.NextDue, Date: st.NextDue, AmountCents: st.AmountCents, Memo: st.Memo}
+	if st.NextDate != nil {
+		first.Date, first.Overridden = *st.NextDate, true
+	}
+	if st.NextAmountCents != nil {
+		first.AmountCents, first.Overridden = *st.NextAmountCents, true
+	}
+	if st.NextMemo != nil {
+		first.Memo, first.Overridden = *st.NextMemo, true
+	}
+	out = append(out, first)
+	rule, err := recurrence.Parse(st.RRule)
+	if err != nil {
+		return out
+	}
+	for _, t := range rule.Occurrences(st.StartsOn, st.NextDue, n-1) {
+		if st.EndsOn != nil && t.After(*st.EndsOn) {
+			break
+		}
+		out = append(out, ScheduledPreview{DueDate: t, Date: t, AmountCents: st.AmountCents, Memo: st.Memo})
+	}
+	return out
+}
+
+// Skip records the next occurrence as skipped without posting it and
+// advances to the one after.
+func (s *ScheduledTransactionStore) Skip(st *models.ScheduledTransaction) error {
+	if st.ClosedAt != nil {
+		return ErrScheduleEnded
+	}
+	saved := *st
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		dueDate := st.NextDue
+		if err := advanceScheduled(tx, st); err != nil {
+			return err
+		}
+		return tx.Create(&models.ScheduledOccurrence{ScheduledTransactionID: st.ID, DueDate: dueDate, Skipped: true}).Error
+	})
+	if err != nil {
+		*st = saved
+	}
+	return err
+}
+
+// Materialize posts every occurrence of st dated on or before now, applying
+// the override to the first, and returns the entries it created. The
+// template is closed when its account can no longer be posted to, and
+// ErrAccountClosed is returned.
+func (s *ScheduledTransactionStore) Materialize(st *models.ScheduledTransaction, now time.Time) ([]models.JournalEntry, error) {
+	var posted []models.JournalEntry
+	for st.ClosedAt == nil {
+		p := Preview(st, 1)[0]
+		if p.Date.After(now) {
+			break
+		}
+		entry, err := s.post(st, p)
+		if errors.Is(err, ErrAccountClosed) || errors.Is(err, ErrForeignAccount) {
+			closed := time.Now()
+			if err := s.DB.Model(st).UpdateColumn("closed_at", closed).Error; err != nil {
+				return posted, err
+			}
+			st.ClosedAt = &closed
+			return posted, ErrAccountClosed
+		}
+		if err != nil {
+			return posted, err
+		}
+		posted = append(posted, *entry)
+	}
+	return posted, nil
+}
+
+// post writes one occurrence and audits its creation as the template
+// owner's. The template's advance is conditional on NextDue being unchanged,
+// so concurrent runs cannot post it twice.
+func (s *ScheduledTransactionStore) post(st *models.ScheduledTransaction, p ScheduledPreview) (*models.JournalEntry, error) {
+	userID := st.UserID
+	leg := &models.Transaction{
+		AccountID:   st.AccountID,
+		UserID:      &userID,
+		AmountCents: p.AmountCents,
+		Currency:    st.Currency,
+		CategoryID:  st.CategoryID,
+		Memo:        p.Memo,
+		OccurredAt:  p.Date,
+	}
+	saved := *st
+	var entry *models.JournalEntry
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		dueDate := st.NextDue
+		if err := advanceScheduled(tx, st); err != nil {
+			return err
+		}
+		ledger := LedgerStore{DB: tx}
+		var err error
+		if st.CounterAccountID != nil {
+			leg.CategoryID = nil
+			entry, err = ledger.PostTransfer(st.HouseholdID, *st.CounterAccountID, leg)
+		} else {
+			entry, err = ledger.PostStandard(st.HouseholdID, leg)
+		}
+		if err != nil {
+			return err
+		}
+		audit := TransactionStore{DB: tx}
+		if err := audit.RecordAudit(st.HouseholdID, userID, leg.ID, models.AuditCreate, map[string][2]any{"transaction": {nil, leg}}); err != nil {
+			return err
+		}
+		return tx.Create(&models.ScheduledOccurrence{ScheduledTransactionID: st.ID, DueDate: dueDate, EntryID: &entry.ID}).Error
+	})
+	if err != nil {
+		*st = saved
+		return nil, err
+	}
+	return entry, nil
+}
+
+// advanceScheduled moves st past its next occurrence, clearing the override,
+// or closes it after the last one. Callers record the occurrence that was
+// due before calling it.
+func advanceScheduled(tx *gorm.DB, st *models.ScheduledTransaction) error {
+	updates := map[string]any{"next_date": nil, "next_amount_cents": nil, "next_memo": nil}
+	next, hasNext := time.Time{}, false
+	if rule, err := recurrence.Parse(st.RRule); err == nil {
+		next, hasNext = rule.Next(st.StartsOn, st.NextDue)
+	}
+	if hasNext && st.EndsOn != nil && next.After(*st.EndsOn) {
+		hasNext = false
+	}
+	if hasNext {
+		updates["next_due"] = next
+	} else {
+		updates["closed_at"] = time.Now()
+	}
+	res := tx.Model(&models.ScheduledTransaction{}).Where("id = ? AND next_due = ? AND closed_at IS NULL", st.ID, st.NextDue).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrOccurrenceHandled
+	}
+	st.NextDate, st.NextAmountCents, st.NextMemo = nil, nil, nil
+	if hasNext {
+		st.NextDue = next
+	} else {
+		closed := updates["closed_at"].(time.Time)
+		st.ClosedAt = &closed
+	}
+	return nil
+}
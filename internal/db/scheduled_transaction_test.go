@@ -0,0 +1,129 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestScheduledTransactionMaterialize(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.ScheduledTransaction{}, &models.ScheduledOccurrence{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&checking)
+	store := ScheduledTransactionStore{DB: gdb}
+	date := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+
+	ends := date(time.April, 30)
+	rent := &models.ScheduledTransaction{HouseholdID: 1, UserID: 7, AccountID: checking.ID, AmountCents: -120000, Currency: "USD", Memo: "Rent"}
+	if err := SetScheduledRule(rent, "FREQ=MONTHLY;BYMONTHDAY=1", date(time.January, 1), &ends); err != nil {
+		t.Fatalf("rule: %v", err)
+	}
+	if err := store.Reschedule(rent, time.Time{}); err != nil || !rent.NextDue.Equal(date(time.January, 1)) {
+		t.Fatalf("unexpected next due %v (%v)", rent.NextDue, err)
+	}
+	store.Create(rent)
+
+	// Edit only the next occurrence.
+	read := *rent
+	higher, moved := int64(-130000), date(time.January, 3)
+	rent.NextAmountCents, rent.NextDate = &higher, &moved
+	if err := store.Update(rent, read, "next_amount_cents", "next_date"); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	posted, err := store.Materialize(rent, date(time.February, 15))
+	if err != nil || len(posted) != 2 {
+		t.Fatalf("expected two entries, got %d (%v)", len(posted), err)
+	}
+	var legs []models.Transaction
+	gdb.Where("account_id = ?", checking.ID).Order("occurred_at asc").Find(&legs)
+	if len(legs) != 2 || legs[0].AmountCents != -130000 || !legs[0].OccurredAt.Equal(moved) || legs[1].AmountCents != -120000 {
+		t.Fatalf("unexpected legs %+v", legs)
+	}
+	audit := TransactionStore{DB: gdb}
+	if trail, _ := audit.ListAudit(legs[0].ID); len(trail) != 1 || trail[0].Action != models.AuditCreate || trail[0].UserID != 7 {
+		t.Fatalf("expected the posting audited as the owner's, got %+v", trail)
+	}
+	if !rent.NextDue.Equal(date(time.March, 1)) || rent.NextAmountCents != nil {
+		t.Fatalf("expected March with no override, got %+v", rent)
+	}
+
+	// A stale copy cannot post the same occurrence again.
+	stale, _ := store.Get(1, rent.ID)
+	stale.NextDue = date(time.February, 1)
+	if _, err := store.Materialize(stale, date(time.February, 15)); !errors.Is(err, ErrOccurrenceHandled) {
+		t.Fatalf("expected ErrOccurrenceHandled, got %v", err)
+	}
+
+	// Nor can an edit made from it move next_due back.
+	edited := *stale
+	edited.Memo = "Rent (old)"
+	if err := store.Update(&edited, *stale, "memo", "next_due"); !errors.Is(err, ErrOccurrenceHandled) {
+		t.Fatalf("expected ErrOccurrenceHandled, got %v", err)
+	}
+
+	if err := store.Skip(rent); err != nil || !rent.NextDue.Equal(date(time.April, 1)) {
+		t.Fatalf("skip: %v, next due %v", err, rent.NextDue)
+	}
+	if up := Preview(rent, 5); len(up) != 1 || !up[0].Date.Equal(date(time.April, 1)) {
+		t.Fatalf("expected only April before the end date, got %+v", up)
+	}
+	if posted, err = store.Materialize(rent, date(time.May, 1)); err != nil || len(posted) != 1 || rent.ClosedAt == nil {
+		t.Fatalf("expected April posted and closed, got %d (%v) %+v", len(posted), err, rent)
+	}
+	var occurrences []models.ScheduledOccurrence
+	gdb.Where("scheduled_transaction_id = ?", rent.ID).Order("due_date asc").Find(&occurrences)
+	if len(occurrences) != 4 || !occurrences[2].Skipped || occurrences[2].EntryID != nil || occurrences[3].EntryID == nil {
+		t.Fatalf("unexpected occurrences %+v", occurrences)
+	}
+
+	// Changing the start re-derives the next occurrence after the last
+	// handled one.
+	rent.StartsOn, rent.EndsOn = date(time.January, 1), nil
+	if err := store.Reschedule(rent, time.Time{}); err != nil || rent.ClosedAt != nil || !rent.NextDue.Equal(date(time.May, 1)) {
+		t.Fatalf("reschedule: %v, %+v", err, rent)
+	}
+	// Occurrences before notBefore are passed over rather than backfilled.
+	if err := store.Reschedule(rent, date(time.July, 15)); err != nil || !rent.NextDue.Equal(date(time.August, 1)) {
+		t.Fatalf("reschedule from July 15: %v, %+v", err, rent)
+	}
+}
+
+func TestScheduledTransferStopsOnClosedAccount(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.ScheduledTransaction{}, &models.ScheduledOccurrence{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	savings := models.Account{HouseholdID: 1, Name: "Savings", Type: "savings", Currency: "USD"}
+	gdb.Create(&checking)
+	gdb.Create(&savings)
+	store := ScheduledTransactionStore{DB: gdb}
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	st := &models.ScheduledTransaction{HouseholdID: 1, UserID: 7, AccountID: checking.ID, CounterAccountID: &savings.ID, AmountCents: -5000, Currency: "USD", Memo: "Save"}
+	SetScheduledRule(st, "FREQ=WEEKLY", start, nil)
+	store.Reschedule(st, time.Time{})
+	store.Create(st)
+	if posted, err := store.Materialize(st, start.AddDate(0, 0, 8)); err != nil || len(posted) != 2 {
+		t.Fatalf("expected two transfers, got %d (%v)", len(posted), err)
+	}
+	accounts := AccountStore{DB: gdb}
+	if bal, _ := accounts.Balance(&savings); bal != 10000 {
+		t.Fatalf("expected savings balance 10000, got %d", bal)
+	}
+
+	now := time.Now()
+	gdb.Model(&savings).Update("closed_at", now)
+	if _, err := store.Materialize(st, start.AddDate(0, 0, 14)); !errors.Is(err, ErrAccountClosed) || st.ClosedAt == nil {
+		t.Fatalf("expected the template to end, got %v", err)
+	}
+	if due, _ := store.ListDue(start.AddDate(0, 1, 0)); len(due) != 0 {
+		t.Fatalf("ended template still due: %+v", due)
+	}
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	DefaultTransactionPageSize = 100
+	MaxTransactionPageSize     = 500
+)
+
+// EnsureTransactionSearch creates the FTS5 memo index and its sync triggers.
+// It reports false when the SQLite build lacks FTS5 (build with
+// -tags sqlite_fts5); memo search then falls back to LIKE.
+func EnsureTransactionSearch(gdb *gorm.DB) (bool, error) {
+	var exists int64
+	gdb.Raw(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'transactions_fts'`).Scan(&exists)
+	if exists > 0 {
+		return true, nil
+	}
+	err := gdb.Exec(`CREATE VIRTUAL TABLE transactions_fts USING fts5(memo, content='transactions', content_rowid='id')`).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			return false, nil
+		}
+		return false, err
+	}
+	stmts := []string{
+		`CREATE TRIGGER IF NOT EXISTS transactions_fts_ai AFTER INSERT ON transactions BEGIN
+			INSERT INTO transactions_fts(rowid, memo) VALUES (new.id, new.memo);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS transactions_fts_ad AFTER DELETE ON transactions BEGIN
+			INSERT INTO transactions_fts(transactions_fts, rowid, memo) VALUES ('delete', old.id, old.memo);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS transactions_fts_au AFTER UPDATE OF memo ON transactions BEGIN
+			INSERT INTO transactions_fts(transactions_fts, rowid, memo) VALUES ('delete', old.id, old.memo);
+			INSERT INTO transactions_fts(rowid, memo) VALUES (new.id, new.memo);
+		END`,
+		`INSERT INTO transactions_fts(transactions_fts) VALUES ('rebuild')`,
+	}
+	for _, stmt := range stmts {
+		if err := gdb.Exec(stmt).Error; err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// TransactionFilter narrows a transaction listing. Either AccountID or
+// HouseholdID scopes the search; external counter legs are never returned.
+type TransactionFilter struct {
+	AccountID     uint
+	HouseholdID   uint
+	CategoryID    *uint
+	MinAmount     *int64
+	MaxAmount     *int64
+	Uncategorized bool
+	UserID        *uint
+	Query         string
+	Start         *time.Time
+	End           *time.Time
+	Cursor        string
+	Limit         int
+}
+
+// Search returns one page of legs, newest first, and the cursor for the
+// next page ("" on the last page). Category filters match split lines too.
+func (s *TransactionStore) Search(f TransactionFilter) ([]models.Transaction, string, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultTransactionPageSize
+	}
+	if limit > MaxTransactionPageSize {
+		limit = MaxTransactionPageSize
+	}
+
+	q := s.DB.Model(&models.Transaction{}).
+		Joins("JOIN accounts a ON a.id = transactions.account_id").
+		Where("a.type <> ?", models.AccountTypeExternal)
+	if f.AccountID != 0 {
+		q = q.Where("transactions.account_id = ?", f.AccountID)
+	}
+	if f.HouseholdID != 0 {
+		q = q.Where("a.household_id = ?", f.HouseholdID)
+	}
+	if f.CategoryID != nil {
+		q = q.Where("(transactions.category_id = ? OR EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = transactions.id AND s.category_id = ?))", *f.CategoryID, *f.CategoryID)
+	}
+	if f.Uncategorized {
+		q = q.Where("transactions.category_id IS NULL AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = transactions.id)")
+	}
+	if f.MinAmount != nil {
+		q = q.Where("transactions.amount_cents >= ?", *f.MinAmount)
+	}
+	if f.MaxAmount != nil {
+		q = q.Where("transactions.amount_cents <= ?", *f.MaxAmount)
+	}
+	if f.UserID != nil {
+		q = q.Where("transactions.user_id = ?", *f.UserID)
+	}
+	if f.Start != nil {
+		q = q.Where("transactions.occurred_at >= ?", *f.Start)
+	}
+	if f.End != nil {
+		q = q.Where("transactions.occurred_at <= ?", *f.End)
+	}
+	if terms := searchTerms(f.Query); len(terms) > 0 {
+		q = s.matchMemo(q, terms)
+	}
+	if f.Cursor != "" {
+		at, id, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		q = q.Where("(transactions.occurred_at < ? OR (transactions.occurred_at = ? AND transactions.id < ?))", at, at, id)
+	}
+
+	var txs []models.Transaction
+	err := q.Select("transactions.*").
+		Preload("Splits").
+		Order("transactions.occurred_at desc, transactions.id desc").
+		Limit(limit + 1).
+		Find(&txs).Error
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(txs) > limit {
+		txs = txs[:limit]
+		last := txs[len(txs)-1]
+		next = encodeCursor(last.OccurredAt, last.ID)
+	}
+	return txs, next, nil
+}
+
+// matchMemo requires every term in the memo, using the FTS5 index when it
+// exists and a case-insensitive LIKE otherwise.
+func (s *TransactionStore) matchMemo(q *gorm.DB, terms []string) *gorm.DB {
+	var fts int64
+	s.DB.Raw(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'transactions_fts'`).Scan(&fts)
+	if fts > 0 {
+		parts := make([]string, len(terms))
+		for i, t := range terms {
+			parts[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"*`
+		}
+		return q.Where("transactions.id IN (SELECT rowid FROM transactions_fts WHERE transactions_fts MATCH ?)", strings.Join(parts, " "))
+	}
+	for _, t := range terms {
+		q = q.Where("LOWER(transactions.memo) LIKE ?", "%"+strings.ToLower(t)+"%")
+	}
+	return q
+}
+
+func searchTerms(q string) []string {
+	return strings.Fields(strings.TrimSpace(q))
+}
+
+// Cursors encode the last row's occurred_at (with its original offset, so
+// comparisons match the stored text) and id.
+func encodeCursor(at time.Time, id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(at.Format(time.RFC3339Nano) + "|" + strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeCursor(c string) (time.Time, uint, error) {
+	b, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	ts, idStr, ok := strings.Cut(string(b), "|")
+	if !ok {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	at, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return at, uint(id), nil
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestSearchPaginatesAndFilters(t *testing.T) {
+	gdb := newTestDB(t)
+	fts, err := EnsureTransactionSearch(gdb)
+	if err != nil {
+		t.Fatalf("search index: %v", err)
+	}
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	card := models.Account{HouseholdID: 1, Name: "Card", Type: "credit", Currency: "USD"}
+	gdb.Create(&checking)
+	gdb.Create(&card)
+	ledger := LedgerStore{DB: gdb}
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	food := uint(3)
+	for i := 0; i < 5; i++ {
+		ledger.PostStandard(1, &models.Transaction{AccountID: checking.ID, AmountCents: int64(-100 * (i + 1)), Currency: "USD", Memo: "Corner grocery", CategoryID: &food, OccurredAt: base.AddDate(0, 0, i)})
+	}
+	// Same timestamp as the newest checking leg to exercise the id tie-break.
+	ledger.PostStandard(1, &models.Transaction{AccountID: card.ID, AmountCents: -900, Currency: "USD", Memo: "Hardware store", OccurredAt: base.AddDate(0, 0, 4)})
+
+	store := TransactionStore{DB: gdb}
+	var seen []uint
+	cursor := ""
+	for page := 0; page < 10; page++ {
+		txs, next, err := store.Search(TransactionFilter{HouseholdID: 1, Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		for _, tx := range txs {
+			seen = append(seen, tx.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 6 {
+		t.Fatalf("expected 6 legs across pages, got %d (%v)", len(seen), seen)
+	}
+
+	min := int64(-300)
+	txs, _, _ := store.Search(TransactionFilter{AccountID: checking.ID, MinAmount: &min, CategoryID: &food})
+	if len(txs) != 3 {
+		t.Errorf("expected 3 legs in amount range, got %d", len(txs))
+	}
+	txs, _, _ = store.Search(TransactionFilter{HouseholdID: 1, Uncategorized: true})
+	if len(txs) != 1 || txs[0].AccountID != card.ID {
+		t.Errorf("expected the uncategorized card leg, got %+v", txs)
+	}
+	txs, _, _ = store.Search(TransactionFilter{HouseholdID: 1, Query: "hardw"})
+	if len(txs) != 1 {
+		t.Errorf("expected memo search (fts=%v) to find 1 leg, got %d", fts, len(txs))
+	}
+	if _, _, err := store.Search(TransactionFilter{HouseholdID: 1, Cursor: "not-a-cursor"}); err != ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
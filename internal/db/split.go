@@ -0,0 +1,70 @@
+package db
+
+import (
+	"errors"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSplitMismatch = errors.New("split amounts must sum to the transaction amount")
+	ErrInvalidSplit  = errors.New("each split needs a category and a non-zero amount")
+	ErrTransferSplit = errors.New("transfers cannot be split")
+)
+
+// ValidateSplits checks splits against the leg they divide.
+func ValidateSplits(leg *models.Transaction, splits []models.TransactionSplit) error {
+	var sum int64
+	for _, s := range splits {
+		if s.CategoryID == 0 || s.AmountCents == 0 {
+			return ErrInvalidSplit
+		}
+		sum += s.AmountCents
+	}
+	if len(splits) > 0 && sum != leg.AmountCents {
+		return ErrSplitMismatch
+	}
+	return nil
+}
+
+type SplitStore struct {
+	DB *gorm.DB
+}
+
+// Replace swaps the leg's splits for splits. A non-empty set clears the
+// leg's own category; an empty set removes the split and leaves the leg
+// uncategorized. leg.Splits is updated on success.
+func (s *SplitStore) Replace(leg *models.Transaction, splits []models.TransactionSplit) error {
+	if err := ValidateSplits(leg, splits); err != nil {
+		return err
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if leg.EntryID != 0 {
+			var kind string
+			tx.Model(&models.JournalEntry{}).Where("id = ?", leg.EntryID).Pluck("kind", &kind)
+			if kind == models.EntryKindTransfer {
+				return ErrTransferSplit
+			}
+		}
+		if err := tx.Where("transaction_id = ?", leg.ID).Delete(&models.TransactionSplit{}).Error; err != nil {
+			return err
+		}
+		for i := range splits {
+			splits[i].ID = 0
+			splits[i].TransactionID = leg.ID
+		}
+		if len(splits) > 0 {
+			if err := tx.Create(&splits).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Transaction{}).Where("id = ?", leg.ID).Update("category_id", nil).Error; err != nil {
+				return err
+			}
+			leg.CategoryID = nil
+		}
+		leg.Splits = splits
+		return nil
+	})
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestSplitsDriveBudgetActuals(t *testing.T) {
+	gdb := newTestDB(t)
+	acc := models.Account{HouseholdID: 1, Name: "Card", Type: "credit", Currency: "USD"}
+	gdb.Create(&acc)
+	ledger := LedgerStore{DB: gdb}
+	splits := SplitStore{DB: gdb}
+	budgets := BudgetStore{DB: gdb}
+	day := time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC)
+	groceries, household := uint(1), uint(2)
+
+	receipt := &models.Transaction{AccountID: acc.ID, AmountCents: -10000, Currency: "USD", CategoryID: &groceries, OccurredAt: day}
+	ledger.PostStandard(1, receipt)
+	ledger.PostStandard(1, &models.Transaction{AccountID: acc.ID, AmountCents: -500, Currency: "USD", CategoryID: &groceries, OccurredAt: day})
+
+	bad := []models.TransactionSplit{{CategoryID: groceries, AmountCents: -7000}, {CategoryID: household, AmountCents: -2000}}
+	if err := splits.Replace(receipt, bad); !errors.Is(err, ErrSplitMismatch) {
+		t.Fatalf("expected ErrSplitMismatch, got %v", err)
+	}
+	good := []models.TransactionSplit{{CategoryID: groceries, AmountCents: -7000}, {CategoryID: household, AmountCents: -3000}}
+	if err := splits.Replace(receipt, good); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if receipt.CategoryID != nil {
+		t.Fatal("split leg must drop its own category")
+	}
+
+	start, end := day.AddDate(0, 0, -9), day.AddDate(0, 1, 0)
+	actuals, err := budgets.MonthActuals(1, start, end)
+	if err != nil {
+		t.Fatalf("actuals: %v", err)
+	}
+	if got := actuals[groceries]; got != -7500 {
+		t.Errorf("groceries actual = %d, want -7500", got)
+	}
+	if got := actuals[household]; got != -3000 {
+		t.Errorf("household actual = %d, want -3000", got)
+	}
+}
@@ -0,0 +1,9 @@
+package db
+
+// Store bundles the stores used by background jobs.
+type Store struct {
+	InvestmentAlertStore *InvestmentAlertStore
+	UserStore            *UserStore
+	NotificationStore    *NotificationStore
+	AlertHistoryStore    *AlertHistoryStore
+}
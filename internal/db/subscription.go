@@ -0,0 +1,291 @@
+package db
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/recurrence"
+
+	"gorm.io/gorm"
+)
+
+var ErrSubscriptionResolved = errors.New("subscription candidate already resolved")
+
+// subscriptionInterval is a charge cadence the detector recognises. Gaps
+// between consecutive charges must fall within [MinDays, MaxDays].
+type subscriptionInterval struct {
+	Name    string
+	RRule   string
+	MinDays int
+	MaxDays int
+	// MinCharges is how many regular charges it takes to propose a candidate.
+	MinCharges int
+}
+
+var subscriptionIntervals = []subscriptionInterval{
+	{"weekly", "FREQ=WEEKLY", 6, 8, 3},
+	{"biweekly", "FREQ=WEEKLY;INTERVAL=2", 13, 16, 3},
+	{"monthly", "FREQ=MONTHLY", 27, 33, 3},
+	{"quarterly", "FREQ=MONTHLY;INTERVAL=3", 85, 95, 3},
+	{"yearly", "FREQ=YEARLY", 355, 375, 2},
+}
+
+// subscriptionLookbackDays is how far back detection scans; enough to see
+// two charges of a yearly subscription.
+const subscriptionLookbackDays = 400
+
+// subscriptionAmountTolerance is how far (as a fraction of the latest
+// charge) an earlier charge may differ and still count as the same
+// subscription.
+const subscriptionAmountTolerance = 0.25
+
+type SubscriptionStore struct {
+	DB *gorm.DB
+}
+
+// Detect scans the household's outflows for periodic charges: legs of
+// standard entries (not transfers between its own accounts) with the same
+// payee (the memo's words, ignoring case, numbers and punctuation), a
+// similar amount and a regular interval. Only the latest unbroken run of
+// charges counts, and a run is ignored once it has missed two charges. The
+// candidates returned are not saved.
+func (s *SubscriptionStore) Detect(householdID uint, now time.Time) ([]models.SubscriptionCandidate, error) {
+	var legs []models.Transaction
+	err := s.DB.Joins("JOIN accounts a ON a.id = transactions.account_id").
+		Joins("LEFT JOIN journal_entries je ON je.id = transactions.entry_id").
+		Where("a.household_id = ? AND a.type <> ?", householdID, models.AccountTypeExternal).
+		Where("je.id IS NULL OR je.kind = ?", models.EntryKindStandard).
+		Where("transactions.amount_cents < 0 AND transactions.occurred_at >= ?", now.AddDate(0, 0, -subscriptionLookbackDays)).
+		Order("transactions.occurred_at asc, transactions.id asc").
+		Find(&legs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byPayee := map[string][]models.Transaction{}
+	var payees []string
+	for _, leg := range legs {
+		key := payeeKey(leg.Memo)
+		if key == "" {
+			continue
+		}
+		if _, ok := byPayee[key]; !ok {
+			payees = append(payees, key)
+		}
+		byPayee[key] = append(byPayee[key], leg)
+	}
+
+	var out []models.SubscriptionCandidate
+	for _, payee := range payees {
+		if c, ok := detectSubscription(householdID, payee, byPayee[payee], now); ok {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// Sync saves the household's detected subscriptions, returning the
+// candidates seen for the first time and those whose latest charge costs
+// more than the one before. Dismissed candidates are kept up to date but
+// never reported.
+func (s *SubscriptionStore) Sync(householdID uint, now time.Time) (created, increased []models.SubscriptionCandidate, err error) {
+	detected, err := s.Detect(householdID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, d := range detected {
+		var existing models.SubscriptionCandidate
+		err := s.DB.Where("household_id = ? AND payee = ?", householdID, d.Payee).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			d.Status = models.SubscriptionPending
+			if err := s.DB.Create(&d).Error; err != nil {
+				return nil, nil, err
+			}
+			created = append(created, d)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing.LastTransactionID == d.LastTransactionID {
+			continue
+		}
+		raised := d.AmountCents > existing.AmountCents
+		if d.AmountCents != existing.AmountCents {
+			d.PreviousAmountCents = existing.AmountCents
+		}
+		d.ID, d.Status, d.BillID, d.CreatedAt = existing.ID, existing.Status, existing.BillID, existing.CreatedAt
+		if err := s.DB.Save(&d).Error; err != nil {
+			return nil, nil, err
+		}
+		if raised && d.Status != models.SubscriptionDismissed {
+			increased = append(increased, d)
+		}
+	}
+	return created, increased, nil
+}
+
+// List returns the household's candidates, optionally only those with
+// status, by payee.
+func (s *SubscriptionStore) List(householdID uint, status string) ([]models.SubscriptionCandidate, error) {
+	q := s.DB.Where("household_id = ?", householdID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var out []models.SubscriptionCandidate
+	err := q.Order("payee asc").Find(&out).Error
+	return out, err
+}
+
+func (s *SubscriptionStore) Get(householdID, id uint) (*models.SubscriptionCandidate, error) {
+	var c models.SubscriptionCandidate
+	if err := s.DB.Where("id = ? AND household_id = ?", id, householdID).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Confirm turns the candidate into a recurring bill owned by userID, due on
+// the next expected charge. Dismissed candidates can still be confirmed.
+func (s *SubscriptionStore) Confirm(c *models.SubscriptionCandidate, userID uint) (*models.Bill, error) {
+	if c.Status == models.SubscriptionConfirmed {
+		return nil, ErrSubscriptionResolved
+	}
+	accountID := c.AccountID
+	bill := &models.Bill{
+		UserID:      userID,
+		HouseholdID: c.HouseholdID,
+		AccountID:   &accountID,
+		CategoryID:  c.CategoryID,
+		Name:        c.Memo,
+		AmountCents: c.AmountCents,
+	}
+	if err := SetSchedule(bill, subscriptionRRule(c.Interval), c.NextExpected); err != nil {
+		return nil, err
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(bill).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&models.SubscriptionCandidate{}).Where("id = ? AND status <> ?", c.ID, models.SubscriptionConfirmed).
+			Updates(map[string]any{"status": models.SubscriptionConfirmed, "bill_id": bill.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrSubscriptionResolved
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.Status, c.BillID = models.SubscriptionConfirmed, &bill.ID
+	return bill, nil
+}
+
+// Dismiss marks a pending candidate as not a subscription so it is not
+// proposed again.
+func (s *SubscriptionStore) Dismiss(c *models.SubscriptionCandidate) error {
+	if c.Status != models.SubscriptionPending {
+		return ErrSubscriptionResolved
+	}
+	if err := s.DB.Model(c).UpdateColumn("status", models.SubscriptionDismissed).Error; err != nil {
+		return err
+	}
+	c.Status = models.SubscriptionDismissed
+	return nil
+}
+
+// detectSubscription looks for a regular run of charges ending with the
+// payee's latest one. legs are in date order. The run's amounts are matched
+// among the earlier charges only, so a price change on the latest charge is
+// reported through AmountCents and PreviousAmountCents instead of breaking
+// the run.
+func detectSubscription(householdID uint, payee string, legs []models.Transaction, now time.Time) (models.SubscriptionCandidate, bool) {
+	n := len(legs)
+	if n < 2 {
+		return models.SubscriptionCandidate{}, false
+	}
+	last := legs[n-1]
+	interval, ok := classifyInterval(daysBetween(legs[n-2].OccurredAt, last.OccurredAt))
+	if !ok {
+		return models.SubscriptionCandidate{}, false
+	}
+	previous := -legs[n-2].AmountCents
+	run := 2
+	for i := n - 3; i >= 0; i-- {
+		gap := daysBetween(legs[i].OccurredAt, legs[i+1].OccurredAt)
+		if gap < interval.MinDays || gap > interval.MaxDays || !similarAmount(-legs[i].AmountCents, previous) {
+			break
+		}
+		run++
+	}
+	if run < interval.MinCharges || daysBetween(last.OccurredAt, now) > 2*interval.MaxDays {
+		return models.SubscriptionCandidate{}, false
+	}
+
+	// The charge after the latest one; it may already be overdue.
+	anchor := truncateDay(last.OccurredAt)
+	rule, _ := recurrence.Parse(interval.RRule)
+	next, _ := rule.Next(anchor, anchor)
+	return models.SubscriptionCandidate{
+		HouseholdID:         householdID,
+		Payee:               payee,
+		Memo:                strings.TrimSpace(last.Memo),
+		AccountID:           last.AccountID,
+		CategoryID:          last.CategoryID,
+		Interval:            interval.Name,
+		AmountCents:         -last.AmountCents,
+		PreviousAmountCents: previous,
+		Occurrences:         run,
+		LastTransactionID:   last.ID,
+		LastChargedAt:       last.OccurredAt,
+		NextExpected:        next,
+	}, true
+}
+
+func classifyInterval(days int) (subscriptionInterval, bool) {
+	for _, in := range subscriptionIntervals {
+		if days >= in.MinDays && days <= in.MaxDays {
+			return in, true
+		}
+	}
+	return subscriptionInterval{}, false
+}
+
+func subscriptionRRule(name string) string {
+	for _, in := range subscriptionIntervals {
+		if in.Name == name {
+			return in.RRule
+		}
+	}
+	return ""
+}
+
+func similarAmount(a, b int64) bool {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	return float64(diff) <= subscriptionAmountTolerance*float64(b)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(truncateDay(to).Sub(truncateDay(from)).Hours() / 24)
+}
+
+// payeeKey normalises a memo to its sorted words, so "NETFLIX.COM 0412" and
+// "Netflix com" group together.
+func payeeKey(memo string) string {
+	tokens := memoTokens(memo)
+	words := make([]string, 0, len(tokens))
+	for t := range tokens {
+		words = append(words, t)
+	}
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestSubscriptionDetectionAndPriceIncrease(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.SubscriptionCandidate{}, &models.Bill{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	acc := models.Account{HouseholdID: 1, Name: "Card", Type: "credit", Currency: "USD"}
+	gdb.Create(&acc)
+	ledger := LedgerStore{DB: gdb}
+	charge := func(memo string, cents int64, at time.Time) {
+		t.Helper()
+		leg := &models.Transaction{AccountID: acc.ID, AmountCents: -cents, Currency: "USD", Memo: memo, OccurredAt: at}
+		if _, err := ledger.PostStandard(1, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	for _, m := range []time.Month{time.March, time.April, time.May} {
+		charge("NETFLIX.COM 0412", 1599, time.Date(2025, m, 14, 9, 0, 0, 0, time.UTC))
+	}
+	// Irregular spending at the same payee is not a subscription.
+	charge("Corner Grocery", 4210, time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC))
+	charge("Corner Grocery", 3880, time.Date(2025, 5, 9, 9, 0, 0, 0, time.UTC))
+	charge("Corner Grocery", 6120, time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC))
+	// Nor is a regular transfer to another of the household's accounts.
+	savings := models.Account{HouseholdID: 1, Name: "Savings", Type: "savings", Currency: "USD"}
+	gdb.Create(&savings)
+	for _, m := range []time.Month{time.March, time.April, time.May} {
+		leg := &models.Transaction{AccountID: acc.ID, AmountCents: -20000, Currency: "USD", Memo: "Monthly savings", OccurredAt: time.Date(2025, m, 1, 9, 0, 0, 0, time.UTC)}
+		if _, err := ledger.PostTransfer(1, savings.ID, leg); err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+	}
+
+	store := SubscriptionStore{DB: gdb}
+	created, increased, err := store.Sync(1, now)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if len(created) != 1 || len(increased) != 0 {
+		t.Fatalf("expected one new candidate, got %+v / %+v", created, increased)
+	}
+	c := created[0]
+	if c.Payee != "com netflix" || c.Interval != "monthly" || c.AmountCents != 1599 || c.Occurrences != 3 {
+		t.Fatalf("unexpected candidate %+v", c)
+	}
+	if !c.NextExpected.Equal(time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected next expected %v", c.NextExpected)
+	}
+
+	// Re-running without new charges reports nothing.
+	if created, increased, _ = store.Sync(1, now); len(created)+len(increased) != 0 {
+		t.Fatalf("expected no changes, got %+v / %+v", created, increased)
+	}
+
+	// A rise beyond the amount tolerance is still the same subscription.
+	charge("Netflix.com", 2199, time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC))
+	_, increased, err = store.Sync(1, now)
+	if err != nil || len(increased) != 1 {
+		t.Fatalf("expected a price increase, got %+v (%v)", increased, err)
+	}
+	if increased[0].PreviousAmountCents != 1599 || increased[0].AmountCents != 2199 || increased[0].Occurrences != 4 {
+		t.Fatalf("unexpected increase %+v", increased[0])
+	}
+	if _, increased, _ = store.Sync(1, now); len(increased) != 0 {
+		t.Fatalf("price increase reported twice")
+	}
+
+	got, err := store.Get(1, c.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	bill, err := store.Confirm(got, 7)
+	if err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if bill.RRule != "FREQ=MONTHLY" || bill.AmountCents != 2199 || !bill.NextDue.Equal(time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected bill %+v", bill)
+	}
+	if got.Status != models.SubscriptionConfirmed || got.BillID == nil || *got.BillID != bill.ID {
+		t.Fatalf("candidate not confirmed: %+v", got)
+	}
+	if _, err := store.Confirm(got, 7); !errors.Is(err, ErrSubscriptionResolved) {
+		t.Fatalf("expected ErrSubscriptionResolved, got %v", err)
+	}
+	if err := store.Dismiss(got); !errors.Is(err, ErrSubscriptionResolved) {
+		t.Fatalf("expected ErrSubscriptionResolved on dismiss, got %v", err)
+	}
+}
+
+func TestSubscriptionDetectionSkipsStaleRuns(t *testing.T) {
+	gdb := newTestDB(t)
+	if err := gdb.AutoMigrate(&models.SubscriptionCandidate{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	acc := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&acc)
+	ledger := LedgerStore{DB: gdb}
+	for _, d := range []int{1, 8, 15} {
+		leg := &models.Transaction{AccountID: acc.ID, AmountCents: -999, Currency: "USD", Memo: "Gym", OccurredAt: time.Date(2025, 1, d, 9, 0, 0, 0, time.UTC)}
+		if _, err := ledger.PostStandard(1, leg); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+	store := SubscriptionStore{DB: gdb}
+	if out, _ := store.Detect(1, time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)); len(out) != 1 || out[0].Interval != "weekly" {
+		t.Fatalf("expected a weekly candidate, got %+v", out)
+	}
+	if out, _ := store.Detect(1, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)); len(out) != 0 {
+		t.Fatalf("expected stale run to be skipped, got %+v", out)
+	}
+}
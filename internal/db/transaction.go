@@ -1,7 +1,12 @@
 package db
 
 import (
+	"encoding/json"
+	"errors"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"bookkeeper-backend/internal/models"
 )
 
@@ -16,3 +21,223 @@ func (s *TransactionStore) GetTransactionByID(id uint) (*models.Transaction, err
 	}
 	return &t, nil
 }
+
+// ListUncategorizedBefore returns account legs entered by the user that have
+// no category, occurred before cutoff and have not been reminded of yet.
+// External counter legs and split legs (categorized through their splits)
+// are skipped.
+func (s *TransactionStore) ListUncategorizedBefore(userID uint, cutoff time.Time) ([]models.Transaction, error) {
+	var txs []models.Transaction
+	err := s.DB.
+		Joins("JOIN accounts a ON a.id = transactions.account_id").
+		Where("transactions.user_id = ? AND transactions.category_id IS NULL AND transactions.occurred_at < ? AND a.type <> ?", userID, cutoff, models.AccountTypeExternal).
+		Where("NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = transactions.id)").
+		Where("NOT EXISTS (SELECT 1 FROM uncategorized_reminders r WHERE r.transaction_id = transactions.id)").
+		Order("transactions.occurred_at desc").
+		Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// RecordUncategorizedReminders marks the legs as reminded.
+func (s *TransactionStore) RecordUncategorizedReminders(txs []models.Transaction) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		for _, t := range txs {
+			r := models.UncategorizedReminder{TransactionID: t.ID, CreatedAt: time.Now()}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&r).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+var (
+	ErrAmountNotEditable = errors.New("amount can only be changed on entries with two legs")
+	ErrTransferCategory  = errors.New("transfers cannot be categorized")
+	ErrSplitCategorized  = errors.New("split transactions are categorized through their splits")
+)
+
+// TransactionPatch lists the fields to change; nil fields are left alone.
+// SetCategory distinguishes clearing the category (CategoryID nil) from not
+// touching it.
+type TransactionPatch struct {
+	AmountCents *int64
+	Memo        *string
+	OccurredAt  *time.Time
+	SetCategory bool
+	CategoryID  *uint
+}
+
+// Update applies p to leg and records the change. Amount changes are
+// mirrored onto the entry's other leg so the entry stays balanced, and date
+// changes move the whole entry; the other legs' changes are recorded too.
+func (s *TransactionStore) Update(householdID, userID uint, leg *models.Transaction, p TransactionPatch) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		var entry models.JournalEntry
+		if leg.EntryID != 0 {
+			if err := tx.Preload("Lines").First(&entry, leg.EntryID).Error; err != nil {
+				return err
+			}
+		}
+		var splits int64
+		tx.Model(&models.TransactionSplit{}).Where("transaction_id = ?", leg.ID).Count(&splits)
+
+		changes := map[string][2]any{}
+		updates := map[string]any{}
+		// otherChanges are the changes made to the entry's other legs.
+		otherChanges := map[uint]map[string][2]any{}
+		recordOther := func(other models.Transaction, field string, before, after any) {
+			if otherChanges[other.ID] == nil {
+				otherChanges[other.ID] = map[string][2]any{}
+			}
+			otherChanges[other.ID][field] = [2]any{before, after}
+		}
+		if p.SetCategory && !sameUintPtr(leg.CategoryID, p.CategoryID) {
+			if p.CategoryID != nil && entry.Kind == models.EntryKindTransfer {
+				return ErrTransferCategory
+			}
+			if p.CategoryID != nil && splits > 0 {
+				return ErrSplitCategorized
+			}
+			changes["category_id"] = [2]any{leg.CategoryID, p.CategoryID}
+			updates["category_id"] = p.CategoryID
+		}
+		if p.Memo != nil && *p.Memo != leg.Memo {
+			changes["memo"] = [2]any{leg.Memo, *p.Memo}
+			updates["memo"] = *p.Memo
+		}
+		if p.AmountCents != nil && *p.AmountCents != leg.AmountCents {
+			if *p.AmountCents == 0 {
+				return ErrInvalidEntry
+			}
+			if len(entry.Lines) != 2 {
+				return ErrAmountNotEditable
+			}
+			if splits > 0 {
+				return ErrSplitMismatch
+			}
+			for _, other := range entry.Lines {
+				if other.ID == leg.ID {
+					continue
+				}
+				if err := tx.Model(&models.Transaction{}).Where("id = ?", other.ID).
+					Update("amount_cents", -*p.AmountCents).Error; err != nil {
+					return err
+				}
+				recordOther(other, "amount_cents", other.AmountCents, -*p.AmountCents)
+			}
+			changes["amount_cents"] = [2]any{leg.AmountCents, *p.AmountCents}
+			updates["amount_cents"] = *p.AmountCents
+		}
+		if p.OccurredAt != nil && !p.OccurredAt.Equal(leg.OccurredAt) {
+			if leg.EntryID != 0 {
+				if err := tx.Model(&models.Transaction{}).Where("entry_id = ?", leg.EntryID).
+					Update("occurred_at", *p.OccurredAt).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&models.JournalEntry{}).Where("id = ?", leg.EntryID).
+					Update("occurred_at", *p.OccurredAt).Error; err != nil {
+					return err
+				}
+				for _, other := range entry.Lines {
+					if other.ID != leg.ID && !other.OccurredAt.Equal(*p.OccurredAt) {
+						recordOther(other, "occurred_at", other.OccurredAt, *p.OccurredAt)
+					}
+				}
+			}
+			changes["occurred_at"] = [2]any{leg.OccurredAt, *p.OccurredAt}
+			updates["occurred_at"] = *p.OccurredAt
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.Transaction{}).Where("id = ?", leg.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Splits").First(leg, leg.ID).Error; err != nil {
+			return err
+		}
+		store := TransactionStore{DB: tx}
+		if err := store.RecordAudit(householdID, userID, leg.ID, models.AuditUpdate, changes); err != nil {
+			return err
+		}
+		for _, other := range entry.Lines {
+			if c, ok := otherChanges[other.ID]; ok {
+				if err := store.RecordAudit(householdID, userID, other.ID, models.AuditUpdate, c); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the leg's whole journal entry (every leg, split and
+// duplicate suspect) and records each removed leg in the audit trail.
+func (s *TransactionStore) Delete(householdID, userID uint, leg *models.Transaction) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		legs := []models.Transaction{*leg}
+		if leg.EntryID != 0 {
+			if err := tx.Where("entry_id = ?", leg.EntryID).Order("id asc").Find(&legs).Error; err != nil {
+				return err
+			}
+		}
+		ids := make([]uint, 0, len(legs))
+		for _, l := range legs {
+			ids = append(ids, l.ID)
+		}
+		if err := tx.Where("transaction_id IN ?", ids).Delete(&models.TransactionSplit{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("transaction_id IN ? OR duplicate_of_id IN ?", ids, ids).Delete(&models.DuplicateSuspect{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&models.Transaction{}).Error; err != nil {
+			return err
+		}
+		if leg.EntryID != 0 {
+			if err := tx.Delete(&models.JournalEntry{}, leg.EntryID).Error; err != nil {
+				return err
+			}
+		}
+		store := TransactionStore{DB: tx}
+		for i := range legs {
+			if err := store.RecordAudit(householdID, userID, legs[i].ID, models.AuditDelete, map[string][2]any{"transaction": {&legs[i], nil}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RecordAudit appends an entry to the transaction's audit trail.
+func (s *TransactionStore) RecordAudit(householdID, userID, transactionID uint, action string, changes map[string][2]any) error {
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	return s.DB.Create(&models.TransactionAudit{
+		HouseholdID:   householdID,
+		TransactionID: transactionID,
+		UserID:        userID,
+		Action:        action,
+		Changes:       string(b),
+	}).Error
+}
+
+// ListAudit returns a transaction's audit trail, oldest first.
+func (s *TransactionStore) ListAudit(transactionID uint) ([]models.TransactionAudit, error) {
+	var out []models.TransactionAudit
+	err := s.DB.Where("transaction_id = ?", transactionID).Order("created_at asc, id asc").Find(&out).Error
+	return out, err
+}
+
+func sameUintPtr(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestTransactionUpdateKeepsEntryBalanced(t *testing.T) {
+	gdb := newTestDB(t)
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	savings := models.Account{HouseholdID: 1, Name: "Savings", Type: "savings", Currency: "USD"}
+	gdb.Create(&checking)
+	gdb.Create(&savings)
+	ledger := LedgerStore{DB: gdb}
+	store := TransactionStore{DB: gdb}
+
+	leg := &models.Transaction{AccountID: checking.ID, AmountCents: -2000, Currency: "USD", Memo: "Lunch", OccurredAt: time.Now()}
+	ledger.PostStandard(1, leg)
+
+	amount, memo := int64(-2500), "Lunch with tip"
+	when := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cat := uint(4)
+	patch := TransactionPatch{AmountCents: &amount, Memo: &memo, OccurredAt: &when, SetCategory: true, CategoryID: &cat}
+	if err := store.Update(1, 9, leg, patch); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	var sum int64
+	gdb.Model(&models.Transaction{}).Where("entry_id = ?", leg.EntryID).Select("COALESCE(SUM(amount_cents),0)").Scan(&sum)
+	if sum != 0 || leg.AmountCents != -2500 || leg.CategoryID == nil {
+		t.Fatalf("entry unbalanced or leg not refreshed: sum=%d leg=%+v", sum, leg)
+	}
+	var moved int64
+	gdb.Model(&models.Transaction{}).Where("entry_id = ? AND occurred_at = ?", leg.EntryID, when).Count(&moved)
+	if moved != 2 {
+		t.Fatalf("expected both legs re-dated, got %d", moved)
+	}
+	var counter models.Transaction
+	gdb.Where("entry_id = ? AND id <> ?", leg.EntryID, leg.ID).First(&counter)
+	if trail, _ := store.ListAudit(counter.ID); len(trail) != 1 || !strings.Contains(trail[0].Changes, "amount_cents") || !strings.Contains(trail[0].Changes, "occurred_at") {
+		t.Fatalf("expected the counter leg's changes audited, got %+v", trail)
+	}
+
+	transfer := &models.Transaction{AccountID: checking.ID, AmountCents: -100, Currency: "USD", OccurredAt: time.Now()}
+	ledger.PostTransfer(1, savings.ID, transfer)
+	if err := store.Update(1, 9, transfer, TransactionPatch{SetCategory: true, CategoryID: &cat}); !errors.Is(err, ErrTransferCategory) {
+		t.Fatalf("expected ErrTransferCategory, got %v", err)
+	}
+
+	if err := store.Delete(1, 9, leg); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	var left int64
+	gdb.Model(&models.Transaction{}).Where("entry_id = ?", leg.EntryID).Count(&left)
+	if left != 0 {
+		t.Fatalf("expected entry legs removed, %d left", left)
+	}
+	trail, _ := store.ListAudit(leg.ID)
+	if len(trail) != 2 || trail[0].Action != models.AuditUpdate || trail[1].Action != models.AuditDelete {
+		t.Fatalf("unexpected audit trail %+v", trail)
+	}
+	if trail, _ := store.ListAudit(counter.ID); len(trail) != 2 || trail[1].Action != models.AuditDelete {
+		t.Fatalf("expected the counter leg's delete audited, got %+v", trail)
+	}
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"context"
+
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type UserStore struct {
+	DB *gorm.DB
+}
+
+func (s *UserStore) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	var u models.User
+	if err := s.DB.WithContext(ctx).First(&u, id).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ListIDs returns every user id, for jobs that sweep all users.
+func (s *UserStore) ListIDs() ([]uint, error) {
+	var ids []uint
+	err := s.DB.Model(&models.User{}).Order("id asc").Pluck("id", &ids).Error
+	return ids, err
+}
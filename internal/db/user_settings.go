@@ -5,6 +5,10 @@ import (
 	"bookkeeper-backend/internal/models"
 )
 
+// DefaultLowBalanceThreshold is the low-balance threshold, in cents, for
+// users who have not set one.
+const DefaultLowBalanceThreshold int64 = 10000
+
 type UserSettingsStore struct {
 	DB *gorm.DB
 }
@@ -26,3 +30,24 @@ func (s *UserSettingsStore) Upsert(userID uint, threshold int64) error {
 	us = models.UserSettings{UserID: userID, LargeTransactionThreshold: threshold}
 	return s.DB.Create(&us).Error
 }
+
+// SetLowBalanceThreshold stores the balance (in cents) below which accounts trigger low-balance alerts.
+func (s *UserSettingsStore) SetLowBalanceThreshold(userID uint, cents int64) error {
+	var us models.UserSettings
+	if err := s.DB.Where("user_id = ?", userID).First(&us).Error; err == nil {
+		us.LowBalanceThreshold = cents
+		return s.DB.Save(&us).Error
+	}
+	us = models.UserSettings{UserID: userID, LowBalanceThreshold: cents}
+	return s.DB.Create(&us).Error
+}
+
+// LowBalanceThreshold returns the user's low-balance threshold in cents, or
+// DefaultLowBalanceThreshold when they have not set one.
+func (s *UserSettingsStore) LowBalanceThreshold(userID uint) int64 {
+	settings, err := s.GetByUserID(userID)
+	if err != nil || settings.LowBalanceThreshold <= 0 {
+		return DefaultLowBalanceThreshold
+	}
+	return settings.LowBalanceThreshold
+}
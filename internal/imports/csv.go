@@ -0,0 +1,268 @@
+package imports
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"bookkeeper-backend/internal/models"
+)
+
+// Row is one parsed statement line. AmountCents is signed relative to the
+// account being imported into (positive = inflow).
+type Row struct {
+	Line        int
+	OccurredAt  time.Time
+	AmountCents int64
+	Memo        string
+	ExternalID  string // bank-assigned id, when the format provides one
+	Err         error  // set when the line could not be parsed
+}
+
+// ValidateProfile checks that a profile is complete enough to parse a file.
+func ValidateProfile(p models.ImportProfile) error {
+	if strings.TrimSpace(p.DateColumn) == "" {
+		return errors.New("date_column required")
+	}
+	if p.AmountColumn == "" && p.DebitColumn == "" && p.CreditColumn == "" {
+		return errors.New("amount_column or debit_column/credit_column required")
+	}
+	if p.AmountColumn != "" && (p.DebitColumn != "" || p.CreditColumn != "") {
+		return errors.New("use either amount_column or debit_column/credit_column, not both")
+	}
+	switch p.SignConvention {
+	case "", models.SignPositiveInflow, models.SignPositiveOutflow:
+	default:
+		return fmt.Errorf("unknown sign_convention %q", p.SignConvention)
+	}
+	if _, err := DateLayout(p.DateFormat); err != nil {
+		return err
+	}
+	if len([]rune(p.Delimiter)) > 1 {
+		return errors.New("delimiter must be a single character")
+	}
+	return nil
+}
+
+// ParseCSV reads a statement according to p. Lines that cannot be parsed
+// are returned with Err set; an error is only returned when the file as a
+// whole is unreadable or does not match the profile's columns.
+func ParseCSV(r io.Reader, p models.ImportProfile) ([]Row, error) {
+	if err := ValidateProfile(p); err != nil {
+		return nil, err
+	}
+	layout, _ := DateLayout(p.DateFormat)
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	if p.Delimiter != "" {
+		cr.Comma = []rune(p.Delimiter)[0]
+	}
+
+	var records [][]string
+	var lines []int
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		records = append(records, rec)
+		lines = append(lines, line)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty file")
+	}
+
+	var header []string
+	first := 0
+	if p.HasHeader {
+		header = records[0]
+		first = 1
+	}
+	col := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		return columnIndex(header, name)
+	}
+	dateIdx, err := col(p.DateColumn)
+	if err != nil {
+		return nil, err
+	}
+	amountIdx, err := col(p.AmountColumn)
+	if err != nil {
+		return nil, err
+	}
+	debitIdx, err := col(p.DebitColumn)
+	if err != nil {
+		return nil, err
+	}
+	creditIdx, err := col(p.CreditColumn)
+	if err != nil {
+		return nil, err
+	}
+	memoIdx, err := col(p.MemoColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, 0, len(records)-first)
+	for i := first; i < len(records); i++ {
+		rec := records[i]
+		if blankRecord(rec) {
+			continue
+		}
+		row := Row{Line: lines[i]}
+		field := func(idx int) string {
+			if idx < 0 || idx >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[idx])
+		}
+
+		occ, err := time.Parse(layout, field(dateIdx))
+		if err != nil {
+			row.Err = fmt.Errorf("invalid date %q", field(dateIdx))
+			rows = append(rows, row)
+			continue
+		}
+		row.OccurredAt = occ
+
+		if amountIdx >= 0 {
+			amt, err := ParseAmountCents(field(amountIdx))
+			if err != nil {
+				row.Err = err
+				rows = append(rows, row)
+				continue
+			}
+			if p.SignConvention == models.SignPositiveOutflow {
+				amt = -amt
+			}
+			row.AmountCents = amt
+		} else {
+			var debit, credit int64
+			if v := field(debitIdx); v != "" {
+				if debit, err = ParseAmountCents(v); err != nil {
+					row.Err = err
+					rows = append(rows, row)
+					continue
+				}
+			}
+			if v := field(creditIdx); v != "" {
+				if credit, err = ParseAmountCents(v); err != nil {
+					row.Err = err
+					rows = append(rows, row)
+					continue
+				}
+			}
+			row.AmountCents = abs(credit) - abs(debit)
+		}
+		row.Memo = field(memoIdx)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ParseAmountCents converts a statement amount such as "1,234.56",
+// "-$12.00", "(45.10)" or "12.5-" into signed cents without going through
+// floating point.
+func ParseAmountCents(s string) (int64, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg = true
+		s = s[1 : len(s)-1]
+	}
+	s = strings.NewReplacer("$", "", "€", "", "£", "", ",", "", " ", "").Replace(s)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = !neg
+		s = s[1:]
+	case strings.HasSuffix(s, "-"):
+		neg = !neg
+		s = s[:len(s)-1]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount %q", orig)
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("invalid amount %q: too many decimal places", orig)
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", orig)
+	}
+	f, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", orig)
+	}
+	cents := w*100 + f
+	if neg {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+// DateLayout converts a human date pattern (YYYY, YY, MM, M, DD, D) into a
+// Go time layout. An empty pattern means ISO dates.
+func DateLayout(format string) (string, error) {
+	if format == "" {
+		return "2006-01-02", nil
+	}
+	r := strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02", "M", "1", "D", "2")
+	layout := r.Replace(format)
+	if strings.ContainsAny(layout, "YMD") {
+		return "", fmt.Errorf("unsupported date_format %q", format)
+	}
+	return layout, nil
+}
+
+func columnIndex(header []string, name string) (int, error) {
+	if header == nil {
+		n, err := strconv.Atoi(name)
+		if err != nil || n < 1 {
+			return -1, fmt.Errorf("column %q must be a 1-based position when the file has no header", name)
+		}
+		return n - 1, nil
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), strings.TrimSpace(name)) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("column %q not found in header", name)
+}
+
+func blankRecord(rec []string) bool {
+	for _, f := range rec {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,88 @@
+package imports
+
+import (
+	"strings"
+	"testing"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestParseAmountCents(t *testing.T) {
+	cases := map[string]int64{
+		"12.34":      1234,
+		"-12.34":     -1234,
+		"$1,234.5":   123450,
+		"(45.10)":    -4510,
+		"12.50-":     -1250,
+		"+7":         700,
+		".99":        99,
+		"€ 3,000.00": 300000,
+	}
+	for in, want := range cases {
+		got, err := ParseAmountCents(in)
+		if err != nil || got != want {
+			t.Errorf("ParseAmountCents(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "abc", "1.234", "--"} {
+		if _, err := ParseAmountCents(bad); err == nil {
+			t.Errorf("ParseAmountCents(%q) expected error", bad)
+		}
+	}
+}
+
+func TestParseCSVAmountColumn(t *testing.T) {
+	p := models.ImportProfile{
+		HasHeader:      true,
+		DateColumn:     "Posted Date",
+		DateFormat:     "MM/DD/YYYY",
+		AmountColumn:   "Amount",
+		MemoColumn:     "Description",
+		SignConvention: models.SignPositiveOutflow,
+	}
+	in := "Posted Date,Description,Amount\n" +
+		"03/01/2025,COFFEE SHOP,4.50\n" +
+		"\n" +
+		"03/02/2025,PAYMENT THANK YOU,-200.00\n" +
+		"2025-03-03,BAD DATE,1.00\n"
+	rows, err := ParseCSV(strings.NewReader(in), p)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	if rows[0].AmountCents != -450 || rows[0].Memo != "COFFEE SHOP" || rows[0].OccurredAt.Day() != 1 {
+		t.Errorf("unexpected first row %+v", rows[0])
+	}
+	if rows[1].AmountCents != 20000 || rows[1].Line != 4 {
+		t.Errorf("unexpected second row %+v", rows[1])
+	}
+	if rows[2].Err == nil {
+		t.Errorf("expected bad date to be rejected")
+	}
+}
+
+func TestParseCSVDebitCreditWithoutHeader(t *testing.T) {
+	p := models.ImportProfile{
+		DateColumn:   "1",
+		DebitColumn:  "3",
+		CreditColumn: "4",
+		MemoColumn:   "2",
+	}
+	in := "2025-04-01,Rent,1500.00,\n2025-04-02,Salary,,3200.00\n"
+	rows, err := ParseCSV(strings.NewReader(in), p)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if rows[0].AmountCents != -150000 || rows[1].AmountCents != 320000 {
+		t.Fatalf("unexpected amounts %d %d", rows[0].AmountCents, rows[1].AmountCents)
+	}
+}
+
+func TestParseCSVMissingColumn(t *testing.T) {
+	p := models.ImportProfile{HasHeader: true, DateColumn: "Date", AmountColumn: "Amount"}
+	if _, err := ParseCSV(strings.NewReader("When,Amount\n2025-01-01,1\n"), p); err == nil {
+		t.Fatal("expected missing column error")
+	}
+}
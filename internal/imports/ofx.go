@@ -0,0 +1,208 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Statement is the subset of an OFX/QFX download needed for import.
+type Statement struct {
+	Currency  string
+	AccountID string // ACCTID as reported by the bank
+	Rows      []Row  // Line is the 1-based position of the STMTTRN record
+	// LedgerBalanceCents is the bank's reported LEDGERBAL, when present.
+	LedgerBalanceCents *int64
+	LedgerBalanceAsOf  time.Time
+}
+
+// ofxToken is an opening tag (optionally carrying a leaf value) or a closing tag.
+type ofxToken struct {
+	name    string
+	value   string
+	closing bool
+}
+
+// ParseOFX reads OFX 1.x (SGML, leaf tags unclosed) and 2.x (XML) files.
+// Each STMTTRN becomes a Row whose ExternalID is the record's FITID.
+func ParseOFX(r io.Reader) (*Statement, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read ofx: %w", err)
+	}
+	body := string(b)
+	start := strings.Index(strings.ToUpper(body), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("not an OFX file: missing <OFX>")
+	}
+	tokens := tokenizeOFX(body[start:])
+
+	st := &Statement{}
+	var cur map[string]string
+	var ledger map[string]string
+	seq := 0
+	for _, tok := range tokens {
+		switch {
+		case !tok.closing && tok.name == "STMTTRN":
+			cur = map[string]string{}
+		case tok.closing && tok.name == "STMTTRN":
+			if cur != nil {
+				seq++
+				st.Rows = append(st.Rows, ofxRow(seq, cur))
+			}
+			cur = nil
+		case !tok.closing && tok.name == "LEDGERBAL":
+			ledger = map[string]string{}
+		case tok.closing && tok.name == "LEDGERBAL":
+			if err := st.setLedgerBalance(ledger); err != nil {
+				return nil, err
+			}
+			ledger = nil
+		case !tok.closing && tok.value != "":
+			switch {
+			case cur != nil:
+				cur[tok.name] = tok.value
+			case ledger != nil:
+				ledger[tok.name] = tok.value
+			case tok.name == "CURDEF":
+				st.Currency = tok.value
+			case tok.name == "ACCTID":
+				st.AccountID = tok.value
+			}
+		}
+	}
+	// SGML files may omit </LEDGERBAL> when it is the last aggregate.
+	if ledger != nil {
+		if err := st.setLedgerBalance(ledger); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+func (st *Statement) setLedgerBalance(fields map[string]string) error {
+	amt, ok := fields["BALAMT"]
+	if !ok {
+		return nil
+	}
+	cents, err := ParseAmountCents(ofxDecimal(amt))
+	if err != nil {
+		return fmt.Errorf("LEDGERBAL: %w", err)
+	}
+	st.LedgerBalanceCents = &cents
+	if v, ok := fields["DTASOF"]; ok {
+		if t, err := ParseOFXDate(v); err == nil {
+			st.LedgerBalanceAsOf = t
+		}
+	}
+	return nil
+}
+
+func ofxRow(seq int, f map[string]string) Row {
+	row := Row{Line: seq, ExternalID: f["FITID"]}
+	if row.ExternalID == "" {
+		row.Err = errors.New("missing FITID")
+		return row
+	}
+	occ, err := ParseOFXDate(f["DTPOSTED"])
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	row.OccurredAt = occ
+	amt, err := ParseAmountCents(ofxDecimal(f["TRNAMT"]))
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	row.AmountCents = amt
+	row.Memo = f["NAME"]
+	if m := f["MEMO"]; m != "" && m != row.Memo {
+		row.Memo = strings.TrimSpace(row.Memo + " " + m)
+	}
+	return row
+}
+
+// tokenizeOFX splits the document into tags. Text following an opening tag
+// up to the next '<' is that element's value, which covers both unclosed
+// SGML leaves and closed XML leaves.
+func tokenizeOFX(s string) []ofxToken {
+	var out []ofxToken
+	for {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			return out
+		}
+		s = s[lt+1:]
+		gt := strings.IndexByte(s, '>')
+		if gt < 0 {
+			return out
+		}
+		tag := strings.TrimSpace(s[:gt])
+		s = s[gt+1:]
+		if tag == "" || tag[0] == '?' || tag[0] == '!' {
+			continue
+		}
+		if tag[0] == '/' {
+			out = append(out, ofxToken{name: strings.ToUpper(strings.TrimSpace(tag[1:])), closing: true})
+			continue
+		}
+		if i := strings.IndexAny(tag, " \t\r\n"); i >= 0 {
+			tag = tag[:i]
+		}
+		value := s
+		if next := strings.IndexByte(s, '<'); next >= 0 {
+			value = s[:next]
+		}
+		out = append(out, ofxToken{name: strings.ToUpper(tag), value: html.UnescapeString(strings.TrimSpace(value))})
+	}
+}
+
+// ParseOFXDate parses OFX datetimes such as "20250301", "20250301120000"
+// and "20250301120000.000[-5:EST]". Date-only values are midnight UTC.
+func ParseOFXDate(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	base, tz, _ := strings.Cut(v, "[")
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	if len(base) < 8 {
+		return time.Time{}, fmt.Errorf("invalid OFX date %q", v)
+	}
+	loc := time.UTC
+	if tz != "" {
+		offset, _, _ := strings.Cut(strings.TrimSuffix(tz, "]"), ":")
+		hours, err := strconv.ParseFloat(offset, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid OFX timezone %q", v)
+		}
+		loc = time.FixedZone("", int(hours*3600))
+	}
+	var t time.Time
+	var err error
+	switch {
+	case len(base) >= 14:
+		t, err = time.ParseInLocation("20060102150405", base[:14], loc)
+	case len(base) >= 12:
+		t, err = time.ParseInLocation("200601021504", base[:12], loc)
+	default:
+		t, err = time.ParseInLocation("20060102", base[:8], time.UTC)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid OFX date %q", v)
+	}
+	return t.UTC(), nil
+}
+
+// ofxDecimal accepts a comma as the decimal separator, which some banks
+// emit despite the spec.
+func ofxDecimal(v string) string {
+	if strings.Contains(v, ",") && !strings.Contains(v, ".") {
+		return strings.Replace(v, ",", ".", 1)
+	}
+	return v
+}
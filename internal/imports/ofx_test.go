@@ -0,0 +1,101 @@
+package imports
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>USD
+<BANKACCTFROM><BANKID>123<ACCTID>000111<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250301120000.000[-5:EST]
+<TRNAMT>-4.50
+<FITID>A1
+<NAME>COFFEE SHOP
+<MEMO>Card 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20250302
+<TRNAMT>1200,00
+<FITID>A2
+<NAME>PAYROLL &amp; CO
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250303
+<TRNAMT>-1.00
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>1195.50<DTASOF>20250303
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+func TestParseOFXSGML(t *testing.T) {
+	st, err := ParseOFX(strings.NewReader(sgmlStatement))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if st.Currency != "USD" || st.AccountID != "000111" {
+		t.Errorf("unexpected statement header %+v", st)
+	}
+	if len(st.Rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(st.Rows))
+	}
+	first := st.Rows[0]
+	if first.ExternalID != "A1" || first.AmountCents != -450 || first.Memo != "COFFEE SHOP Card 1234" {
+		t.Errorf("unexpected first row %+v", first)
+	}
+	if want := time.Date(2025, 3, 1, 17, 0, 0, 0, time.UTC); !first.OccurredAt.Equal(want) {
+		t.Errorf("expected %v, got %v", want, first.OccurredAt)
+	}
+	if st.Rows[1].AmountCents != 120000 || st.Rows[1].Memo != "PAYROLL & CO" {
+		t.Errorf("unexpected second row %+v", st.Rows[1])
+	}
+	if st.Rows[2].Err == nil {
+		t.Error("expected row without FITID to be rejected")
+	}
+	if st.LedgerBalanceCents == nil || *st.LedgerBalanceCents != 119550 {
+		t.Fatalf("unexpected ledger balance %v", st.LedgerBalanceCents)
+	}
+	if st.LedgerBalanceAsOf.Day() != 3 {
+		t.Errorf("unexpected ledger date %v", st.LedgerBalanceAsOf)
+	}
+}
+
+func TestParseOFXXML(t *testing.T) {
+	in := `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+<CURDEF>CAD</CURDEF>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20250410</DTPOSTED><TRNAMT>-25.00</TRNAMT><FITID>X9</FITID><NAME>GROCER</NAME></STMTTRN>
+</BANKTRANLIST>
+</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>`
+	st, err := ParseOFX(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if st.Currency != "CAD" || len(st.Rows) != 1 || st.LedgerBalanceCents != nil {
+		t.Fatalf("unexpected statement %+v", st)
+	}
+	if r := st.Rows[0]; r.ExternalID != "X9" || r.AmountCents != -2500 || r.Memo != "GROCER" {
+		t.Errorf("unexpected row %+v", r)
+	}
+}
+
+func TestParseOFXRejectsNonOFX(t *testing.T) {
+	if _, err := ParseOFX(strings.NewReader("Date,Amount\n2025-01-01,1\n")); err == nil {
+		t.Fatal("expected error for non-OFX input")
+	}
+}
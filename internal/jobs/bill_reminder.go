@@ -2,31 +2,75 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 	"bookkeeper-backend/internal/db"
 	"bookkeeper-backend/internal/models"
 )
 
-// BillReminderJob checks for bills due in 3 days and creates notifications
-func BillReminderJob(ctx context.Context, billStore *db.BillStore, notificationStore *db.NotificationStore, userID uint) error {
-	bills, err := billStore.ListDueInDays(userID, 3)
+// billReminderDays is how far ahead bills are reminded of.
+const billReminderDays = 3
+
+// BillReminderJob sends every member of the household one notification
+// listing its bills due in the next 3 days. Each occurrence is only reminded
+// once; it is recorded after the notifications are sent so a failed run is
+// retried.
+func BillReminderJob(ctx context.Context, billStore *db.BillStore, householdStore *db.HouseholdStore, notificationStore *db.NotificationStore, householdID uint) error {
+	bills, err := billStore.ListDueInDays(householdID, billReminderDays)
+	if err != nil || len(bills) == 0 {
+		return err
+	}
+	title, msg := "Bill due soon", "Bill '"+bills[0].Name+"' is due soon: $"+formatCents(bills[0].AmountCents)+" on "+bills[0].NextDue.Format("2006-01-02")
+	if len(bills) > 1 {
+		parts := make([]string, 0, len(bills))
+		for _, bill := range bills {
+			parts = append(parts, fmt.Sprintf("'%s' $%s on %s", bill.Name, formatCents(bill.AmountCents), bill.NextDue.Format("2006-01-02")))
+		}
+		title = "Bills due soon"
+		msg = fmt.Sprintf("%d bills are due in the next %d days: %s", len(bills), billReminderDays, strings.Join(parts, ", "))
+	}
+	members, err := householdStore.MemberIDs(householdID)
 	if err != nil {
 		return err
 	}
-	for _, bill := range bills {
-		msg := "Bill '" + bill.Name + "' is due soon: $" + formatCents(bill.AmountCents) + " on " + bill.NextDue.Format("2006-01-02")
+	for _, userID := range members {
 		n := &models.Notification{
-			UserID:  userID,
-			Type:    models.NotificationType("bill"),
-			Message: msg,
-			Read:    false,
+			UserID:    int64(userID),
+			Type:      models.NotificationType("bill"),
+			Title:     title,
+			Message:   msg,
+			Read:      false,
 			CreatedAt: time.Now(),
 		}
-		notificationStore.CreateNotification(ctx, n)
+		if err := notificationStore.CreateNotification(ctx, n); err != nil {
+			return err
+		}
 	}
-	return nil
+	return billStore.RecordReminders(bills)
 }
 
 func formatCents(cents int64) string {
 	return fmt.Sprintf("%.2f", float64(cents)/100)
 }
+
+// BillReminderSweepJob runs BillReminderJob for every household.
+func BillReminderSweepJob(ctx context.Context, billStore *db.BillStore, householdStore *db.HouseholdStore, notificationStore *db.NotificationStore) error {
+	households, err := householdStore.ListIDs()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, hID := range households {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := BillReminderJob(ctx, billStore, householdStore, notificationStore, hID); err != nil {
+			slog.Error("bill reminders failed", "household_id", hID, "error", err)
+			errs = append(errs, fmt.Errorf("household %d: %w", hID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
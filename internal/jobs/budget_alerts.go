@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+)
+
+// BudgetAlertJob evaluates a household's budgets for month and notifies every
+// member once per category that crossed a new threshold. Evaluate claims each
+// threshold before it is notified, so a crossing is notified at most once even
+// when evaluations overlap; one whose notification fails is not retried.
+func BudgetAlertJob(ctx context.Context, alertStore *db.BudgetAlertStore, notificationStore *db.NotificationStore, householdID uint, month string) error {
+	hits, err := alertStore.Evaluate(householdID, month)
+	if err != nil || len(hits) == 0 {
+		return err
+	}
+	members, err := alertStore.MemberIDs(householdID)
+	if err != nil {
+		return err
+	}
+	for _, hit := range hits {
+		name := hit.CategoryName
+		if name == "" {
+			name = fmt.Sprintf("category %d", hit.CategoryID)
+		}
+		msg := fmt.Sprintf("%s has reached %d%% of its %s budget: $%s spent of $%s",
+			name, hit.Threshold, hit.Month, formatCents(hit.SpentCents), formatCents(hit.PlannedCents))
+		for _, userID := range members {
+			n := &models.Notification{
+				UserID:    int64(userID),
+				Type:      models.NotificationTypeBudget,
+				Title:     "Budget alert",
+				Message:   msg,
+				Read:      false,
+				CreatedAt: time.Now(),
+			}
+			if err := notificationStore.CreateNotification(ctx, n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// BudgetAlertSweepJob runs BudgetAlertJob for every household with a budget
+// in the current month, catching anything missed on write.
+func BudgetAlertSweepJob(ctx context.Context, alertStore *db.BudgetAlertStore, notificationStore *db.NotificationStore) error {
+	month := time.Now().UTC().Format("2006-01")
+	households, err := alertStore.BudgetedHouseholds(month)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, hID := range households {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := BudgetAlertJob(ctx, alertStore, notificationStore, hID, month); err != nil {
+			slog.Error("budget alert evaluation failed", "household_id", hID, "error", err)
+			errs = append(errs, fmt.Errorf("household %d: %w", hID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
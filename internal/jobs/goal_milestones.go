@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+)
+
+// GoalMilestoneJob refreshes a goal's progress and notifies its owner of the
+// highest milestone newly reached. Milestones already recorded stay silent.
+func GoalMilestoneJob(ctx context.Context, goalStore *db.GoalStore, notificationStore *db.NotificationStore, goal *models.Goal) error {
+	reached, err := goalStore.Refresh(goal)
+	if err != nil || len(reached) == 0 {
+		return err
+	}
+	top := reached[len(reached)-1]
+	msg := fmt.Sprintf("Goal '%s' is %d%% complete: $%s of $%s", goal.Name, top, formatCents(goal.CurrentCents), formatCents(goal.TargetCents))
+	if top >= 100 {
+		msg = "Goal '" + goal.Name + "' is complete!"
+	}
+	n := &models.Notification{
+		UserID:    int64(goal.UserID),
+		Type:      models.NotificationTypeGoal,
+		Title:     "Goal milestone",
+		Message:   msg,
+		Read:      false,
+		CreatedAt: time.Now(),
+	}
+	return notificationStore.CreateNotification(ctx, n)
+}
+
+// HouseholdGoalsJob runs GoalMilestoneJob for every goal tracking one of the
+// household's accounts or categories.
+func HouseholdGoalsJob(ctx context.Context, goalStore *db.GoalStore, notificationStore *db.NotificationStore, householdID uint) error {
+	goals, err := goalStore.ListLinked(householdID)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range goals {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := GoalMilestoneJob(ctx, goalStore, notificationStore, &goals[i]); err != nil {
+			slog.Error("goal milestone check failed", "goal_id", goals[i].ID, "error", err)
+			errs = append(errs, fmt.Errorf("goal %d: %w", goals[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GoalMilestoneSweepJob runs HouseholdGoalsJob for every household, catching
+// progress on goals that did not come from a transaction write.
+func GoalMilestoneSweepJob(ctx context.Context, goalStore *db.GoalStore, householdStore *db.HouseholdStore, notificationStore *db.NotificationStore) error {
+	households, err := householdStore.ListIDs()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, hID := range households {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := HouseholdGoalsJob(ctx, goalStore, notificationStore, hID); err != nil {
+			slog.Error("household goals failed", "household_id", hID, "error", err)
+			errs = append(errs, fmt.Errorf("household %d: %w", hID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+)
+
+// GoalProjectionJob projects a goal with a due date and notifies its owner
+// when it falls behind. The status is remembered, so a goal is reported again
+// only after it has been back on track.
+func GoalProjectionJob(ctx context.Context, goalStore *db.GoalStore, notificationStore *db.NotificationStore, goal *models.Goal) error {
+	if goal.DueDate == nil {
+		return nil
+	}
+	p, err := goalStore.Project(goal, time.Now(), db.DefaultGoalLookbackMonths, 0)
+	if err != nil {
+		return err
+	}
+	if p.Status == goal.ProjectionStatus {
+		return nil
+	}
+	if p.Status == db.GoalStatusBehind {
+		msg := fmt.Sprintf("Goal '%s' is behind schedule: save $%s a month to reach $%s by %s",
+			goal.Name, formatCents(p.RequiredMonthlyCents), formatCents(goal.TargetCents), goal.DueDate.Format("2006-01-02"))
+		n := &models.Notification{
+			UserID:    int64(goal.UserID),
+			Type:      models.NotificationTypeGoal,
+			Title:     "Goal behind schedule",
+			Message:   msg,
+			Read:      false,
+			CreatedAt: time.Now(),
+		}
+		if err := notificationStore.CreateNotification(ctx, n); err != nil {
+			return err
+		}
+	}
+	return goalStore.SetProjectionStatus(goal, p.Status)
+}
+
+// GoalProjectionSweepJob runs GoalProjectionJob for every goal with a due
+// date.
+func GoalProjectionSweepJob(ctx context.Context, goalStore *db.GoalStore, notificationStore *db.NotificationStore) error {
+	goals, err := goalStore.ListWithDueDate()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range goals {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := GoalProjectionJob(ctx, goalStore, notificationStore, &goals[i]); err != nil {
+			slog.Error("goal projection failed", "goal_id", goals[i].ID, "error", err)
+			errs = append(errs, fmt.Errorf("goal %d: %w", goals[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -10,7 +10,7 @@ import (
 // ImportSyncFailureJob is a stub for future import/sync failure notifications
 func ImportSyncFailureJob(ctx context.Context, notificationStore *db.NotificationStore, userID uint, details string) error {
 	n := &models.Notification{
-		UserID:  userID,
+		UserID:  int64(userID),
 		Type:    models.NotificationType("import_sync_failure"),
 		Message: "Import/Sync failure: " + details,
 		Read:    false,
@@ -71,7 +71,7 @@ func EvaluateInvestmentAlertsJob(ctx context.Context, dbStore *db.Store) error {
 func EvaluateAlertCondition(ctx context.Context, dbStore *db.Store, alert models.InvestmentAlert) (bool, string) {
 	// If compound condition is present, evaluate recursively
 	if alert.Compound != nil {
-		return evaluateCompoundCondition(ctx, dbStore, *alert.Compound)
+		return evaluateCompoundCondition(ctx, dbStore, alert.UserID, *alert.Compound)
 	}
 	// Example: Trigger if price crosses threshold (pseudo-code)
 	// In real implementation, fetch current price from market data provider
@@ -147,7 +147,7 @@ func EvaluateAlertCondition(ctx context.Context, dbStore *db.Store, alert models
 }
 
 // evaluateCompoundCondition recursively evaluates AND/OR logic for compound alert conditions
-func evaluateCompoundCondition(ctx context.Context, dbStore *db.Store, compound models.CompoundAlertCondition) (bool, string) {
+func evaluateCompoundCondition(ctx context.Context, dbStore *db.Store, userID uint, compound models.CompoundAlertCondition) (bool, string) {
 	if len(compound.Conditions) == 0 {
 		return false, ""
 	}
@@ -157,7 +157,7 @@ func evaluateCompoundCondition(ctx context.Context, dbStore *db.Store, compound
 		triggered = true
 		for _, cond := range compound.Conditions {
 			singleAlert := models.InvestmentAlert{
-				UserID:      cond.UserID,
+				UserID:      userID,
 				AssetSymbol: cond.AssetSymbol,
 				AlertType:   cond.AlertType,
 				Direction:   cond.Direction,
@@ -174,7 +174,7 @@ func evaluateCompoundCondition(ctx context.Context, dbStore *db.Store, compound
 	} else if compound.Operator == "OR" {
 		for _, cond := range compound.Conditions {
 			singleAlert := models.InvestmentAlert{
-				UserID:      cond.UserID,
+				UserID:      userID,
 				AssetSymbol: cond.AssetSymbol,
 				AlertType:   cond.AlertType,
 				Direction:   cond.Direction,
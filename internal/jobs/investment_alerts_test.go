@@ -3,7 +3,6 @@ package jobs_test
 import (
 	"context"
 	"testing"
-	"time"
 
 	"bookkeeper-backend/internal/jobs"
 	"bookkeeper-backend/internal/models"
@@ -2,38 +2,77 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 	"bookkeeper-backend/internal/db"
 	"bookkeeper-backend/internal/models"
 )
 
-// LowBalanceJob checks for accounts below threshold and creates notifications
+// LowBalanceJob notifies the user of each asset account whose balance fell
+// below their threshold. An account is alerted once per drop: the alert is
+// cleared when the balance recovers. Credit cards, loans and other
+// liabilities are skipped.
 func LowBalanceJob(ctx context.Context, accountStore *db.AccountStore, userSettingsStore *db.UserSettingsStore, notificationStore *db.NotificationStore, userID uint) error {
 	accounts, err := accountStore.ListByUser(userID)
 	if err != nil {
 		return err
 	}
-	settings, _ := userSettingsStore.GetByUserID(userID)
-	threshold := int64(10000) // $100 default
-	if settings != nil && settings.LowBalanceThreshold > 0 {
-		threshold = settings.LowBalanceThreshold
+	balances, err := accountStore.Balances(accounts)
+	if err != nil {
+		return err
 	}
+	threshold := userSettingsStore.LowBalanceThreshold(userID)
 	for _, acc := range accounts {
-		if acc.BalanceCents < threshold {
-			msg := "Account '" + acc.Name + "' balance low: $" + formatCents(acc.BalanceCents)
-			n := &models.Notification{
-				UserID:  userID,
-				Type:    models.NotificationType("low_balance"),
-				Message: msg,
-				Read:    false,
-				CreatedAt: time.Now(),
+		if acc.IsLiability() {
+			continue
+		}
+		balance := balances[acc.ID]
+		if balance >= threshold {
+			if err := accountStore.ClearLowBalanceAlert(userID, acc.ID); err != nil {
+				return err
 			}
-			notificationStore.CreateNotification(ctx, n)
+			continue
+		}
+		claimed, err := accountStore.ClaimLowBalanceAlert(userID, acc.ID, balance)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		msg := "Account '" + acc.Name + "' balance low: $" + formatCents(balance)
+		n := &models.Notification{
+			UserID:  int64(userID),
+			Type:    models.NotificationType("low_balance"),
+			Message: msg,
+			Read:    false,
+			CreatedAt: time.Now(),
+		}
+		if err := notificationStore.CreateNotification(ctx, n); err != nil {
+			// Release the claim so the next run tries again.
+			return errors.Join(err, accountStore.ClearLowBalanceAlert(userID, acc.ID))
 		}
 	}
 	return nil
 }
 
-func formatCents(cents int64) string {
-	return fmt.Sprintf("%.2f", float64(cents)/100)
+// LowBalanceSweepJob runs LowBalanceJob for every user.
+func LowBalanceSweepJob(ctx context.Context, accountStore *db.AccountStore, userSettingsStore *db.UserSettingsStore, userStore *db.UserStore, notificationStore *db.NotificationStore) error {
+	users, err := userStore.ListIDs()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, userID := range users {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := LowBalanceJob(ctx, accountStore, userSettingsStore, notificationStore, userID); err != nil {
+			slog.Error("low balance check failed", "user_id", userID, "error", err)
+			errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/scheduler"
+
+	"gorm.io/gorm"
+)
+
+// jobRunRetention is how long the scheduler's run history is kept.
+const jobRunRetention = 30 * 24 * time.Hour
+
+// Scheduled returns the server's background jobs and when they run (UTC).
+// Jobs that take a single user or household are swept over all of them; a
+// sweep logs each failure and carries on, returning the failures joined.
+func Scheduled(sqlDB *sql.DB, gdb *gorm.DB) []scheduler.Job {
+	notifications := &db.NotificationStore{DB: sqlDB}
+	users := &db.UserStore{DB: gdb}
+	households := &db.HouseholdStore{DB: gdb}
+	bills := &db.BillStore{DB: gdb}
+	goals := &db.GoalStore{DB: gdb}
+	alerts := &db.BudgetAlertStore{DB: gdb}
+	scheduled := &db.ScheduledTransactionStore{DB: gdb}
+	store := &db.Store{
+		InvestmentAlertStore: &db.InvestmentAlertStore{DB: sqlDB},
+		UserStore:            users,
+		NotificationStore:    notifications,
+		AlertHistoryStore:    &db.AlertHistoryStore{DB: sqlDB},
+	}
+
+	return []scheduler.Job{
+		{
+			Name:     "scheduled_transactions",
+			Schedule: "*/15 * * * *",
+			Timeout:  5 * time.Minute,
+			Run: func(ctx context.Context) error {
+				posted, err := ScheduledTransactionJob(ctx, scheduled, notifications)
+				errs := []error{err}
+				// New postings can cross budget thresholds and goal milestones,
+				// even when other templates failed.
+				month := time.Now().UTC().Format("2006-01")
+				for _, hID := range posted {
+					errs = append(errs, BudgetAlertJob(ctx, alerts, notifications, hID, month))
+					errs = append(errs, HouseholdGoalsJob(ctx, goals, notifications, hID))
+				}
+				return errors.Join(errs...)
+			},
+		},
+		{
+			Name:     "bill_reminders",
+			Schedule: "0 8 * * *",
+			Run: func(ctx context.Context) error {
+				return BillReminderSweepJob(ctx, bills, households, notifications)
+			},
+		},
+		{
+			Name:     "low_balance",
+			Schedule: "0 7 * * *",
+			Run: func(ctx context.Context) error {
+				return LowBalanceSweepJob(ctx, &db.AccountStore{DB: gdb}, &db.UserSettingsStore{DB: gdb}, users, notifications)
+			},
+		},
+		{
+			Name:     "uncategorized_transactions",
+			Schedule: "0 9 * * 1",
+			Run: func(ctx context.Context) error {
+				return UncategorizedTxSweepJob(ctx, &db.TransactionStore{DB: gdb}, users, notifications)
+			},
+		},
+		{
+			Name:     "investment_alerts",
+			Schedule: "*/30 * * * *",
+			Run: func(ctx context.Context) error {
+				return EvaluateInvestmentAlertsJob(ctx, store)
+			},
+		},
+		{
+			Name:     "budget_alerts",
+			Schedule: "5 * * * *",
+			Run: func(ctx context.Context) error {
+				return BudgetAlertSweepJob(ctx, alerts, notifications)
+			},
+		},
+		{
+			Name:     "goal_milestones",
+			Schedule: "0 6 * * *",
+			Run: func(ctx context.Context) error {
+				return GoalMilestoneSweepJob(ctx, goals, households, notifications)
+			},
+		},
+		{
+			Name:     "goal_projections",
+			Schedule: "15 6 * * *",
+			Run: func(ctx context.Context) error {
+				return GoalProjectionSweepJob(ctx, goals, notifications)
+			},
+		},
+		{
+			Name:     "subscriptions",
+			Schedule: "0 3 * * *",
+			Timeout:  15 * time.Minute,
+			Run: func(ctx context.Context) error {
+				return SubscriptionSweepJob(ctx, &db.SubscriptionStore{DB: gdb}, households, notifications)
+			},
+		},
+		{
+			Name:     "job_run_cleanup",
+			Schedule: "30 4 * * *",
+			Run: func(ctx context.Context) error {
+				runs := &db.JobRunStore{DB: gdb}
+				return runs.Prune(time.Now().Add(-jobRunRetention))
+			},
+		},
+	}
+}
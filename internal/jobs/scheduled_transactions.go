@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+)
+
+// ScheduledTransactionJob posts every scheduled transaction occurrence that
+// has come due, across all households. A template whose account was closed
+// is ended and its owner notified. A template that fails is logged and the
+// rest still run; the failures are returned joined. It also returns the
+// households that received transactions so callers can re-evaluate them.
+func ScheduledTransactionJob(ctx context.Context, scheduledStore *db.ScheduledTransactionStore, notificationStore *db.NotificationStore) ([]uint, error) {
+	now := time.Now()
+	due, err := scheduledStore.ListDue(now)
+	if err != nil {
+		return nil, err
+	}
+	var households []uint
+	var errs []error
+	seen := map[uint]bool{}
+	for i := range due {
+		if err := ctx.Err(); err != nil {
+			return households, errors.Join(append(errs, err)...)
+		}
+		st := &due[i]
+		posted, err := scheduledStore.Materialize(st, now)
+		if len(posted) > 0 && !seen[st.HouseholdID] {
+			seen[st.HouseholdID] = true
+			households = append(households, st.HouseholdID)
+		}
+		switch {
+		case errors.Is(err, db.ErrAccountClosed):
+			n := &models.Notification{
+				UserID:    int64(st.UserID),
+				Type:      models.NotificationTypeTransaction,
+				Title:     "Scheduled transaction ended",
+				Message:   fmt.Sprintf("'%s' ($%s) stopped posting because its account is closed", st.Memo, formatCents(st.AmountCents)),
+				Read:      false,
+				CreatedAt: time.Now(),
+			}
+			if err := notificationStore.CreateNotification(ctx, n); err != nil {
+				errs = append(errs, fmt.Errorf("scheduled transaction %d: %w", st.ID, err))
+			}
+		case errors.Is(err, db.ErrOccurrenceHandled):
+			// Another run posted it first.
+		case err != nil:
+			slog.Error("scheduled transaction failed", "scheduled_transaction_id", st.ID, "error", err)
+			errs = append(errs, fmt.Errorf("scheduled transaction %d: %w", st.ID, err))
+		}
+	}
+	return households, errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+)
+
+// SubscriptionDetectionJob scans a household's transactions for periodic
+// charges and notifies every member of newly proposed subscriptions and of
+// price increases on known ones. Each charge is only reported once.
+func SubscriptionDetectionJob(ctx context.Context, subscriptionStore *db.SubscriptionStore, householdStore *db.HouseholdStore, notificationStore *db.NotificationStore, householdID uint) error {
+	created, increased, err := subscriptionStore.Sync(householdID, time.Now())
+	if err != nil || len(created)+len(increased) == 0 {
+		return err
+	}
+	var notes []*models.Notification
+	if len(created) == 1 {
+		c := created[0]
+		notes = append(notes, &models.Notification{
+			Title:   "Possible subscription",
+			Message: fmt.Sprintf("'%s' looks like a %s subscription of $%s. Confirm it to track it as a bill.", c.Memo, c.Interval, formatCents(c.AmountCents)),
+		})
+	} else if len(created) > 1 {
+		notes = append(notes, &models.Notification{
+			Title:   "Possible subscriptions",
+			Message: fmt.Sprintf("%d charges look like subscriptions. Confirm them to track them as bills.", len(created)),
+		})
+	}
+	for _, c := range increased {
+		notes = append(notes, &models.Notification{
+			Title: "Subscription price increase",
+			Message: fmt.Sprintf("'%s' went up from $%s to $%s per %s charge",
+				c.Memo, formatCents(c.PreviousAmountCents), formatCents(c.AmountCents), c.Interval),
+		})
+	}
+
+	members, err := householdStore.MemberIDs(householdID)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		for _, userID := range members {
+			n := &models.Notification{
+				UserID:    int64(userID),
+				Type:      models.NotificationTypeSubscription,
+				Title:     note.Title,
+				Message:   note.Message,
+				Read:      false,
+				CreatedAt: time.Now(),
+			}
+			if err := notificationStore.CreateNotification(ctx, n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SubscriptionSweepJob runs SubscriptionDetectionJob for every household.
+func SubscriptionSweepJob(ctx context.Context, subscriptionStore *db.SubscriptionStore, householdStore *db.HouseholdStore, notificationStore *db.NotificationStore) error {
+	households, err := householdStore.ListIDs()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, hID := range households {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := SubscriptionDetectionJob(ctx, subscriptionStore, householdStore, notificationStore, hID); err != nil {
+			slog.Error("subscription detection failed", "household_id", hID, "error", err)
+			errs = append(errs, fmt.Errorf("household %d: %w", hID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,32 +2,56 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 	"bookkeeper-backend/internal/db"
 	"bookkeeper-backend/internal/models"
 )
 
-// UncategorizedTxJob finds transactions older than 3 days without a category and notifies the user
+// UncategorizedTxJob sends the user one notification about their
+// transactions older than 3 days without a category. Each transaction is only
+// mentioned once; it is recorded after the notification is sent.
 func UncategorizedTxJob(ctx context.Context, txStore *db.TransactionStore, notificationStore *db.NotificationStore, userID uint) error {
 	cutoff := time.Now().AddDate(0, 0, -3)
 	txs, err := txStore.ListUncategorizedBefore(userID, cutoff)
-	if err != nil {
+	if err != nil || len(txs) == 0 {
 		return err
 	}
-	for _, tx := range txs {
-		msg := "Uncategorized transaction: $" + formatCents(tx.AmountCents) + " on " + tx.OccurredAt.Format("2006-01-02")
-		n := &models.Notification{
-			UserID:  userID,
-			Type:    models.NotificationType("uncategorized_tx"),
-			Message: msg,
-			Read:    false,
-			CreatedAt: time.Now(),
-		}
-		notificationStore.CreateNotification(ctx, n)
+	msg := "Uncategorized transaction: $" + formatCents(txs[0].AmountCents) + " on " + txs[0].OccurredAt.Format("2006-01-02")
+	if len(txs) > 1 {
+		msg = fmt.Sprintf("%d transactions older than 3 days have no category", len(txs))
+	}
+	n := &models.Notification{
+		UserID:    int64(userID),
+		Type:      models.NotificationType("uncategorized_tx"),
+		Title:     "Uncategorized transactions",
+		Message:   msg,
+		Read:      false,
+		CreatedAt: time.Now(),
 	}
-	return nil
+	if err := notificationStore.CreateNotification(ctx, n); err != nil {
+		return err
+	}
+	return txStore.RecordUncategorizedReminders(txs)
 }
 
-func formatCents(cents int64) string {
-	return fmt.Sprintf("%.2f", float64(cents)/100)
+// UncategorizedTxSweepJob runs UncategorizedTxJob for every user.
+func UncategorizedTxSweepJob(ctx context.Context, txStore *db.TransactionStore, userStore *db.UserStore, notificationStore *db.NotificationStore) error {
+	users, err := userStore.ListIDs()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, userID := range users {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := UncategorizedTxJob(ctx, txStore, notificationStore, userID); err != nil {
+			slog.Error("uncategorized reminder failed", "user_id", userID, "error", err)
+			errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+		}
+	}
+	return errors.Join(errs...)
 }
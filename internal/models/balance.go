@@ -0,0 +1,7 @@
+package models
+
+// BalancePoint is an account's closing balance at the end of a calendar day (UTC).
+type BalancePoint struct {
+	Date         string `json:"date"` // YYYY-MM-DD
+	BalanceCents int64  `json:"balance_cents"`
+}
@@ -2,14 +2,44 @@ package models
 
 import "time"
 
+// Bill is an expected payment. A recurring bill carries an iCalendar RRULE
+// anchored at StartsOn; NextDue is the next unpaid occurrence and advances
+// each time the bill is marked paid. A one-time bill is closed once paid.
 type Bill struct {
-	ID        uint      `gorm:"primaryKey"`
-	UserID    uint      `gorm:"index"`
-	Name      string    `gorm:"size:255"`
-	AmountCents int64   `gorm:"not null"`
-	DueDay    int       `gorm:"not null"` // Day of month
-	NextDue   time.Time `gorm:"not null"`
-	Recurring bool      `gorm:"not null"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"index"`
+	HouseholdID uint       `json:"household_id" gorm:"index"`
+	AccountID   *uint      `json:"account_id,omitempty"`
+	CategoryID  *uint      `json:"category_id,omitempty"`
+	Name        string     `json:"name" gorm:"size:255"`
+	AmountCents int64      `json:"amount_cents" gorm:"not null"`
+	DueDay      int        `json:"due_day" gorm:"not null"` // Day of month
+	NextDue     time.Time  `json:"next_due" gorm:"not null"`
+	Recurring   bool       `json:"recurring" gorm:"not null"`
+	RRule       string     `json:"rrule" gorm:"column:rrule;size:255"`
+	StartsOn    time.Time  `json:"starts_on"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// BillPayment links a paid occurrence of a bill to the transaction that
+// paid it.
+type BillPayment struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	BillID        uint      `json:"bill_id" gorm:"uniqueIndex:idx_bill_payments_due"`
+	DueDate       time.Time `json:"due_date" gorm:"uniqueIndex:idx_bill_payments_due"`
+	TransactionID uint      `json:"transaction_id" gorm:"uniqueIndex"`
+	UserID        uint      `json:"user_id"`
+	AmountCents   int64     `json:"amount_cents"`
+	PaidAt        time.Time `json:"paid_at"`
+}
+
+// BillReminder records that a bill's household was reminded of one of its
+// occurrences, so each due date is only reminded once.
+type BillReminder struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BillID    uint      `json:"bill_id" gorm:"uniqueIndex:idx_bill_reminders_due"`
+	DueDate   time.Time `json:"due_date" gorm:"uniqueIndex:idx_bill_reminders_due"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -1,9 +1,28 @@
 package models
 
+import "time"
+
 type Budget struct {
 	ID          uint   `json:"id" gorm:"primaryKey"`
 	HouseholdID uint   `json:"household_id"`
 	Month       string `json:"month"`
 	CategoryID  uint   `json:"category_id"`
 	PlannedCents int64 `json:"planned_cents"`
+	// Rollover carries this month's remaining (or overspent) amount into the
+	// category's available balance next month.
+	Rollover bool `json:"rollover"`
+}
+
+// BudgetMove records money reassigned from one category's envelope to
+// another's within a month.
+type BudgetMove struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	HouseholdID    uint      `json:"household_id" gorm:"index"`
+	Month          string    `json:"month" gorm:"size:7"`
+	FromCategoryID uint      `json:"from_category_id"`
+	ToCategoryID   uint      `json:"to_category_id"`
+	AmountCents    int64     `json:"amount_cents"`
+	UserID         uint      `json:"user_id"`
+	Memo           string    `json:"memo"`
+	CreatedAt      time.Time `json:"created_at"`
 }
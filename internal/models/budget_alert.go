@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// DefaultBudgetAlertThresholds are the spending percentages of a category's
+// planned amount that trigger an alert when a household has not chosen its own.
+var DefaultBudgetAlertThresholds = []int{80, 100, 120}
+
+// BudgetAlert records that a category's spending crossed a threshold in a
+// month, so each threshold is only ever notified once.
+type BudgetAlert struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	HouseholdID  uint      `json:"household_id" gorm:"uniqueIndex:idx_budget_alerts_threshold"`
+	CategoryID   uint      `json:"category_id" gorm:"uniqueIndex:idx_budget_alerts_threshold"`
+	Month        string    `json:"month" gorm:"size:7;uniqueIndex:idx_budget_alerts_threshold"`
+	Threshold    int       `json:"threshold" gorm:"uniqueIndex:idx_budget_alerts_threshold"`
+	SpentCents   int64     `json:"spent_cents"`
+	PlannedCents int64     `json:"planned_cents"`
+	CreatedAt    time.Time `json:"created_at"`
+}
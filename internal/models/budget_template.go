@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// BudgetTemplate is a saved set of planned amounts that can be applied to
+// any month.
+type BudgetTemplate struct {
+	ID          uint                 `json:"id" gorm:"primaryKey"`
+	HouseholdID uint                 `json:"household_id" gorm:"index"`
+	Name        string               `json:"name" gorm:"size:255"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+	Lines       []BudgetTemplateLine `json:"lines" gorm:"foreignKey:TemplateID"`
+}
+
+type BudgetTemplateLine struct {
+	ID           uint  `json:"id" gorm:"primaryKey"`
+	TemplateID   uint  `json:"template_id" gorm:"index"`
+	CategoryID   uint  `json:"category_id"`
+	PlannedCents int64 `json:"planned_cents"`
+	Rollover     bool  `json:"rollover"`
+}
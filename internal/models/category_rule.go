@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// CategoryRule assigns CategoryID to transactions that match every set
+// condition. Amount bounds are inclusive and signed like AmountCents
+// (expenses are negative). Rules run in ascending Priority, first match wins.
+type CategoryRule struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	HouseholdID    uint      `json:"household_id" gorm:"index"`
+	Name           string    `json:"name" gorm:"size:255"`
+	Priority       int       `json:"priority"`
+	MemoContains   string    `json:"memo_contains"`
+	MemoRegex      string    `json:"memo_regex"`
+	MinAmountCents *int64    `json:"min_amount_cents"`
+	MaxAmountCents *int64    `json:"max_amount_cents"`
+	AccountID      *uint     `json:"account_id"`
+	CategoryID     uint      `json:"category_id"`
+	Enabled        bool      `json:"enabled"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Duplicate suspect statuses
+const (
+	DuplicatePending   = "pending"
+	DuplicateMerged    = "merged"
+	DuplicateDismissed = "dismissed"
+)
+
+// DuplicateSuspect records that TransactionID looks like a second copy of
+// DuplicateOfID (same account, similar amount, date and memo).
+type DuplicateSuspect struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	HouseholdID   uint         `json:"household_id" gorm:"index"`
+	TransactionID uint         `json:"transaction_id"`
+	DuplicateOfID uint         `json:"duplicate_of_id"`
+	Score         float64      `json:"score"`
+	Status        string       `json:"status" gorm:"size:16"`
+	CreatedAt     time.Time    `json:"created_at"`
+	ResolvedAt    *time.Time   `json:"resolved_at"`
+	Transaction   *Transaction `json:"transaction,omitempty" gorm:"foreignKey:TransactionID"`
+	DuplicateOf   *Transaction `json:"duplicate_of,omitempty" gorm:"foreignKey:DuplicateOfID"`
+}
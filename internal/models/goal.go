@@ -2,13 +2,40 @@ package models
 
 import "time"
 
+// DefaultGoalMilestones are the progress percentages notified when a goal
+// has no milestones of its own.
+var DefaultGoalMilestones = []int{50, 100}
+
+// Goal is a user's savings target. A goal linked to an account tracks that
+// account's balance; one linked to a category tracks what has been put into
+// the category since the goal was created. Unlinked goals are updated by hand.
 type Goal struct {
-	ID        uint      `gorm:"primaryKey"`
-	UserID    uint      `gorm:"index"`
-	Name      string    `gorm:"size:255"`
-	TargetCents int64   `gorm:"not null"`
-	CurrentCents int64  `gorm:"not null"`
-	DueDate   *time.Time
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	UserID       uint   `json:"user_id" gorm:"index"`
+	HouseholdID  *uint  `json:"household_id,omitempty" gorm:"index"`
+	AccountID    *uint  `json:"account_id,omitempty"`
+	CategoryID   *uint  `json:"category_id,omitempty"`
+	Name         string `json:"name" gorm:"size:255"`
+	TargetCents  int64  `json:"target_cents" gorm:"not null"`
+	CurrentCents int64  `json:"current_cents" gorm:"not null"`
+	// Milestones is a comma-separated list of progress percentages; empty
+	// means DefaultGoalMilestones.
+	Milestones string     `json:"-" gorm:"size:64"`
+	DueDate    *time.Time `json:"due_date"`
+	// ProjectionStatus is the last on_track/behind status notified, so a
+	// goal falling behind is reported once rather than on every run.
+	ProjectionStatus string          `json:"projection_status,omitempty" gorm:"size:16"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	Reached          []GoalMilestone `json:"milestones_reached,omitempty" gorm:"foreignKey:GoalID"`
+}
+
+// GoalMilestone records the first time a goal reached a milestone, so each
+// milestone is notified once.
+type GoalMilestone struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	GoalID       uint      `json:"goal_id" gorm:"uniqueIndex:idx_goal_milestones_percent"`
+	Percent      int       `json:"percent" gorm:"uniqueIndex:idx_goal_milestones_percent"`
+	CurrentCents int64     `json:"current_cents"`
+	ReachedAt    time.Time `json:"reached_at"`
 }
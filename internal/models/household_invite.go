@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Household member roles. See internal/permissions for what each may do.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// HouseholdInvite is a single-use, expiring invitation for Email to join a
+// household. Only the SHA-256 of the token is stored.
+type HouseholdInvite struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	HouseholdID uint       `json:"household_id" gorm:"index"`
+	InvitedBy   uint       `json:"invited_by"`
+	Email       string     `json:"email" gorm:"size:255;index"`
+	Role        string     `json:"role" gorm:"size:32"`
+	TokenHash   string     `json:"-" gorm:"size:64;uniqueIndex"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	DeclinedAt  *time.Time `json:"declined_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Pending reports whether the invite can still be accepted or declined.
+func (i *HouseholdInvite) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.DeclinedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Sign conventions for single-column statement amounts
+const (
+	SignPositiveInflow  = "positive_inflow"  // deposits are positive (most bank accounts)
+	SignPositiveOutflow = "positive_outflow" // charges are positive (most credit cards)
+)
+
+// ImportProfile describes how a bank's CSV export maps onto transactions.
+// Column fields hold header names when HasHeader is set, otherwise 1-based
+// column positions ("1", "2", ...).
+type ImportProfile struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	HouseholdID    uint      `json:"household_id" gorm:"index"`
+	Name           string    `json:"name" gorm:"size:255"`
+	HasHeader      bool      `json:"has_header"`
+	Delimiter      string    `json:"delimiter" gorm:"size:1"`
+	DateColumn     string    `json:"date_column"`
+	DateFormat     string    `json:"date_format"` // e.g. YYYY-MM-DD, MM/DD/YYYY
+	AmountColumn   string    `json:"amount_column"`
+	DebitColumn    string    `json:"debit_column"`
+	CreditColumn   string    `json:"credit_column"`
+	MemoColumn     string    `json:"memo_column"`
+	SignConvention string    `json:"sign_convention" gorm:"size:32"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Job run statuses
+const (
+	JobRunRunning     = "running"
+	JobRunSucceeded   = "succeeded"
+	JobRunFailed      = "failed"
+	JobRunTimedOut    = "timed_out"
+	JobRunCancelled   = "cancelled"
+	JobRunInterrupted = "interrupted"
+)
+
+// JobRun is one execution of a scheduled background job. Runs still marked
+// running when the server starts were interrupted by a crash or restart.
+type JobRun struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Job        string     `json:"job" gorm:"size:64;index:idx_job_runs_job_started"`
+	Status     string     `json:"status" gorm:"size:16"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at" gorm:"index:idx_job_runs_job_started"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMs int64      `json:"duration_ms"`
+}
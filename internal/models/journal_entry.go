@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// AccountTypeExternal marks the per-household counterparty account that
+// balances income and expenses. It is hidden from account listings.
+const AccountTypeExternal = "external"
+
+// Journal entry kinds
+const (
+	EntryKindStandard = "standard" // income/expense against the external account
+	EntryKindTransfer = "transfer" // money moved between two household accounts
+	EntryKindCompound = "compound" // arbitrary balanced legs (splits, card payments with fees)
+)
+
+// JournalEntry is a single financial event. Its Lines are the per-account
+// legs (stored as transactions) and always sum to zero.
+type JournalEntry struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	HouseholdID uint          `json:"household_id" gorm:"index"`
+	UserID      *uint         `json:"user_id"`
+	Kind        string        `json:"kind" gorm:"size:32"`
+	Memo        string        `json:"memo"`
+	OccurredAt  time.Time     `json:"occurred_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Lines       []Transaction `json:"lines" gorm:"foreignKey:EntryID"`
+}
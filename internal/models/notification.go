@@ -12,6 +12,8 @@ const (
 	NotificationTypeTransaction       NotificationType = "transaction"
 	NotificationTypeGoal              NotificationType = "goal"
 	NotificationTypeInvestmentAlert   NotificationType = "investment_alert"
+	NotificationTypeHouseholdInvite   NotificationType = "household_invite"
+	NotificationTypeSubscription      NotificationType = "subscription"
 )
 
 // Notification represents a user notification/alert
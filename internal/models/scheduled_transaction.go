@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// ScheduledTransaction is a template that posts a transaction on every
+// occurrence of its RRULE, anchored at StartsOn, until EndsOn. NextDue is the
+// next occurrence still to post. The Next* fields override that one
+// occurrence only and are cleared once it is posted or skipped. A transfer
+// sets CounterAccountID; otherwise the leg is balanced against the
+// household's external account.
+type ScheduledTransaction struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	HouseholdID      uint       `json:"household_id" gorm:"index"`
+	UserID           uint       `json:"user_id"`
+	AccountID        uint       `json:"account_id"`
+	CounterAccountID *uint      `json:"counter_account_id,omitempty"`
+	CategoryID       *uint      `json:"category_id,omitempty"`
+	AmountCents      int64      `json:"amount_cents"`
+	Currency         string     `json:"currency" gorm:"size:8"`
+	Memo             string     `json:"memo" gorm:"size:255"`
+	RRule            string     `json:"rrule" gorm:"column:rrule;size:255"`
+	StartsOn         time.Time  `json:"starts_on"`
+	EndsOn           *time.Time `json:"ends_on,omitempty"`
+	NextDue          time.Time  `json:"next_due" gorm:"index"`
+	NextDate         *time.Time `json:"next_date,omitempty"`
+	NextAmountCents  *int64     `json:"next_amount_cents,omitempty"`
+	NextMemo         *string    `json:"next_memo,omitempty"`
+	ClosedAt         *time.Time `json:"closed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// ScheduledOccurrence records what happened to one occurrence of a
+// scheduled transaction: the entry it posted, or that it was skipped.
+type ScheduledOccurrence struct {
+	ID                     uint      `json:"id" gorm:"primaryKey"`
+	ScheduledTransactionID uint      `json:"scheduled_transaction_id" gorm:"uniqueIndex:idx_scheduled_occurrences_due"`
+	DueDate                time.Time `json:"due_date" gorm:"uniqueIndex:idx_scheduled_occurrences_due"`
+	EntryID                *uint     `json:"entry_id,omitempty"`
+	Skipped                bool      `json:"skipped"`
+	CreatedAt              time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Subscription candidate statuses
+const (
+	SubscriptionPending   = "pending"
+	SubscriptionConfirmed = "confirmed"
+	SubscriptionDismissed = "dismissed"
+)
+
+// SubscriptionCandidate is a periodic charge found in a household's history:
+// the same payee, a similar amount and a regular interval. Confirming it
+// creates a Bill; dismissed candidates stay so they are not proposed again.
+type SubscriptionCandidate struct {
+	ID                  uint      `json:"id" gorm:"primaryKey"`
+	HouseholdID         uint      `json:"household_id" gorm:"uniqueIndex:idx_subscription_candidates_payee"`
+	Payee               string    `json:"payee" gorm:"size:255;uniqueIndex:idx_subscription_candidates_payee"`
+	Memo                string    `json:"memo" gorm:"size:255"`
+	AccountID           uint      `json:"account_id"`
+	CategoryID          *uint     `json:"category_id"`
+	Interval            string    `json:"interval" gorm:"size:16"`
+	AmountCents         int64     `json:"amount_cents"`
+	PreviousAmountCents int64     `json:"previous_amount_cents"`
+	Occurrences         int       `json:"occurrences"`
+	LastTransactionID   uint      `json:"last_transaction_id"`
+	LastChargedAt       time.Time `json:"last_charged_at"`
+	NextExpected        time.Time `json:"next_expected"`
+	Status              string    `json:"status" gorm:"size:16"`
+	BillID              *uint     `json:"bill_id"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
@@ -2,8 +2,11 @@ package models
 
 import "time"
 
+// Transaction is one leg of a JournalEntry posted to a single account.
+// AmountCents is signed relative to the account (positive = inflow).
 type Transaction struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
+	EntryID     uint      `json:"entry_id" gorm:"index"`
 	AccountID   uint      `json:"account_id"`
 	UserID      *uint     `json:"user_id"`
 	AmountCents int64     `json:"amount_cents"`
@@ -11,4 +14,18 @@ type Transaction struct {
 	CategoryID  *uint     `json:"category_id"`
 	Memo        string    `json:"memo"`
 	OccurredAt  time.Time `json:"occurred_at"`
+	// ExternalID is the bank's identifier (OFX FITID) for imported legs.
+	ExternalID *string `json:"external_id,omitempty" gorm:"size:255"`
+	// Splits, when present, replace CategoryID with per-category allocations.
+	Splits []TransactionSplit `json:"splits,omitempty" gorm:"foreignKey:TransactionID"`
+	// DuplicateOf lists existing legs this one was flagged against when it was created.
+	DuplicateOf []uint `json:"duplicate_of,omitempty" gorm:"-"`
+}
+
+// UncategorizedReminder records that a leg's user was reminded to categorize
+// it, so each leg is only reminded once.
+type UncategorizedReminder struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	TransactionID uint      `json:"transaction_id" gorm:"uniqueIndex"`
+	CreatedAt     time.Time `json:"created_at"`
 }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Transaction audit actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// TransactionAudit records one change to a transaction leg. Changes is a
+// JSON object of field -> [old, new]; deletes store the removed leg as old.
+type TransactionAudit struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	HouseholdID   uint      `json:"household_id" gorm:"index"`
+	TransactionID uint      `json:"transaction_id" gorm:"index"`
+	UserID        uint      `json:"user_id"`
+	Action        string    `json:"action" gorm:"size:16"`
+	Changes       string    `json:"changes" gorm:"type:text"`
+	CreatedAt     time.Time `json:"created_at"`
+}
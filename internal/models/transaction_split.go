@@ -0,0 +1,11 @@
+package models
+
+// TransactionSplit allocates part of a transaction leg to a category. A
+// split leg has no CategoryID of its own and its splits sum to AmountCents.
+type TransactionSplit struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	TransactionID uint   `json:"transaction_id" gorm:"index"`
+	CategoryID    uint   `json:"category_id" gorm:"index"`
+	AmountCents   int64  `json:"amount_cents"`
+	Memo          string `json:"memo"`
+}
@@ -33,6 +33,9 @@ type Household struct {
 	ID        uint      `gorm:"primaryKey"`
 	Name      string    `gorm:"size:255;not null"`
 	CreatedBy uint      `gorm:"index"`
+	// BudgetAlertThresholds is a comma-separated list of spending percentages
+	// that trigger budget alerts; empty means DefaultBudgetAlertThresholds.
+	BudgetAlertThresholds string `gorm:"size:64"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Members   []HouseholdMember
@@ -53,11 +56,33 @@ type Account struct {
 	Type                string     `gorm:"size:32"`
 	Currency            string     `gorm:"size:8"`
 	OpeningBalanceCents int64
+	BankAccountID       string `gorm:"size:64"` // the bank's ACCTID; OFX files for other accounts are rejected
 	ArchivedAt          *time.Time
+	ClosedAt            *time.Time // closed accounts are also archived and accept no new legs
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
 }
 
+// liabilityAccountTypes hold money owed, so their balance is normally at or
+// below zero.
+var liabilityAccountTypes = map[string]bool{"credit": true, "credit_card": true, "loan": true, "mortgage": true, "line_of_credit": true}
+
+// IsLiability reports whether the account tracks money owed rather than
+// money held.
+func (a *Account) IsLiability() bool {
+	return liabilityAccountTypes[a.Type]
+}
+
+// LowBalanceAlert records that a user was told an account ran low. It is
+// removed once the balance recovers, so each drop is only alerted once.
+type LowBalanceAlert struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"uniqueIndex:idx_low_balance_alerts_account"`
+	AccountID    uint      `json:"account_id" gorm:"uniqueIndex:idx_low_balance_alerts_account"`
+	BalanceCents int64     `json:"balance_cents"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 type Category struct {
 	ID          uint      `gorm:"primaryKey"`
 	HouseholdID uint      `gorm:"index"`
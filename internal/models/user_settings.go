@@ -11,5 +11,6 @@ type UserSettings struct {
 	ID                        uint                   `gorm:"primaryKey"`
 	UserID                    uint                   `gorm:"index;unique"`
 	LargeTransactionThreshold int64                  `gorm:"default:10000"`
+	LowBalanceThreshold       int64                  `gorm:"default:10000"`
 	NotificationPreferences   NotificationPreferences `gorm:"-" json:"notification_preferences,omitempty"`
 }
@@ -0,0 +1,65 @@
+// Package permissions defines what each household role is allowed to do.
+// Handlers look up the caller's role and ask Can before acting.
+package permissions
+
+import "bookkeeper-backend/internal/models"
+
+// Action is something a household member may attempt.
+type Action string
+
+const (
+	// View covers every read: accounts, balances, transactions, budgets, reports.
+	View Action = "view"
+	// EditTransactions covers posting, editing and deleting transactions,
+	// splits, journal entries, imports and duplicate resolution.
+	EditTransactions Action = "edit_transactions"
+	// ManageAccounts covers creating, updating, archiving, closing and
+	// deleting accounts.
+	ManageAccounts Action = "manage_accounts"
+	// ManageCategories covers categories and categorization rules.
+	ManageCategories Action = "manage_categories"
+	// ManageBudgets covers creating, changing and deleting budgets.
+	ManageBudgets Action = "manage_budgets"
+	// ManageBills covers bills, their schedules and marking them paid.
+	ManageBills Action = "manage_bills"
+	// ManageMembers covers invites, removing members and role changes.
+	ManageMembers Action = "manage_members"
+)
+
+// legacyMember is the role households used before editor and viewer
+// existed; it keeps the full write access it always had.
+const legacyMember = "member"
+
+var matrix = map[string]map[Action]bool{
+	models.RoleOwner: {
+		View: true, EditTransactions: true, ManageAccounts: true,
+		ManageCategories: true, ManageBudgets: true, ManageBills: true,
+		ManageMembers: true,
+	},
+	models.RoleEditor: {
+		View: true, EditTransactions: true, ManageAccounts: true,
+		ManageCategories: true, ManageBudgets: true, ManageBills: true,
+	},
+	models.RoleViewer: {
+		View: true,
+	},
+}
+
+// Normalize maps legacy role names onto the current ones.
+func Normalize(role string) string {
+	if role == legacyMember {
+		return models.RoleEditor
+	}
+	return role
+}
+
+// Can reports whether role may perform action. Unknown roles may do nothing.
+func Can(role string, action Action) bool {
+	return matrix[Normalize(role)][action]
+}
+
+// Assignable reports whether role can be given through an invite or a role
+// change. Ownership only moves by transfer.
+func Assignable(role string) bool {
+	return role == models.RoleEditor || role == models.RoleViewer
+}
@@ -0,0 +1,46 @@
+package permissions
+
+import (
+	"testing"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestCan(t *testing.T) {
+	writes := []Action{EditTransactions, ManageAccounts, ManageCategories, ManageBudgets, ManageBills}
+	cases := []struct {
+		role    string
+		view    bool
+		write   bool
+		members bool
+	}{
+		{models.RoleOwner, true, true, true},
+		{models.RoleEditor, true, true, false},
+		{"member", true, true, false},
+		{models.RoleViewer, true, false, false},
+		{"", false, false, false},
+		{"admin", false, false, false},
+	}
+	for _, c := range cases {
+		if got := Can(c.role, View); got != c.view {
+			t.Errorf("%q view: got %v, want %v", c.role, got, c.view)
+		}
+		for _, a := range writes {
+			if got := Can(c.role, a); got != c.write {
+				t.Errorf("%q %s: got %v, want %v", c.role, a, got, c.write)
+			}
+		}
+		if got := Can(c.role, ManageMembers); got != c.members {
+			t.Errorf("%q manage members: got %v, want %v", c.role, got, c.members)
+		}
+	}
+}
+
+func TestAssignable(t *testing.T) {
+	if Assignable(models.RoleOwner) || Assignable("member") || Assignable("") {
+		t.Fatalf("only editor and viewer may be assigned")
+	}
+	if !Assignable(models.RoleEditor) || !Assignable(models.RoleViewer) {
+		t.Fatalf("editor and viewer must be assignable")
+	}
+}
@@ -0,0 +1,238 @@
+// Package recurrence implements the subset of iCalendar (RFC 5545) RRULEs
+// used for bills and scheduled transactions: daily, weekly (optionally on a
+// weekday), monthly on a day of the month, the last day or the last business
+// day, and yearly.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is an RRULE FREQ value.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// LastDay is the ByMonthDay value for the last day of the month.
+const LastDay = -1
+
+// maxSteps bounds the search for the next occurrence.
+const maxSteps = 100000
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Rule is a parsed recurrence rule. Occurrences are computed from an anchor
+// (DTSTART), which supplies the time of day and any day or month the rule
+// leaves open.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	// Weekday is the BYDAY of a weekly rule.
+	Weekday *time.Weekday
+	// ByMonthDay is 1..31 (clamped to short months) or LastDay; zero means
+	// the anchor's day.
+	ByMonthDay int
+	// ByMonth is the month of a yearly rule; zero means the anchor's month.
+	ByMonth time.Month
+	// LastBusinessDay selects the last Monday-to-Friday of the month
+	// (BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1).
+	LastBusinessDay bool
+}
+
+// Parse reads an RRULE such as "FREQ=MONTHLY;BYMONTHDAY=15" or
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR". A leading "RRULE:" is ignored.
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	r := Rule{Interval: 1}
+	var byDay string
+	var setPos int
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("%w: %q", ErrInvalidRule, part)
+		}
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && (r.Interval < 1 || r.Interval > 1000) {
+				err = ErrInvalidRule
+			}
+		case "BYDAY":
+			byDay = value
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = strconv.Atoi(value)
+			if err == nil && (r.ByMonthDay == 0 || r.ByMonthDay > 31 || r.ByMonthDay < LastDay) {
+				err = ErrInvalidRule
+			}
+		case "BYMONTH":
+			var m int
+			m, err = strconv.Atoi(value)
+			if err == nil && (m < 1 || m > 12) {
+				err = ErrInvalidRule
+			}
+			r.ByMonth = time.Month(m)
+		case "BYSETPOS":
+			setPos, err = strconv.Atoi(value)
+		default:
+			return Rule{}, fmt.Errorf("%w: unsupported %s", ErrInvalidRule, key)
+		}
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w: %s=%s", ErrInvalidRule, key, value)
+		}
+	}
+
+	switch r.Freq {
+	case Daily, Weekly, Monthly, Yearly:
+	case "":
+		return Rule{}, fmt.Errorf("%w: FREQ required", ErrInvalidRule)
+	default:
+		return Rule{}, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRule, r.Freq)
+	}
+	switch {
+	case byDay == "" && setPos == 0:
+	case r.Freq == Weekly && setPos == 0:
+		wd, ok := weekdays[byDay]
+		if !ok {
+			return Rule{}, fmt.Errorf("%w: weekly BYDAY must be a single day", ErrInvalidRule)
+		}
+		r.Weekday = &wd
+	case r.Freq == Monthly && byDay == "MO,TU,WE,TH,FR" && setPos == -1 && r.ByMonthDay == 0:
+		r.LastBusinessDay = true
+	default:
+		return Rule{}, fmt.Errorf("%w: unsupported BYDAY/BYSETPOS combination", ErrInvalidRule)
+	}
+	if r.ByMonthDay != 0 && r.Freq != Monthly && r.Freq != Yearly {
+		return Rule{}, fmt.Errorf("%w: BYMONTHDAY needs FREQ=MONTHLY or YEARLY", ErrInvalidRule)
+	}
+	if r.ByMonth != 0 && r.Freq != Yearly {
+		return Rule{}, fmt.Errorf("%w: BYMONTH needs FREQ=YEARLY", ErrInvalidRule)
+	}
+	return r, nil
+}
+
+// String returns the rule in canonical RRULE form.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Weekday != nil {
+		for code, wd := range weekdays {
+			if wd == *r.Weekday {
+				parts = append(parts, "BYDAY="+code)
+			}
+		}
+	}
+	if r.ByMonth != 0 {
+		parts = append(parts, "BYMONTH="+strconv.Itoa(int(r.ByMonth)))
+	}
+	if r.ByMonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	if r.LastBusinessDay {
+		parts = append(parts, "BYDAY=MO,TU,WE,TH,FR", "BYSETPOS=-1")
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after after. Occurrences before
+// the anchor are never returned. ok is false if none was found.
+func (r Rule) Next(anchor, after time.Time) (time.Time, bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	start := anchor
+	if r.Freq == Weekly && r.Weekday != nil {
+		start = anchor.AddDate(0, 0, (int(*r.Weekday)-int(anchor.Weekday())+7)%7)
+	}
+	for k := 0; k < maxSteps; k++ {
+		t := r.occurrence(start, k*interval)
+		if t.After(after) && !t.Before(anchor) {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Occurrences returns up to n occurrences after after, in order.
+func (r Rule) Occurrences(anchor, after time.Time, n int) []time.Time {
+	out := make([]time.Time, 0, n)
+	for len(out) < n {
+		t, ok := r.Next(anchor, after)
+		if !ok {
+			break
+		}
+		out = append(out, t)
+		after = t
+	}
+	return out
+}
+
+// occurrence returns the occurrence in the period step periods after start.
+func (r Rule) occurrence(start time.Time, step int) time.Time {
+	switch r.Freq {
+	case Daily:
+		return start.AddDate(0, 0, step)
+	case Weekly:
+		return start.AddDate(0, 0, 7*step)
+	case Yearly:
+		month := start.Month()
+		if r.ByMonth != 0 {
+			month = r.ByMonth
+		}
+		return r.dayInMonth(start, start.Year()+step, month)
+	default:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, start.Location())
+		return r.dayInMonth(start, first.Year(), first.Month())
+	}
+}
+
+// dayInMonth places the rule's day in year/month at start's time of day.
+func (r Rule) dayInMonth(start time.Time, year int, month time.Month) time.Time {
+	last := daysIn(year, month, start.Location())
+	day := start.Day()
+	switch {
+	case r.LastBusinessDay:
+		day = last
+		for {
+			wd := time.Date(year, month, day, 0, 0, 0, 0, start.Location()).Weekday()
+			if wd != time.Saturday && wd != time.Sunday {
+				break
+			}
+			day--
+		}
+	case r.ByMonthDay == LastDay:
+		day = last
+	case r.ByMonthDay > 0:
+		day = r.ByMonthDay
+	}
+	if day > last {
+		day = last
+	}
+	return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+}
+
+func daysIn(year int, month time.Month, loc *time.Location) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+}
@@ -0,0 +1,82 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+}
+
+func TestNextOccurrences(t *testing.T) {
+	cases := []struct {
+		rule   string
+		anchor time.Time
+		after  time.Time
+		want   []time.Time
+	}{
+		{"FREQ=WEEKLY", day(2025, 1, 6), day(2025, 1, 6), []time.Time{day(2025, 1, 13), day(2025, 1, 20)}},
+		// Biweekly on Fridays, anchored on a Wednesday.
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=FR", day(2025, 1, 1), day(2024, 12, 31), []time.Time{day(2025, 1, 3), day(2025, 1, 17)}},
+		// The 31st clamps to short months and comes back afterwards.
+		{"FREQ=MONTHLY", day(2025, 1, 31), day(2025, 1, 31), []time.Time{day(2025, 2, 28), day(2025, 3, 31)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=15", day(2025, 1, 20), day(2025, 1, 1), []time.Time{day(2025, 2, 15), day(2025, 3, 15)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", day(2024, 1, 1), day(2024, 1, 31), []time.Time{day(2024, 2, 29), day(2024, 3, 31)}},
+		// May 31 2025 is a Saturday; August 31 2025 a Sunday.
+		{"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", day(2025, 5, 1), day(2025, 5, 1), []time.Time{day(2025, 5, 30), day(2025, 6, 30)}},
+		{"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", day(2025, 8, 1), day(2025, 8, 1), []time.Time{day(2025, 8, 29)}},
+		{"FREQ=YEARLY;BYMONTH=4;BYMONTHDAY=15", day(2025, 1, 1), day(2025, 4, 15), []time.Time{day(2026, 4, 15), day(2027, 4, 15)}},
+		{"RRULE:FREQ=YEARLY", day(2024, 2, 29), day(2024, 2, 29), []time.Time{day(2025, 2, 28)}},
+	}
+	for _, c := range cases {
+		r, err := Parse(c.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", c.rule, err)
+		}
+		got := r.Occurrences(c.anchor, c.after, len(c.want))
+		if len(got) != len(c.want) {
+			t.Fatalf("%s: got %v, want %v", c.rule, got, c.want)
+		}
+		for i := range got {
+			if !got[i].Equal(c.want[i]) {
+				t.Errorf("%s: occurrence %d got %v, want %v", c.rule, i, got[i], c.want[i])
+			}
+		}
+	}
+}
+
+func TestParseRejectsUnsupported(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"FREQ=HOURLY",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=WEEKLY;BYDAY=MO,FR",
+		"FREQ=DAILY;BYMONTHDAY=3",
+		"FREQ=MONTHLY;BYMONTH=2",
+		"FREQ=MONTHLY;COUNT=3",
+		"FREQ=WEEKLY;INTERVAL=0",
+	} {
+		if _, err := Parse(s); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("%q: expected ErrInvalidRule, got %v", s, err)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=FR",
+		"FREQ=MONTHLY;BYMONTHDAY=-1",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"FREQ=YEARLY;BYMONTH=4;BYMONTHDAY=15",
+	} {
+		r, err := Parse(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if r.String() != s {
+			t.Errorf("got %q, want %q", r.String(), s)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package rules matches transactions against household categorization rules.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"bookkeeper-backend/internal/models"
+)
+
+// Validate checks that a rule has at least one condition and a usable regex.
+func Validate(r models.CategoryRule) error {
+	if r.CategoryID == 0 {
+		return errors.New("category_id required")
+	}
+	if strings.TrimSpace(r.MemoContains) == "" && r.MemoRegex == "" &&
+		r.MinAmountCents == nil && r.MaxAmountCents == nil && r.AccountID == nil {
+		return errors.New("rule needs at least one condition")
+	}
+	if r.MinAmountCents != nil && r.MaxAmountCents != nil && *r.MinAmountCents > *r.MaxAmountCents {
+		return errors.New("min_amount_cents exceeds max_amount_cents")
+	}
+	if r.MemoRegex != "" {
+		if _, err := regexp.Compile("(?i)" + r.MemoRegex); err != nil {
+			return fmt.Errorf("invalid memo_regex: %w", err)
+		}
+	}
+	return nil
+}
+
+type compiled struct {
+	rule     models.CategoryRule
+	contains string
+	re       *regexp.Regexp
+}
+
+// Engine holds a household's enabled rules in evaluation order.
+type Engine struct {
+	rules []compiled
+}
+
+// Compile prepares rules for matching. Disabled rules are dropped and the
+// rest are ordered by Priority, then ID.
+func Compile(rs []models.CategoryRule) (*Engine, error) {
+	e := &Engine{}
+	for _, r := range rs {
+		if !r.Enabled {
+			continue
+		}
+		c := compiled{rule: r, contains: strings.ToLower(strings.TrimSpace(r.MemoContains))}
+		if r.MemoRegex != "" {
+			re, err := regexp.Compile("(?i)" + r.MemoRegex)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", r.ID, err)
+			}
+			c.re = re
+		}
+		e.rules = append(e.rules, c)
+	}
+	sort.SliceStable(e.rules, func(i, j int) bool {
+		a, b := e.rules[i].rule, e.rules[j].rule
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.ID < b.ID
+	})
+	return e, nil
+}
+
+// Match returns the first rule that applies to tx, or nil.
+func (e *Engine) Match(tx *models.Transaction) *models.CategoryRule {
+	if e == nil {
+		return nil
+	}
+	memo := strings.ToLower(tx.Memo)
+	for i := range e.rules {
+		c := &e.rules[i]
+		r := &c.rule
+		if r.AccountID != nil && *r.AccountID != tx.AccountID {
+			continue
+		}
+		if r.MinAmountCents != nil && tx.AmountCents < *r.MinAmountCents {
+			continue
+		}
+		if r.MaxAmountCents != nil && tx.AmountCents > *r.MaxAmountCents {
+			continue
+		}
+		if c.contains != "" && !strings.Contains(memo, c.contains) {
+			continue
+		}
+		if c.re != nil && !c.re.MatchString(tx.Memo) {
+			continue
+		}
+		return r
+	}
+	return nil
+}
+
+// Apply sets tx.CategoryID from the first matching rule and reports whether
+// one matched. Already-categorized transactions are left alone.
+func (e *Engine) Apply(tx *models.Transaction) bool {
+	if tx.CategoryID != nil {
+		return false
+	}
+	r := e.Match(tx)
+	if r == nil {
+		return false
+	}
+	id := r.CategoryID
+	tx.CategoryID = &id
+	return true
+}
@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"testing"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestEngineMatchOrderAndConditions(t *testing.T) {
+	min, max := int64(-10000), int64(-1)
+	acct := uint(3)
+	e, err := Compile([]models.CategoryRule{
+		{ID: 1, Priority: 10, MemoContains: "coffee", CategoryID: 100, Enabled: true},
+		{ID: 2, Priority: 1, MemoRegex: `^(starbucks|peet'?s)\b`, MinAmountCents: &min, MaxAmountCents: &max, CategoryID: 200, Enabled: true},
+		{ID: 3, Priority: 0, AccountID: &acct, CategoryID: 300, Enabled: true},
+		{ID: 4, Priority: -5, MemoContains: "coffee", CategoryID: 400, Enabled: false},
+	})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	cases := []struct {
+		tx   models.Transaction
+		want uint
+	}{
+		{models.Transaction{AccountID: 1, AmountCents: -550, Memo: "STARBUCKS #123"}, 200},
+		{models.Transaction{AccountID: 1, AmountCents: -50000, Memo: "Starbucks coffee beans"}, 100},
+		{models.Transaction{AccountID: 3, AmountCents: -550, Memo: "STARBUCKS"}, 300},
+		{models.Transaction{AccountID: 1, AmountCents: 1000, Memo: "Payroll"}, 0},
+	}
+	for _, tc := range cases {
+		got := uint(0)
+		if r := e.Match(&tc.tx); r != nil {
+			got = r.CategoryID
+		}
+		if got != tc.want {
+			t.Errorf("%q: expected category %d, got %d", tc.tx.Memo, tc.want, got)
+		}
+	}
+
+	cat := uint(9)
+	tx := models.Transaction{Memo: "coffee", CategoryID: &cat}
+	if e.Apply(&tx) || *tx.CategoryID != 9 {
+		t.Error("Apply must not overwrite an existing category")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(models.CategoryRule{CategoryID: 1}); err == nil {
+		t.Error("expected error for rule without conditions")
+	}
+	if err := Validate(models.CategoryRule{CategoryID: 1, MemoRegex: "("}); err == nil {
+		t.Error("expected error for bad regex")
+	}
+	if err := Validate(models.CategoryRule{CategoryID: 1, MemoContains: "rent"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
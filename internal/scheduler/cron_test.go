@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func at(y int, m time.Month, d, h, min int) time.Time {
+	return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+}
+
+func TestScheduleNext(t *testing.T) {
+	cases := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"*/15 * * * *", at(2025, 6, 1, 10, 7), at(2025, 6, 1, 10, 15)},
+		{"*/15 * * * *", at(2025, 6, 1, 10, 45), at(2025, 6, 1, 11, 0)},
+		{"0 8 * * *", at(2025, 6, 1, 8, 0), at(2025, 6, 2, 8, 0)},
+		// June 1 2025 is a Sunday.
+		{"0 9 * * 1", at(2025, 6, 1, 12, 0), at(2025, 6, 2, 9, 0)},
+		{"30 6 * * 1-5", at(2025, 6, 6, 7, 0), at(2025, 6, 9, 6, 30)},
+		{"0 0 * * 7", at(2025, 6, 2, 0, 0), at(2025, 6, 8, 0, 0)},
+		{"@monthly", at(2025, 12, 15, 0, 0), at(2026, 1, 1, 0, 0)},
+		{"0 12 31 * *", at(2025, 4, 1, 0, 0), at(2025, 5, 31, 12, 0)},
+		// Day of month and day of week both restricted: either matches.
+		{"0 0 15 * 1", at(2025, 6, 10, 0, 0), at(2025, 6, 15, 0, 0)},
+		{"0 0 29 2 *", at(2025, 3, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"0 8-18/4 * * *", at(2025, 6, 1, 12, 1), at(2025, 6, 1, 16, 0)},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		if got := s.Next(c.after); !got.Equal(c.want) {
+			t.Errorf("%s after %v: got %v, want %v", c.expr, c.after, got, c.want)
+		}
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@yearly"} {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("%q: expected ErrInvalidSchedule, got %v", expr, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package scheduler runs background jobs in-process on cron-like schedules,
+// recording every run in the job_runs table.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+)
+
+// DefaultTimeout bounds a run when its Job sets no Timeout.
+const DefaultTimeout = 5 * time.Minute
+
+// Job is a named task run on Schedule, a cron expression evaluated in UTC.
+// A run is cancelled after Timeout. Runs of the same job never overlap: if a
+// run is still going when the next one is due, that one is skipped.
+type Job struct {
+	Name     string
+	Schedule string
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+}
+
+// Scheduler runs jobs until stopped.
+type Scheduler struct {
+	runs   *db.JobRunStore
+	logger *slog.Logger
+	jobs   []entry
+	now    func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(runs *db.JobRunStore, logger *slog.Logger) *Scheduler {
+	return &Scheduler{runs: runs, logger: logger, now: time.Now}
+}
+
+// Add registers a job. It must be called before Start.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job needs a name and a run function")
+	}
+	for _, e := range s.jobs {
+		if e.job.Name == job.Name {
+			return fmt.Errorf("job %q already registered", job.Name)
+		}
+	}
+	sched, err := Parse(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %q: %w", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultTimeout
+	}
+	s.jobs = append(s.jobs, entry{job: job, schedule: sched})
+	return nil
+}
+
+// Start marks runs left over from a previous process as interrupted and
+// starts every job's loop. Jobs stop when ctx is cancelled or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	if err := s.runs.MarkInterrupted(); err != nil {
+		s.logger.Error("job history cleanup failed", "error", err)
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, e := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, e)
+	}
+	s.logger.Info("scheduler started", "jobs", len(s.jobs))
+}
+
+// Stop cancels running jobs and waits for them to record their outcome, or
+// until ctx is done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.logger.Info("scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunNow runs the named job once, outside its schedule.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (*models.JobRun, error) {
+	for _, e := range s.jobs {
+		if e.job.Name == name {
+			return s.run(ctx, e.job)
+		}
+	}
+	return nil, fmt.Errorf("unknown job %q", name)
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	defer s.wg.Done()
+	for {
+		next := e.schedule.Next(s.now().UTC())
+		if next.IsZero() {
+			s.logger.Warn("job has no upcoming run", "job", e.job.Name)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if _, err := s.run(ctx, e.job); err != nil {
+			s.logger.Error("job history write failed", "job", e.job.Name, "error", err)
+		}
+	}
+}
+
+// run executes job once under its timeout and records the outcome. The
+// returned error is only about recording; the job's own error is in the run.
+func (s *Scheduler) run(ctx context.Context, job Job) (*models.JobRun, error) {
+	record, err := s.runs.Start(job.Name, s.now())
+	if err != nil {
+		return nil, err
+	}
+	runCtx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+	jobErr := safeRun(runCtx, job.Run)
+
+	status, msg := models.JobRunSucceeded, ""
+	switch {
+	case jobErr == nil:
+	case ctx.Err() != nil:
+		status, msg = models.JobRunCancelled, jobErr.Error()
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		status, msg = models.JobRunTimedOut, jobErr.Error()
+	default:
+		status, msg = models.JobRunFailed, jobErr.Error()
+	}
+	if jobErr != nil {
+		s.logger.Error("job failed", "job", job.Name, "status", status, "error", jobErr)
+	} else {
+		s.logger.Debug("job finished", "job", job.Name)
+	}
+	// Record the outcome even when the scheduler is shutting down.
+	return record, s.runs.Finish(record, status, msg, s.now())
+}
+
+// safeRun turns a panicking job into an error so one bad run does not take
+// down the server.
+func safeRun(ctx context.Context, run func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
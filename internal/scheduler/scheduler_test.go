@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestScheduler(t *testing.T) (*Scheduler, *db.JobRunStore) {
+	t.Helper()
+	gdb, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, _ := gdb.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := gdb.AutoMigrate(&models.JobRun{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	runs := &db.JobRunStore{DB: gdb}
+	return New(runs, slog.New(slog.NewTextHandler(io.Discard, nil))), runs
+}
+
+func TestRunRecordsOutcome(t *testing.T) {
+	s, runs := newTestScheduler(t)
+	jobs := []Job{
+		{Name: "ok", Schedule: "@daily", Run: func(ctx context.Context) error { return nil }},
+		{Name: "fails", Schedule: "@daily", Run: func(ctx context.Context) error { return errors.New("boom") }},
+		{Name: "slow", Schedule: "@daily", Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		{Name: "panics", Schedule: "@daily", Run: func(ctx context.Context) error { panic("bad state") }},
+	}
+	for _, j := range jobs {
+		if err := s.Add(j); err != nil {
+			t.Fatalf("add %s: %v", j.Name, err)
+		}
+	}
+	want := map[string]string{
+		"ok":     models.JobRunSucceeded,
+		"fails":  models.JobRunFailed,
+		"slow":   models.JobRunTimedOut,
+		"panics": models.JobRunFailed,
+	}
+	for name, status := range want {
+		run, err := s.RunNow(context.Background(), name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if run.Status != status || run.FinishedAt == nil {
+			t.Errorf("%s: got %+v, want status %s", name, run, status)
+		}
+	}
+	history, _ := runs.Recent("fails", 10)
+	if len(history) != 1 || history[0].Error != "boom" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	if err := s.Add(Job{Name: "ok", Schedule: "@daily", Run: jobs[0].Run}); err == nil {
+		t.Fatalf("expected duplicate job name to be rejected")
+	}
+	if err := s.Add(Job{Name: "bad", Schedule: "every day", Run: jobs[0].Run}); !errors.Is(err, ErrInvalidSchedule) {
+		t.Fatalf("expected ErrInvalidSchedule, got %v", err)
+	}
+}
+
+func TestStartMarksInterruptedAndStops(t *testing.T) {
+	s, runs := newTestScheduler(t)
+	if _, err := runs.Start("stale", time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	s.Add(Job{Name: "never", Schedule: "@monthly", Run: func(ctx context.Context) error { return nil }})
+	s.Start(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	history, _ := runs.Recent("stale", 1)
+	if len(history) != 1 || history[0].Status != models.JobRunInterrupted {
+		t.Fatalf("expected stale run to be interrupted, got %+v", history)
+	}
+}
@@ -2,21 +2,26 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
+	"bookkeeper-backend/internal/db"
 	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
 	"bookkeeper-backend/middleware"
 
 	"gorm.io/gorm"
 )
 
 type AccountHandler struct {
-	db *gorm.DB
+	db       *gorm.DB
+	accounts *db.AccountStore
 }
 
-func NewAccountHandler(db *gorm.DB) *AccountHandler {
-	return &AccountHandler{db: db}
+func NewAccountHandler(gdb *gorm.DB) *AccountHandler {
+	return &AccountHandler{db: gdb, accounts: &db.AccountStore{DB: gdb}}
 }
 
 type createAccountRequest struct {
@@ -24,22 +29,12 @@ type createAccountRequest struct {
 	Type                string `json:"type"`
 	Currency            string `json:"currency"`
 	OpeningBalanceCents int64  `json:"opening_balance_cents"`
+	BankAccountID       string `json:"bank_account_id"`
 }
 
 func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request, householdIDStr string) {
-	user, ok := middleware.UserFrom(r.Context())
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageAccounts)
 	if !ok {
-		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-	hID, valid := parseUintString(householdIDStr)
-	if !valid {
-		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
-		return
-	}
-	member, _ := userIsHouseholdMember(h.db, user.ID, hID)
-	if !member {
-		writeJSONError(r, w, "forbidden", http.StatusForbidden)
 		return
 	}
 	var req createAccountRequest
@@ -50,6 +45,10 @@ func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request, househol
 	if req.Type == "" {
 		req.Type = "checking"
 	}
+	if req.Type == models.AccountTypeExternal {
+		writeJSONError(r, w, "invalid account type", http.StatusBadRequest)
+		return
+	}
 	if req.Currency == "" {
 		req.Currency = "USD"
 	}
@@ -59,6 +58,7 @@ func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request, househol
 		Type:                req.Type,
 		Currency:            req.Currency,
 		OpeningBalanceCents: req.OpeningBalanceCents,
+		BankAccountID:       sanitizeString(req.BankAccountID),
 	}
 	if err := h.db.Create(acc).Error; err != nil {
 		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
@@ -68,40 +68,308 @@ func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request, househol
 }
 
 func (h *AccountHandler) List(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived"))
+	accounts, err := h.accounts.ListByHousehold(hID, includeArchived)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", accounts)
+}
+
+// Totals returns each account's balance and the sum per currency. Archived
+// accounts are left out unless ?include_archived=true.
+func (h *AccountHandler) Totals(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived"))
+	accounts, err := h.accounts.ListByHousehold(hID, includeArchived)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	balances, err := h.accounts.Balances(accounts)
+	if err != nil {
+		writeJSONError(r, w, "balance failed", http.StatusInternalServerError)
+		return
+	}
+	type item struct {
+		AccountID    uint   `json:"account_id"`
+		Name         string `json:"name"`
+		Currency     string `json:"currency"`
+		BalanceCents int64  `json:"balance_cents"`
+	}
+	items := make([]item, 0, len(accounts))
+	totals := map[string]int64{}
+	for _, a := range accounts {
+		items = append(items, item{AccountID: a.ID, Name: a.Name, Currency: a.Currency, BalanceCents: balances[a.ID]})
+		totals[a.Currency] += balances[a.ID]
+	}
+	writeJSONSuccess(r, w, "ok", map[string]any{"accounts": items, "totals": totals})
+}
+
+// Get returns the account with its current balance.
+func (h *AccountHandler) Get(w http.ResponseWriter, r *http.Request, accountIDStr string) {
+	acc, ok := h.loadAccount(w, r, accountIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	balance, err := h.accounts.Balance(acc)
+	if err != nil {
+		writeJSONError(r, w, "balance failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", map[string]any{"account": acc, "balance_cents": balance})
+}
+
+type updateAccountRequest struct {
+	Name                *string `json:"name"`
+	Type                *string `json:"type"`
+	Currency            *string `json:"currency"`
+	OpeningBalanceCents *int64  `json:"opening_balance_cents"`
+	BankAccountID       *string `json:"bank_account_id"`
+}
+
+func (h *AccountHandler) Update(w http.ResponseWriter, r *http.Request, accountIDStr string) {
+	acc, ok := h.loadAccount(w, r, accountIDStr, permissions.ManageAccounts)
+	if !ok {
+		return
+	}
+	var req updateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Name != nil {
+		if sanitizeString(*req.Name) == "" {
+			writeJSONError(r, w, "name cannot be empty", http.StatusBadRequest)
+			return
+		}
+		acc.Name = sanitizeString(*req.Name)
+	}
+	if req.Type != nil {
+		if *req.Type == "" || *req.Type == models.AccountTypeExternal {
+			writeJSONError(r, w, "invalid account type", http.StatusBadRequest)
+			return
+		}
+		acc.Type = *req.Type
+	}
+	if req.Currency != nil && *req.Currency != acc.Currency {
+		var n int64
+		h.db.Model(&models.Transaction{}).Where("account_id = ?", acc.ID).Count(&n)
+		if n > 0 {
+			writeJSONError(r, w, "currency cannot change once the account has transactions", http.StatusConflict)
+			return
+		}
+		acc.Currency = *req.Currency
+	}
+	if req.OpeningBalanceCents != nil {
+		acc.OpeningBalanceCents = *req.OpeningBalanceCents
+	}
+	if req.BankAccountID != nil {
+		acc.BankAccountID = sanitizeString(*req.BankAccountID)
+	}
+	if err := h.db.Save(acc).Error; err != nil {
+		writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "updated", acc)
+}
+
+// Delete removes an account with no transactions; others must be archived or closed.
+func (h *AccountHandler) Delete(w http.ResponseWriter, r *http.Request, accountIDStr string) {
+	acc, ok := h.loadAccount(w, r, accountIDStr, permissions.ManageAccounts)
+	if !ok {
+		return
+	}
+	if err := h.accounts.Delete(acc); err != nil {
+		if errors.Is(err, db.ErrAccountHasTransactions) {
+			writeJSONError(r, w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "deleted", map[string]any{"id": acc.ID})
+}
+
+func (h *AccountHandler) Archive(w http.ResponseWriter, r *http.Request, accountIDStr string) {
+	acc, ok := h.loadAccount(w, r, accountIDStr, permissions.ManageAccounts)
+	if !ok {
+		return
+	}
+	if err := h.accounts.Archive(acc); err != nil {
+		writeJSONError(r, w, "archive failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "archived", acc)
+}
+
+// Unarchive restores an archived account; a closed account is reopened.
+func (h *AccountHandler) Unarchive(w http.ResponseWriter, r *http.Request, accountIDStr string) {
+	acc, ok := h.loadAccount(w, r, accountIDStr, permissions.ManageAccounts)
+	if !ok {
+		return
+	}
+	if err := h.accounts.Unarchive(acc); err != nil {
+		writeJSONError(r, w, "unarchive failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "unarchived", acc)
+}
+
+// Close archives the account after checking its final balance is zero. A
+// non-zero balance can be swept with {"transfer_to_account_id": N}.
+func (h *AccountHandler) Close(w http.ResponseWriter, r *http.Request, accountIDStr string) {
+	acc, ok := h.loadAccount(w, r, accountIDStr, permissions.ManageAccounts)
+	if !ok {
+		return
+	}
+	user, _ := middleware.UserFrom(r.Context())
+	var req struct {
+		TransferToAccountID *uint `json:"transfer_to_account_id"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+			return
+		}
+	}
+	var sweepTo *models.Account
+	if req.TransferToAccountID != nil {
+		var target models.Account
+		err := h.db.Where("household_id = ? AND type <> ? AND closed_at IS NULL", acc.HouseholdID, models.AccountTypeExternal).
+			First(&target, *req.TransferToAccountID).Error
+		if err != nil || target.ID == acc.ID {
+			writeJSONError(r, w, "invalid transfer_to_account_id", http.StatusBadRequest)
+			return
+		}
+		if target.Currency != acc.Currency {
+			writeJSONError(r, w, "transfer_to_account_id must use the account's currency", http.StatusBadRequest)
+			return
+		}
+		sweepTo = &target
+	}
+	if err := h.accounts.Close(acc, sweepTo, user.ID); err != nil {
+		switch {
+		case errors.Is(err, db.ErrAccountNotEmpty), errors.Is(err, db.ErrAccountClosed):
+			balance, _ := h.accounts.Balance(acc)
+			writeJSONError(r, w, err.Error()+" (balance "+strconv.FormatInt(balance, 10)+" cents)", http.StatusConflict)
+		case isLedgerError(err):
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		default:
+			writeJSONError(r, w, "close failed", http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSONSuccess(r, w, "closed", acc)
+}
+
+// loadAccount resolves an accessible account from the path and checks the
+// caller's role allows action, writing the error response itself when it
+// returns false.
+func (h *AccountHandler) loadAccount(w http.ResponseWriter, r *http.Request, accountIDStr string, action permissions.Action) (*models.Account, bool) {
 	user, ok := middleware.UserFrom(r.Context())
 	if !ok {
 		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
-		return
+		return nil, false
 	}
-	hID, valid := parseUintString(householdIDStr)
+	accID, valid := parseUintString(accountIDStr)
 	if !valid {
-		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
-		return
+		writeJSONError(r, w, "invalid account id", http.StatusBadRequest)
+		return nil, false
 	}
-	member, _ := userIsHouseholdMember(h.db, user.ID, hID)
-	if !member {
+	acc, owned := h.ensureOwnership(user.ID, accID, permissions.View)
+	if !owned {
+		writeJSONError(r, w, "account not found", http.StatusNotFound)
+		return nil, false
+	}
+	if !userCan(h.db, user.ID, acc.HouseholdID, action) {
 		writeJSONError(r, w, "forbidden", http.StatusForbidden)
+		return nil, false
+	}
+	return acc, true
+}
+
+// Balance returns the account's current balance (opening balance plus all legs).
+func (h *AccountHandler) Balance(w http.ResponseWriter, r *http.Request, accountIDStr string) {
+	acc, ok := h.loadAccount(w, r, accountIDStr, permissions.View)
+	if !ok {
 		return
 	}
-	var accounts []models.Account
-	h.db.Where("household_id = ?", hID).Find(&accounts)
-	writeJSONSuccess(r, w, "ok", accounts)
+	balance, err := h.accounts.Balance(acc)
+	if err != nil {
+		writeJSONError(r, w, "balance failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", map[string]any{
+		"account_id":            acc.ID,
+		"currency":              acc.Currency,
+		"opening_balance_cents": acc.OpeningBalanceCents,
+		"balance_cents":         balance,
+		"as_of":                 time.Now().UTC(),
+	})
 }
 
-func (h *AccountHandler) ensureOwnership(userID, accountID uint) (*models.Account, bool) {
+// maxBalanceHistoryDays bounds the size of a balance time series response.
+const maxBalanceHistoryDays = 731
+
+// BalanceHistory returns daily closing balances between ?start and ?end
+// (YYYY-MM-DD, inclusive). Defaults to the last 30 days.
+func (h *AccountHandler) BalanceHistory(w http.ResponseWriter, r *http.Request, accountIDStr string) {
+	acc, ok := h.loadAccount(w, r, accountIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	end := time.Now().UTC()
+	start := end.AddDate(0, 0, -29)
+	if v := r.URL.Query().Get("start"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			writeJSONError(r, w, "start must be YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		start = t
+	}
+	if v := r.URL.Query().Get("end"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			writeJSONError(r, w, "end must be YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		end = t
+	}
+	if end.Before(start) || end.Sub(start) > maxBalanceHistoryDays*24*time.Hour {
+		writeJSONError(r, w, "invalid date range", http.StatusBadRequest)
+		return
+	}
+	points, err := h.accounts.DailyBalances(acc, start, end)
+	if err != nil {
+		writeJSONError(r, w, "balance history failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", map[string]any{
+		"account_id": acc.ID,
+		"currency":   acc.Currency,
+		"points":     points,
+	})
+}
+
+// ensureOwnership loads a real account whose household lets the user perform
+// action.
+func (h *AccountHandler) ensureOwnership(userID, accountID uint, action permissions.Action) (*models.Account, bool) {
 	var acc models.Account
-	if err := h.db.First(&acc, accountID).Error; err != nil {
+	if err := h.db.Where("type <> ?", models.AccountTypeExternal).First(&acc, accountID).Error; err != nil {
 		return nil, false
 	}
-	isMember, _ := userIsHouseholdMember(h.db, userID, acc.HouseholdID)
-	if !isMember {
+	if !userCan(h.db, userID, acc.HouseholdID, action) {
 		return nil, false
 	}
 	return &acc, true
 }
-
-func (h *AccountHandler) archiveAccount(acc *models.Account) {
-	now := time.Now()
-	acc.ArchivedAt = &now
-	h.db.Save(acc)
-}
\ No newline at end of file
@@ -0,0 +1,266 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+	"bookkeeper-backend/middleware"
+
+	"gorm.io/gorm"
+)
+
+type BillHandler struct {
+	db    *gorm.DB
+	bills *db.BillStore
+}
+
+func NewBillHandler(gdb *gorm.DB) *BillHandler {
+	return &BillHandler{db: gdb, bills: &db.BillStore{DB: gdb}}
+}
+
+// billRequest is used for create and update. next_due is a YYYY-MM-DD date;
+// rrule is an iCalendar rule such as "FREQ=MONTHLY;BYMONTHDAY=1" and empty
+// for a one-time bill. On update, omitted fields are left unchanged and
+// account_id or category_id set to null clear them.
+type billRequest struct {
+	Name        *string         `json:"name"`
+	AmountCents *int64          `json:"amount_cents"`
+	NextDue     *string         `json:"next_due"`
+	RRule       *string         `json:"rrule"`
+	AccountID   json.RawMessage `json:"account_id"`
+	CategoryID  json.RawMessage `json:"category_id"`
+}
+
+// defaultUpcomingBills is how many due dates a bill lists by default.
+const defaultUpcomingBills = 3
+
+func (h *BillHandler) Create(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBills)
+	if !ok {
+		return
+	}
+	user, _ := middleware.UserFrom(r.Context())
+	var req billRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Name == nil || req.AmountCents == nil || req.NextDue == nil {
+		writeJSONError(r, w, "name, amount_cents and next_due required", http.StatusBadRequest)
+		return
+	}
+	b := &models.Bill{UserID: user.ID, HouseholdID: hID}
+	if !h.applyRequest(w, r, b, &req) {
+		return
+	}
+	if err := h.bills.Create(b); err != nil {
+		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "created", map[string]any{"bill": b, "upcoming": db.Upcoming(b, defaultUpcomingBills)})
+}
+
+// List returns the household's open bills; ?include_closed=true adds paid
+// one-time bills.
+func (h *BillHandler) List(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	bills, err := h.bills.ListByHousehold(hID, r.URL.Query().Get("include_closed") == "true")
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", bills)
+}
+
+// Get returns a bill with its next due dates (?occurrences=N, up to 24).
+func (h *BillHandler) Get(w http.ResponseWriter, r *http.Request, householdIDStr, billIDStr string) {
+	b, ok := h.loadBill(w, r, householdIDStr, billIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	n := defaultUpcomingBills
+	if v := r.URL.Query().Get("occurrences"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > 24 {
+			writeJSONError(r, w, "occurrences must be between 1 and 24", http.StatusBadRequest)
+			return
+		}
+		n = parsed
+	}
+	writeJSONSuccess(r, w, "ok", map[string]any{"bill": b, "upcoming": db.Upcoming(b, n)})
+}
+
+func (h *BillHandler) Update(w http.ResponseWriter, r *http.Request, householdIDStr, billIDStr string) {
+	b, ok := h.loadBill(w, r, householdIDStr, billIDStr, permissions.ManageBills)
+	if !ok {
+		return
+	}
+	var req billRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !h.applyRequest(w, r, b, &req) {
+		return
+	}
+	if err := h.bills.Save(b); err != nil {
+		writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "updated", map[string]any{"bill": b, "upcoming": db.Upcoming(b, defaultUpcomingBills)})
+}
+
+func (h *BillHandler) Delete(w http.ResponseWriter, r *http.Request, householdIDStr, billIDStr string) {
+	b, ok := h.loadBill(w, r, householdIDStr, billIDStr, permissions.ManageBills)
+	if !ok {
+		return
+	}
+	if err := h.bills.Delete(b); err != nil {
+		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "deleted", map[string]any{"id": b.ID})
+}
+
+// Pay marks the bill's current occurrence paid by a household transaction
+// (body {"transaction_id": 12}) and advances it to the next due date.
+func (h *BillHandler) Pay(w http.ResponseWriter, r *http.Request, householdIDStr, billIDStr string) {
+	b, ok := h.loadBill(w, r, householdIDStr, billIDStr, permissions.ManageBills)
+	if !ok {
+		return
+	}
+	user, _ := middleware.UserFrom(r.Context())
+	var req struct {
+		TransactionID uint `json:"transaction_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TransactionID == 0 {
+		writeJSONError(r, w, "transaction_id required", http.StatusBadRequest)
+		return
+	}
+	var trx models.Transaction
+	if err := h.db.Joins("JOIN accounts a ON a.id = transactions.account_id").
+		Where("transactions.id = ? AND a.household_id = ? AND a.type <> ?", req.TransactionID, b.HouseholdID, models.AccountTypeExternal).
+		First(&trx).Error; err != nil {
+		writeJSONError(r, w, "invalid transaction_id", http.StatusBadRequest)
+		return
+	}
+	payment, err := h.bills.MarkPaid(b, &trx, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrBillClosed), errors.Is(err, db.ErrBillAlreadyPaid), errors.Is(err, db.ErrPaymentLinked):
+			writeJSONError(r, w, err.Error(), http.StatusConflict)
+		default:
+			writeJSONError(r, w, "payment failed", http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSONSuccess(r, w, "paid", map[string]any{"bill": b, "payment": payment})
+}
+
+func (h *BillHandler) Payments(w http.ResponseWriter, r *http.Request, householdIDStr, billIDStr string) {
+	b, ok := h.loadBill(w, r, householdIDStr, billIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	payments, err := h.bills.Payments(b.ID)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", payments)
+}
+
+func (h *BillHandler) loadBill(w http.ResponseWriter, r *http.Request, householdIDStr, billIDStr string, action permissions.Action) (*models.Bill, bool) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, action)
+	if !ok {
+		return nil, false
+	}
+	id, valid := parseUintString(billIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid bill id", http.StatusBadRequest)
+		return nil, false
+	}
+	b, err := h.bills.Get(hID, id)
+	if err != nil {
+		writeJSONError(r, w, "bill not found", http.StatusNotFound)
+		return nil, false
+	}
+	return b, true
+}
+
+// applyRequest copies the request's fields onto b. Changing next_due or
+// rrule re-anchors the schedule at the (new) next due date. It writes the
+// error response itself when it returns false.
+func (h *BillHandler) applyRequest(w http.ResponseWriter, r *http.Request, b *models.Bill, req *billRequest) bool {
+	if req.Name != nil {
+		name := sanitizeString(*req.Name)
+		if name == "" {
+			writeJSONError(r, w, "name cannot be empty", http.StatusBadRequest)
+			return false
+		}
+		b.Name = name
+	}
+	if req.AmountCents != nil {
+		if *req.AmountCents <= 0 {
+			writeJSONError(r, w, "amount_cents must be positive", http.StatusBadRequest)
+			return false
+		}
+		b.AmountCents = *req.AmountCents
+	}
+	if req.NextDue != nil || req.RRule != nil {
+		nextDue := b.NextDue
+		if req.NextDue != nil {
+			t, err := time.Parse("2006-01-02", *req.NextDue)
+			if err != nil {
+				writeJSONError(r, w, "next_due must be YYYY-MM-DD", http.StatusBadRequest)
+				return false
+			}
+			nextDue = t
+		}
+		rrule := b.RRule
+		if req.RRule != nil {
+			rrule = *req.RRule
+		}
+		if err := db.SetSchedule(b, rrule, nextDue); err != nil {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+		b.ClosedAt = nil
+	}
+	if len(req.AccountID) > 0 {
+		b.AccountID = nil
+		if !isJSONNull(req.AccountID) {
+			var id uint
+			var acc models.Account
+			if err := json.Unmarshal(req.AccountID, &id); err != nil ||
+				h.db.Where("household_id = ? AND type <> ?", b.HouseholdID, models.AccountTypeExternal).First(&acc, id).Error != nil {
+				writeJSONError(r, w, "invalid account_id", http.StatusBadRequest)
+				return false
+			}
+			b.AccountID = &acc.ID
+		}
+	}
+	if len(req.CategoryID) > 0 {
+		b.CategoryID = nil
+		if !isJSONNull(req.CategoryID) {
+			var id uint
+			var cat models.Category
+			if err := json.Unmarshal(req.CategoryID, &id); err != nil ||
+				h.db.Where("household_id = ?", b.HouseholdID).First(&cat, id).Error != nil {
+				writeJSONError(r, w, "invalid category_id", http.StatusBadRequest)
+				return false
+			}
+			b.CategoryID = &cat.ID
+		}
+	}
+	return true
+}
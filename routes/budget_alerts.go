@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/jobs"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+
+	"gorm.io/gorm"
+)
+
+// BudgetAlerts returns (GET) or replaces (PUT, body {"thresholds": [80, 100]})
+// the household's budget alert thresholds. GET also lists the alerts already
+// recorded for ?month=YYYY-MM.
+func (h *BudgetHandler) BudgetAlerts(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	action := permissions.View
+	if r.Method == http.MethodPut {
+		action = permissions.ManageBudgets
+	}
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, action)
+	if !ok {
+		return
+	}
+	alertStore := db.BudgetAlertStore{DB: h.db}
+
+	if r.Method == http.MethodPut {
+		var req struct {
+			Thresholds []int `json:"thresholds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		thresholds, err := alertStore.SetThresholds(hID, req.Thresholds)
+		if err != nil {
+			if errors.Is(err, db.ErrInvalidThresholds) {
+				writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+			return
+		}
+		writeJSONSuccess(r, w, "updated", map[string]any{"thresholds": thresholds})
+		return
+	}
+
+	thresholds, err := alertStore.Thresholds(hID)
+	if err != nil {
+		writeJSONError(r, w, "query error", http.StatusInternalServerError)
+		return
+	}
+	out := map[string]any{"thresholds": thresholds}
+	if month := r.URL.Query().Get("month"); month != "" {
+		if !validMonth(month) {
+			writeJSONError(r, w, "month must be YYYY-MM", http.StatusBadRequest)
+			return
+		}
+		var alerts []models.BudgetAlert
+		if err := h.db.Where("household_id = ? AND month = ?", hID, month).
+			Order("category_id asc, threshold asc").Find(&alerts).Error; err != nil {
+			writeJSONError(r, w, "query error", http.StatusInternalServerError)
+			return
+		}
+		out["alerts"] = alerts
+	}
+	writeJSONSuccess(r, w, "ok", out)
+}
+
+// evaluateBudgetAlerts notifies the household about budget thresholds newly
+// crossed in the months containing the given times. It runs after a
+// transaction write has succeeded, so failures are only logged. Thresholds
+// claimed before a failed notification are not notified again; ones not yet
+// claimed are picked up by the periodic sweep.
+func evaluateBudgetAlerts(r *http.Request, gdb *gorm.DB, notifications *db.NotificationStore, householdID uint, times ...time.Time) {
+	if notifications == nil {
+		return
+	}
+	seen := map[string]bool{}
+	months := make([]string, 0, len(times))
+	for _, t := range times {
+		m := t.UTC().Format("2006-01")
+		if !seen[m] {
+			seen[m] = true
+			months = append(months, m)
+		}
+	}
+	sort.Strings(months)
+	alertStore := db.BudgetAlertStore{DB: gdb}
+	for _, m := range months {
+		if err := jobs.BudgetAlertJob(r.Context(), &alertStore, notifications, householdID, m); err != nil {
+			slog.Error("budget alert evaluation failed", "household_id", householdID, "month", m, "error", err)
+		}
+	}
+}
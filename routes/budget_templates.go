@@ -0,0 +1,181 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/permissions"
+
+	"gorm.io/gorm"
+)
+
+type createTemplateRequest struct {
+	Name      string          `json:"name"`
+	Lines     []db.BudgetLine `json:"lines"`
+	FromMonth string          `json:"from_month"`
+}
+
+type applyBudgetRequest struct {
+	Month      string `json:"month"`
+	Source     string `json:"source"` // template, month, average
+	TemplateID uint   `json:"template_id"`
+	FromMonth  string `json:"from_month"`
+	Months     int    `json:"months"`
+	Overwrite  bool   `json:"overwrite"`
+	Preview    bool   `json:"preview"`
+}
+
+// defaultAverageMonths is the trailing window for source=average.
+const defaultAverageMonths = 3
+
+// CreateTemplate saves a template from explicit lines or, with from_month,
+// from that month's budgets.
+func (h *BudgetHandler) CreateTemplate(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBudgets)
+	if !ok {
+		return
+	}
+	var req createTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || sanitizeString(req.Name) == "" {
+		writeJSONError(r, w, "name required", http.StatusBadRequest)
+		return
+	}
+	budgetStore := db.BudgetStore{DB: h.db}
+	lines := req.Lines
+	if len(lines) == 0 && req.FromMonth != "" {
+		if !validMonth(req.FromMonth) {
+			writeJSONError(r, w, "from_month must be YYYY-MM", http.StatusBadRequest)
+			return
+		}
+		var err error
+		if lines, err = budgetStore.MonthLines(hID, req.FromMonth); err != nil {
+			writeJSONError(r, w, "query error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if len(lines) == 0 {
+		writeJSONError(r, w, "lines or a budgeted from_month required", http.StatusBadRequest)
+		return
+	}
+	t, err := budgetStore.CreateTemplate(hID, sanitizeString(req.Name), lines)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidPlan) {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "created", t)
+}
+
+func (h *BudgetHandler) ListTemplates(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	budgetStore := db.BudgetStore{DB: h.db}
+	templates, err := budgetStore.ListTemplates(hID)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", templates)
+}
+
+func (h *BudgetHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request, householdIDStr, templateIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBudgets)
+	if !ok {
+		return
+	}
+	tID, valid := parseUintString(templateIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid template id", http.StatusBadRequest)
+		return
+	}
+	budgetStore := db.BudgetStore{DB: h.db}
+	if err := budgetStore.DeleteTemplate(hID, tID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			writeJSONError(r, w, "template not found", http.StatusNotFound)
+			return
+		}
+		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "deleted", map[string]any{"id": tID})
+}
+
+// Apply fills a month's budgets from a template, another month, or the
+// trailing average of actual spending. Everything is written in one
+// transaction; preview=true reports the changes without writing them.
+func (h *BudgetHandler) Apply(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBudgets)
+	if !ok {
+		return
+	}
+	var req applyBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !validMonth(req.Month) {
+		writeJSONError(r, w, "month required YYYY-MM", http.StatusBadRequest)
+		return
+	}
+
+	budgetStore := db.BudgetStore{DB: h.db}
+	var lines []db.BudgetLine
+	var err error
+	switch req.Source {
+	case "template":
+		t, terr := budgetStore.GetTemplate(hID, req.TemplateID)
+		if terr != nil {
+			writeJSONError(r, w, "template not found", http.StatusNotFound)
+			return
+		}
+		lines = db.TemplateLines(t)
+	case "month":
+		if !validMonth(req.FromMonth) || req.FromMonth == req.Month {
+			writeJSONError(r, w, "from_month (YYYY-MM) different from month required", http.StatusBadRequest)
+			return
+		}
+		lines, err = budgetStore.MonthLines(hID, req.FromMonth)
+	case "average":
+		if req.Months == 0 {
+			req.Months = defaultAverageMonths
+		}
+		if req.Months < 1 || req.Months > 24 {
+			writeJSONError(r, w, "months must be between 1 and 24", http.StatusBadRequest)
+			return
+		}
+		lines, err = budgetStore.AverageLines(hID, req.Month, req.Months)
+	default:
+		writeJSONError(r, w, "source must be template, month or average", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeJSONError(r, w, "query error", http.StatusInternalServerError)
+		return
+	}
+
+	changes, err := budgetStore.ApplyLines(hID, req.Month, lines, req.Overwrite, req.Preview)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidPlan) {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSONError(r, w, "apply failed", http.StatusInternalServerError)
+		return
+	}
+	msg := "applied"
+	if req.Preview {
+		msg = "preview"
+	}
+	writeJSONSuccess(r, w, msg, map[string]any{
+		"month":   req.Month,
+		"preview": req.Preview,
+		"changes": changes,
+	})
+}
@@ -2,13 +2,15 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"bookkeeper-backend/internal/models"
 	"bookkeeper-backend/internal/db"
-	"bookkeeper-backend/middleware"
+	"bookkeeper-backend/internal/permissions"
 
 	"gorm.io/gorm"
 )
@@ -26,22 +28,12 @@ type createBudgetRequest struct {
 	Month        string `json:"month"`          // YYYY-MM
 	CategoryID   uint   `json:"category_id"`
 	PlannedCents int64  `json:"planned_cents"`
+	Rollover     *bool  `json:"rollover"`
 }
 
 func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request, householdIDStr string) {
-	user, ok := middleware.UserFrom(r.Context())
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBudgets)
 	if !ok {
-		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-	hID, valid := parseUintString(householdIDStr)
-	if !valid {
-		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
-		return
-	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, hID)
-	if !isMember {
-		writeJSONError(r, w, "forbidden", http.StatusForbidden)
 		return
 	}
 
@@ -59,6 +51,7 @@ func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request, household
 		Month:        req.Month,
 		CategoryID:   req.CategoryID,
 		PlannedCents: req.PlannedCents,
+		Rollover:     req.Rollover != nil && *req.Rollover,
 	}
 	if err := h.db.Create(b).Error; err != nil {
 		writeJSONError(r, w, "create failed (maybe duplicate)", http.StatusConflict)
@@ -68,19 +61,8 @@ func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request, household
 }
 
 func (h *BudgetHandler) List(w http.ResponseWriter, r *http.Request, householdIDStr string) {
-	user, ok := middleware.UserFrom(r.Context())
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
 	if !ok {
-		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-	hID, valid := parseUintString(householdIDStr)
-	if !valid {
-		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
-		return
-	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, hID)
-	if !isMember {
-		writeJSONError(r, w, "forbidden", http.StatusForbidden)
 		return
 	}
 
@@ -101,30 +83,16 @@ func (h *BudgetHandler) List(w http.ResponseWriter, r *http.Request, householdID
 
 	var budgets []models.Budget
 	h.db.Where("household_id = ? AND month = ?", hID, month).Find(&budgets)
+	budgetStore := db.BudgetStore{DB: h.db}
+	actuals, err := budgetStore.MonthActuals(hID, start, end)
+	if err != nil {
+		writeJSONError(r, w, "query error", http.StatusInternalServerError)
+		return
+	}
 
 	results := make([]row, 0, len(budgets))
 	for _, b := range budgets {
-		var actual int64
-		h.db.Model(&models.Transaction{}).
-			Select("COALESCE(SUM(amount_cents),0)").
-			Where("category_id = ? AND occurred_at >= ? AND occurred_at < ?", b.CategoryID, start, end).
-			Scan(&actual)
-
-		// Notification for budget threshold
-		if actual >= b.PlannedCents && b.PlannedCents > 0 {
-			msg := fmt.Sprintf("Budget reached for category %d: $%.2f spent of $%.2f", b.CategoryID, float64(actual)/100, float64(b.PlannedCents)/100)
-			n := &models.Notification{
-				UserID:  int64(user.ID),
-				Type:    models.NotificationTypeBudget,
-				Message: msg,
-				Read:    false,
-				CreatedAt: time.Now(),
-			}
-			if h.Notifications != nil {
-				h.Notifications.CreateNotification(r.Context(), n)
-			}
-		}
-
+		actual := actuals[b.CategoryID]
 		results = append(results, row{
 			ID:           b.ID,
 			CategoryID:   b.CategoryID,
@@ -153,22 +121,15 @@ func monthBounds(m string) (time.Time, time.Time) {
 }
 
 func (h *BudgetHandler) Delete(w http.ResponseWriter, r *http.Request, householdIDStr, budgetIDStr string) {
-	user, ok := middleware.UserFrom(r.Context())
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBudgets)
 	if !ok {
-		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-	hID, ok1 := parseUintString(householdIDStr)
-	bID, ok2 := parseUintString(budgetIDStr)
-	if !ok1 || !ok2 {
+	bID, valid := parseUintString(budgetIDStr)
+	if !valid {
 		writeJSONError(r, w, "invalid id", http.StatusBadRequest)
 		return
 	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, hID)
-	if !isMember {
-		writeJSONError(r, w, "forbidden", http.StatusForbidden)
-		return
-	}
 	if err := h.db.Where("id = ? AND household_id = ?", bID, hID).Delete(&models.Budget{}).Error; err != nil {
 		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
 		return
@@ -178,19 +139,8 @@ func (h *BudgetHandler) Delete(w http.ResponseWriter, r *http.Request, household
 
 func (h *BudgetHandler) Upsert(w http.ResponseWriter, r *http.Request, householdIDStr string) {
 	// Optional convenience endpoint (PUT) to set planned amount
-	user, ok := middleware.UserFrom(r.Context())
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBudgets)
 	if !ok {
-		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-	hID, valid := parseUintString(householdIDStr)
-	if !valid {
-		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
-		return
-	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, hID)
-	if !isMember {
-		writeJSONError(r, w, "forbidden", http.StatusForbidden)
 		return
 	}
 	var req createBudgetRequest
@@ -208,6 +158,9 @@ func (h *BudgetHandler) Upsert(w http.ResponseWriter, r *http.Request, household
 	if err == nil {
 		// update
 		existing.PlannedCents = req.PlannedCents
+		if req.Rollover != nil {
+			existing.Rollover = *req.Rollover
+		}
 		if err := h.db.Save(&existing).Error; err != nil {
 			writeJSONError(r, w, "update failed", http.StatusInternalServerError)
 			return
@@ -225,6 +178,7 @@ func (h *BudgetHandler) Upsert(w http.ResponseWriter, r *http.Request, household
 		Month:        req.Month,
 		CategoryID:   req.CategoryID,
 		PlannedCents: req.PlannedCents,
+		Rollover:     req.Rollover != nil && *req.Rollover,
 	}
 	if err := h.db.Create(b).Error; err != nil {
 		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
@@ -234,19 +188,8 @@ func (h *BudgetHandler) Upsert(w http.ResponseWriter, r *http.Request, household
 }
 
 func (h *BudgetHandler) Summary(w http.ResponseWriter, r *http.Request, householdIDStr string) {
-	user, ok := middleware.UserFrom(r.Context())
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
 	if !ok {
-		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-	hID, valid := parseUintString(householdIDStr)
-	if !valid {
-		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
-		return
-	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, hID)
-	if !isMember {
-		writeJSONError(r, w, "forbidden", http.StatusForbidden)
 		return
 	}
 	month := r.URL.Query().Get("month")
@@ -254,32 +197,57 @@ func (h *BudgetHandler) Summary(w http.ResponseWriter, r *http.Request, househol
 		writeJSONError(r, w, "month required YYYY-MM", http.StatusBadRequest)
 		return
 	}
-	start, end := monthBounds(month)
-
 	type row struct {
-		CategoryID   uint   `json:"category_id"`
-		PlannedCents int64  `json:"planned_cents"`
-		ActualCents  int64  `json:"actual_cents"`
-		Variance     int64  `json:"variance"`
-		Month        string `json:"month"`
+		db.Envelope
+		// Variance is planned minus spent; spending is negative in ActualCents.
+		Variance int64 `json:"variance"`
+		// Rollups include every descendant category.
+		RollupPlannedCents int64 `json:"rollup_planned_cents"`
+		RollupActualCents  int64 `json:"rollup_actual_cents"`
 	}
 
-	var budgets []models.Budget
-	h.db.Where("household_id = ? AND month = ?", hID, month).Find(&budgets)
+	budgetStore := db.BudgetStore{DB: h.db}
+	envelopes, err := budgetStore.Envelopes(hID, month)
+	if err != nil {
+		writeJSONError(r, w, "query error", http.StatusInternalServerError)
+		return
+	}
+	start, end := monthBounds(month)
+	actuals, err := budgetStore.MonthActuals(hID, start, end)
+	if err != nil {
+		writeJSONError(r, w, "query error", http.StatusInternalServerError)
+		return
+	}
+	planned := map[uint]int64{}
+	for _, e := range envelopes {
+		planned[e.CategoryID] = e.PlannedCents
+	}
+	categoryStore := db.CategoryStore{DB: h.db}
+	rolledPlanned, err := categoryStore.RollUp(hID, planned)
+	if err != nil {
+		writeJSONError(r, w, "query error", http.StatusInternalServerError)
+		return
+	}
+	rolledActual, err := categoryStore.RollUp(hID, actuals)
+	if err != nil {
+		writeJSONError(r, w, "query error", http.StatusInternalServerError)
+		return
+	}
+	// Parents of budgeted categories get a row even without a budget of their own.
+	for id := range rolledPlanned {
+		if _, ok := planned[id]; !ok {
+			envelopes = append(envelopes, db.Envelope{CategoryID: id, Month: month, ActualCents: actuals[id], AvailableCents: actuals[id]})
+		}
+	}
+	sort.Slice(envelopes, func(i, j int) bool { return envelopes[i].CategoryID < envelopes[j].CategoryID })
 
-	out := make([]row, 0, len(budgets))
-	for _, b := range budgets {
-		var actual int64
-		h.db.Model(&models.Transaction{}).
-			Select("COALESCE(SUM(amount_cents),0)").
-			Where("category_id = ? AND occurred_at >= ? AND occurred_at < ?", b.CategoryID, start, end).
-			Scan(&actual)
+	out := make([]row, 0, len(envelopes))
+	for _, e := range envelopes {
 		out = append(out, row{
-			CategoryID:   b.CategoryID,
-			PlannedCents: b.PlannedCents,
-			ActualCents:  actual,
-			Variance:     b.PlannedCents - actual,
-			Month:        month,
+			Envelope:           e,
+			Variance:           e.PlannedCents + e.ActualCents,
+			RollupPlannedCents: rolledPlanned[e.CategoryID],
+			RollupActualCents:  rolledActual[e.CategoryID],
 		})
 	}
 	writeJSONSuccess(r, w, "ok", map[string]any{
@@ -287,4 +255,61 @@ func (h *BudgetHandler) Summary(w http.ResponseWriter, r *http.Request, househol
 		"items":   out,
 		"summary": fmt.Sprintf("%d categories", len(out)),
 	})
-}
\ No newline at end of file
+}
+
+type moveMoneyRequest struct {
+	Month          string `json:"month"`
+	FromCategoryID uint   `json:"from_category_id"`
+	ToCategoryID   uint   `json:"to_category_id"`
+	AmountCents    int64  `json:"amount_cents"`
+	Memo           string `json:"memo"`
+}
+
+// Move reassigns money between two categories' envelopes for a month and
+// records who did it.
+func (h *BudgetHandler) Move(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBudgets)
+	if !ok {
+		return
+	}
+	var req moveMoneyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !validMonth(req.Month) {
+		writeJSONError(r, w, "month required YYYY-MM", http.StatusBadRequest)
+		return
+	}
+	budgetStore := db.BudgetStore{DB: h.db}
+	mv, err := budgetStore.MoveMoney(hID, user.ID, req.Month, req.FromCategoryID, req.ToCategoryID, req.AmountCents, sanitizeString(req.Memo))
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidMove) {
+			writeJSONError(r, w, "distinct household categories and a positive amount_cents required", http.StatusBadRequest)
+			return
+		}
+		writeJSONError(r, w, "move failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "moved", mv)
+}
+
+// Moves lists the money moves recorded for ?month=YYYY-MM.
+func (h *BudgetHandler) Moves(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	month := r.URL.Query().Get("month")
+	if !validMonth(month) {
+		writeJSONError(r, w, "month required YYYY-MM", http.StatusBadRequest)
+		return
+	}
+	budgetStore := db.BudgetStore{DB: h.db}
+	moves, err := budgetStore.ListMoves(hID, month)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", moves)
+}
@@ -1,22 +1,25 @@
 package routes
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
 
+	"bookkeeper-backend/internal/db"
 	"bookkeeper-backend/internal/models"
-	"bookkeeper-backend/middleware"
+	"bookkeeper-backend/internal/permissions"
 
 	"gorm.io/gorm"
 )
 
 type CategoryHandler struct {
-	db *gorm.DB
+	db         *gorm.DB
+	categories *db.CategoryStore
 }
 
-func NewCategoryHandler(db *gorm.DB) *CategoryHandler {
-	return &CategoryHandler{db: db}
+func NewCategoryHandler(gdb *gorm.DB) *CategoryHandler {
+	return &CategoryHandler{db: gdb, categories: &db.CategoryStore{DB: gdb}}
 }
 
 type createCategoryRequest struct {
@@ -25,19 +28,8 @@ type createCategoryRequest struct {
 }
 
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request, householdIDStr string) {
-	user, ok := middleware.UserFrom(r.Context())
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageCategories)
 	if !ok {
-		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-	hID, ok2 := parseUintString(householdIDStr)
-	if !ok2 {
-		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
-		return
-	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, hID)
-	if !isMember {
-		writeJSONError(r, w, "forbidden", http.StatusForbidden)
 		return
 	}
 	var req createCategoryRequest
@@ -45,6 +37,10 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request, househo
 		writeJSONError(r, w, "invalid payload", http.StatusBadRequest)
 		return
 	}
+	if err := h.categories.CheckParent(hID, 0, req.ParentID); err != nil {
+		writeJSONError(r, w, "invalid parent_id", http.StatusBadRequest)
+		return
+	}
 	cat := &models.Category{
 		HouseholdID: hID,
 		Name:        sanitizeString(req.Name),
@@ -58,19 +54,8 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request, househo
 }
 
 func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request, householdIDStr string) {
-	user, ok := middleware.UserFrom(r.Context())
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
 	if !ok {
-		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-	hID, ok2 := parseUintString(householdIDStr)
-	if !ok2 {
-		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
-		return
-	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, hID)
-	if !isMember {
-		writeJSONError(r, w, "forbidden", http.StatusForbidden)
 		return
 	}
 	var cats []models.Category
@@ -80,22 +65,193 @@ func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request, household
 
 // Simple stats endpoint: total categories for a household
 func (h *CategoryHandler) Count(w http.ResponseWriter, r *http.Request, householdIDStr string) {
-	user, ok := middleware.UserFrom(r.Context())
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
 	if !ok {
-		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-	hID64, err := strconv.ParseUint(householdIDStr, 10, 32)
+	var count int64
+	h.db.Model(&models.Category{}).Where("household_id = ?", hID).Count(&count)
+	writeJSONSuccess(r, w, "ok", map[string]any{"count": count})
+}
+
+// Tree returns the household's categories nested under their parents.
+func (h *CategoryHandler) Tree(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	tree, err := h.categories.Tree(hID)
 	if err != nil {
-		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
+		writeJSONError(r, w, "query error", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", tree)
+}
+
+type updateCategoryRequest struct {
+	Name     *string         `json:"name"`
+	ParentID json.RawMessage `json:"parent_id"`
+}
+
+// Update renames and/or moves a category. "parent_id": null moves it to the
+// top level; omitting parent_id leaves it where it is.
+func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request, householdIDStr, categoryIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageCategories)
+	if !ok {
 		return
 	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, uint(hID64))
-	if !isMember {
-		writeJSONError(r, w, "forbidden", http.StatusForbidden)
+	cat, ok := h.loadCategory(w, r, hID, categoryIDStr)
+	if !ok {
 		return
 	}
-	var count int64
-	h.db.Model(&models.Category{}).Where("household_id = ?", uint(hID64)).Count(&count)
-	writeJSONSuccess(r, w, "ok", map[string]any{"count": count})
-}
\ No newline at end of file
+	var req updateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Name != nil {
+		name := sanitizeString(*req.Name)
+		if name == "" {
+			writeJSONError(r, w, "name cannot be empty", http.StatusBadRequest)
+			return
+		}
+		req.Name = &name
+	}
+	setParent := len(req.ParentID) > 0
+	var parentID *uint
+	if setParent && !bytes.Equal(bytes.TrimSpace(req.ParentID), []byte("null")) {
+		var id uint
+		if err := json.Unmarshal(req.ParentID, &id); err != nil || id == 0 {
+			writeJSONError(r, w, "invalid parent_id", http.StatusBadRequest)
+			return
+		}
+		parentID = &id
+	}
+	if err := h.categories.Update(cat, req.Name, setParent, parentID); err != nil {
+		switch {
+		case errors.Is(err, db.ErrCategoryCycle):
+			writeJSONError(r, w, err.Error(), http.StatusConflict)
+		case errors.Is(err, db.ErrInvalidParent):
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		default:
+			writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSONSuccess(r, w, "updated", cat)
+}
+
+// Delete removes a category that nothing references any more; its children
+// move up a level.
+func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request, householdIDStr, categoryIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageCategories)
+	if !ok {
+		return
+	}
+	cat, ok := h.loadCategory(w, r, hID, categoryIDStr)
+	if !ok {
+		return
+	}
+	if err := h.categories.Delete(cat); err != nil {
+		if errors.Is(err, db.ErrCategoryInUse) {
+			writeJSONError(r, w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "deleted", map[string]any{"id": cat.ID})
+}
+
+// Merge folds the category into into_category_id, reassigning everything
+// that referenced it.
+func (h *CategoryHandler) Merge(w http.ResponseWriter, r *http.Request, householdIDStr, categoryIDStr string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageCategories)
+	if !ok {
+		return
+	}
+	source, ok := h.loadCategory(w, r, hID, categoryIDStr)
+	if !ok {
+		return
+	}
+	var req struct {
+		IntoCategoryID uint `json:"into_category_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.IntoCategoryID == 0 {
+		writeJSONError(r, w, "into_category_id required", http.StatusBadRequest)
+		return
+	}
+	target, err := h.categories.Get(hID, req.IntoCategoryID)
+	if err != nil {
+		writeJSONError(r, w, "target category not found", http.StatusNotFound)
+		return
+	}
+	if err := h.categories.Merge(user.ID, source, target); err != nil {
+		switch {
+		case errors.Is(err, db.ErrMergeIntoChild):
+			writeJSONError(r, w, err.Error(), http.StatusConflict)
+		case errors.Is(err, db.ErrInvalidMerge):
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		default:
+			writeJSONError(r, w, "merge failed", http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSONSuccess(r, w, "merged", map[string]any{"merged_id": source.ID, "into_category_id": target.ID})
+}
+
+// Defaults compares the household's categories with the built-in set for
+// ?locale= (GET) or creates the missing ones (POST, body {"locale"}).
+func (h *CategoryHandler) Defaults(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	action := permissions.View
+	if r.Method == http.MethodPost {
+		action = permissions.ManageCategories
+	}
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, action)
+	if !ok {
+		return
+	}
+	locale := r.URL.Query().Get("locale")
+	if r.Method == http.MethodPost {
+		var req struct {
+			Locale string `json:"locale"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+				return
+			}
+		}
+		locale = req.Locale
+	}
+	diff, err := h.categories.SeedDefaultCategories(hID, locale, r.Method != http.MethodPost)
+	if err != nil {
+		writeJSONError(r, w, "seed failed", http.StatusInternalServerError)
+		return
+	}
+	msg := "ok"
+	if r.Method == http.MethodPost {
+		msg = "applied"
+	}
+	writeJSONSuccess(r, w, msg, map[string]any{
+		"locale":  diff.Locale,
+		"locales": db.DefaultCategoryLocales(),
+		"missing": diff.Missing,
+		"present": diff.Present,
+		"applied": r.Method == http.MethodPost,
+	})
+}
+
+func (h *CategoryHandler) loadCategory(w http.ResponseWriter, r *http.Request, householdID uint, categoryIDStr string) (*models.Category, bool) {
+	cID, valid := parseUintString(categoryIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid category id", http.StatusBadRequest)
+		return nil, false
+	}
+	cat, err := h.categories.Get(householdID, cID)
+	if err != nil {
+		writeJSONError(r, w, "category not found", http.StatusNotFound)
+		return nil, false
+	}
+	return cat, true
+}
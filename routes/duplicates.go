@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+
+	"gorm.io/gorm"
+)
+
+// DuplicateHandler lists suspected duplicate transactions and resolves them.
+type DuplicateHandler struct {
+	db         *gorm.DB
+	duplicates *db.DuplicateStore
+}
+
+func NewDuplicateHandler(gdb *gorm.DB) *DuplicateHandler {
+	return &DuplicateHandler{db: gdb, duplicates: &db.DuplicateStore{DB: gdb}}
+}
+
+func (h *DuplicateHandler) List(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	suspects, err := h.duplicates.ListPending(hID)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", suspects)
+}
+
+// Resolve merges (action "merge") or dismisses (action "dismiss") a suspect.
+func (h *DuplicateHandler) Resolve(w http.ResponseWriter, r *http.Request, householdIDStr, suspectIDStr, action string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	sID, valid := parseUintString(suspectIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	var sus *models.DuplicateSuspect
+	var err error
+	if action == "merge" {
+		sus, err = h.duplicates.Merge(hID, user.ID, sID)
+	} else {
+		sus, err = h.duplicates.Dismiss(hID, sID)
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		writeJSONError(r, w, "duplicate not found", http.StatusNotFound)
+		return
+	case errors.Is(err, db.ErrDuplicateResolved):
+		writeJSONError(r, w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		writeJSONError(r, w, action+" failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, sus.Status, sus)
+}
@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/permissions"
+
+	"gorm.io/gorm"
+)
+
+// ForecastHandler projects a household's account balances forward.
+type ForecastHandler struct {
+	db        *gorm.DB
+	forecasts *db.ForecastStore
+	settings  *db.UserSettingsStore
+}
+
+func NewForecastHandler(gdb *gorm.DB) *ForecastHandler {
+	return &ForecastHandler{db: gdb, forecasts: &db.ForecastStore{DB: gdb}, settings: &db.UserSettingsStore{DB: gdb}}
+}
+
+const (
+	defaultForecastDays = 90
+	maxForecastDays     = 365
+)
+
+// Get returns a daily balance projection per account for ?days=N (default
+// 90, up to 365), averaging spending over ?lookback_days=N (default 90).
+// Days on which an account would close below the caller's low-balance
+// threshold are flagged.
+func (h *ForecastHandler) Get(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	days := defaultForecastDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxForecastDays {
+			writeJSONError(r, w, "days must be between 1 and 365", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	lookback := db.DefaultForecastLookbackDays
+	if v := r.URL.Query().Get("lookback_days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxForecastDays {
+			writeJSONError(r, w, "lookback_days must be between 1 and 365", http.StatusBadRequest)
+			return
+		}
+		lookback = n
+	}
+	f, err := h.forecasts.Forecast(hID, time.Now(), days, lookback, h.settings.LowBalanceThreshold(user.ID))
+	if err != nil {
+		writeJSONError(r, w, "forecast failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", f)
+}
@@ -0,0 +1,321 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/jobs"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+	"bookkeeper-backend/middleware"
+
+	"gorm.io/gorm"
+)
+
+type GoalHandler struct {
+	db            *gorm.DB
+	goals         *db.GoalStore
+	Notifications *db.NotificationStore
+}
+
+func NewGoalHandler(gdb *gorm.DB, notifications *db.NotificationStore) *GoalHandler {
+	return &GoalHandler{db: gdb, goals: &db.GoalStore{DB: gdb}, Notifications: notifications}
+}
+
+// goalRequest is used for create and update. On update, omitted fields are
+// left unchanged and "due_date", "account_id" or "category_id" set to null
+// clear them. An empty milestones list restores the defaults.
+type goalRequest struct {
+	Name         *string         `json:"name"`
+	TargetCents  *int64          `json:"target_cents"`
+	CurrentCents *int64          `json:"current_cents"`
+	DueDate      json.RawMessage `json:"due_date"`
+	AccountID    json.RawMessage `json:"account_id"`
+	CategoryID   json.RawMessage `json:"category_id"`
+	Milestones   []int           `json:"milestones"`
+}
+
+type goalResponse struct {
+	*models.Goal
+	Milestones []int `json:"milestones"`
+}
+
+func newGoalResponse(g *models.Goal) goalResponse {
+	return goalResponse{Goal: g, Milestones: db.Milestones(g)}
+}
+
+func (h *GoalHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.UserFrom(r.Context())
+	if !ok {
+		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req goalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Name == nil || req.TargetCents == nil {
+		writeJSONError(r, w, "name and target_cents required", http.StatusBadRequest)
+		return
+	}
+	g := &models.Goal{UserID: user.ID}
+	if !h.applyRequest(w, r, user.ID, g, &req) {
+		return
+	}
+	if err := h.goals.Create(g); err != nil {
+		if errors.Is(err, db.ErrGoalLink) {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
+		return
+	}
+	h.evaluate(r, g)
+	writeJSONSuccess(r, w, "created", newGoalResponse(g))
+}
+
+// List returns the caller's goals. Progress of linked goals is computed at
+// read time but not saved; milestones are only recorded on writes and by the
+// background job.
+func (h *GoalHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.UserFrom(r.Context())
+	if !ok {
+		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	goals, err := h.goals.ListByUser(user.ID)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	out := make([]goalResponse, 0, len(goals))
+	now := time.Now()
+	for i := range goals {
+		if current, err := h.goals.Progress(&goals[i], now); err == nil {
+			goals[i].CurrentCents = current
+		}
+		out = append(out, newGoalResponse(&goals[i]))
+	}
+	writeJSONSuccess(r, w, "ok", out)
+}
+
+func (h *GoalHandler) Get(w http.ResponseWriter, r *http.Request, goalIDStr string) {
+	g, ok := h.loadGoal(w, r, goalIDStr)
+	if !ok {
+		return
+	}
+	if current, err := h.goals.Progress(g, time.Now()); err == nil {
+		g.CurrentCents = current
+	}
+	writeJSONSuccess(r, w, "ok", newGoalResponse(g))
+}
+
+func (h *GoalHandler) Update(w http.ResponseWriter, r *http.Request, goalIDStr string) {
+	g, ok := h.loadGoal(w, r, goalIDStr)
+	if !ok {
+		return
+	}
+	var req goalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !h.applyRequest(w, r, g.UserID, g, &req) {
+		return
+	}
+	if err := h.goals.Save(g); err != nil {
+		if errors.Is(err, db.ErrGoalLink) {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		return
+	}
+	h.evaluate(r, g)
+	writeJSONSuccess(r, w, "updated", newGoalResponse(g))
+}
+
+func (h *GoalHandler) Delete(w http.ResponseWriter, r *http.Request, goalIDStr string) {
+	g, ok := h.loadGoal(w, r, goalIDStr)
+	if !ok {
+		return
+	}
+	if err := h.goals.Delete(g); err != nil {
+		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "deleted", map[string]any{"id": g.ID})
+}
+
+// Projection reports when the goal will be reached at its recent pace, the
+// monthly contribution needed to hit its due date, and whether it is on
+// track. ?months sets the lookback (default 6) and ?annual_return an
+// expected yearly return in percent.
+func (h *GoalHandler) Projection(w http.ResponseWriter, r *http.Request, goalIDStr string) {
+	g, ok := h.loadGoal(w, r, goalIDStr)
+	if !ok {
+		return
+	}
+	lookback := db.DefaultGoalLookbackMonths
+	if v := r.URL.Query().Get("months"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 60 {
+			writeJSONError(r, w, "months must be between 1 and 60", http.StatusBadRequest)
+			return
+		}
+		lookback = n
+	}
+	var annualReturn float64
+	if v := r.URL.Query().Get("annual_return"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 50 {
+			writeJSONError(r, w, "annual_return must be between 0 and 50", http.StatusBadRequest)
+			return
+		}
+		annualReturn = f
+	}
+	p, err := h.goals.Project(g, time.Now(), lookback, annualReturn)
+	if err != nil {
+		writeJSONError(r, w, "projection failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", p)
+}
+
+// loadGoal resolves one of the caller's goals, writing the error response
+// itself when it returns false.
+func (h *GoalHandler) loadGoal(w http.ResponseWriter, r *http.Request, goalIDStr string) (*models.Goal, bool) {
+	user, ok := middleware.UserFrom(r.Context())
+	if !ok {
+		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	id, valid := parseUintString(goalIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid goal id", http.StatusBadRequest)
+		return nil, false
+	}
+	g, err := h.goals.Get(user.ID, id)
+	if err != nil {
+		writeJSONError(r, w, "goal not found", http.StatusNotFound)
+		return nil, false
+	}
+	return g, true
+}
+
+// applyRequest copies the request's fields onto g, checking that a linked
+// account or category is in a household the user can view. It writes the
+// error response itself when it returns false.
+func (h *GoalHandler) applyRequest(w http.ResponseWriter, r *http.Request, userID uint, g *models.Goal, req *goalRequest) bool {
+	if req.Name != nil {
+		name := sanitizeString(*req.Name)
+		if name == "" {
+			writeJSONError(r, w, "name cannot be empty", http.StatusBadRequest)
+			return false
+		}
+		g.Name = name
+	}
+	if req.TargetCents != nil {
+		if *req.TargetCents <= 0 {
+			writeJSONError(r, w, "target_cents must be positive", http.StatusBadRequest)
+			return false
+		}
+		g.TargetCents = *req.TargetCents
+	}
+	if req.CurrentCents != nil {
+		g.CurrentCents = *req.CurrentCents
+	}
+	if len(req.DueDate) > 0 {
+		g.DueDate = nil
+		if !isJSONNull(req.DueDate) {
+			var s string
+			if err := json.Unmarshal(req.DueDate, &s); err != nil {
+				writeJSONError(r, w, "due_date must be YYYY-MM-DD", http.StatusBadRequest)
+				return false
+			}
+			t, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				writeJSONError(r, w, "due_date must be YYYY-MM-DD", http.StatusBadRequest)
+				return false
+			}
+			g.DueDate = &t
+		}
+	}
+	if req.Milestones != nil {
+		if err := db.SetMilestones(g, req.Milestones); err != nil {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	if len(req.AccountID) > 0 {
+		g.AccountID = nil
+		if !isJSONNull(req.AccountID) {
+			var id uint
+			var acc models.Account
+			if err := json.Unmarshal(req.AccountID, &id); err != nil ||
+				h.db.Where("type <> ?", models.AccountTypeExternal).First(&acc, id).Error != nil ||
+				!userCan(h.db, userID, acc.HouseholdID, permissions.View) {
+				writeJSONError(r, w, "invalid account_id", http.StatusBadRequest)
+				return false
+			}
+			g.AccountID = &acc.ID
+		}
+	}
+	if len(req.CategoryID) > 0 {
+		g.CategoryID = nil
+		if !isJSONNull(req.CategoryID) {
+			var id uint
+			var cat models.Category
+			if err := json.Unmarshal(req.CategoryID, &id); err != nil ||
+				h.db.First(&cat, id).Error != nil ||
+				!userCan(h.db, userID, cat.HouseholdID, permissions.View) {
+				writeJSONError(r, w, "invalid category_id", http.StatusBadRequest)
+				return false
+			}
+			g.CategoryID = &cat.ID
+		}
+	}
+	g.HouseholdID = nil
+	if g.AccountID != nil {
+		var acc models.Account
+		if err := h.db.Select("id", "household_id").First(&acc, *g.AccountID).Error; err == nil {
+			g.HouseholdID = &acc.HouseholdID
+		}
+	} else if g.CategoryID != nil {
+		var cat models.Category
+		if err := h.db.Select("id", "household_id").First(&cat, *g.CategoryID).Error; err == nil {
+			g.HouseholdID = &cat.HouseholdID
+		}
+	}
+	return true
+}
+
+// evaluate refreshes the goal's progress and notifies newly reached
+// milestones. Without a notification store milestones are left for the job.
+func (h *GoalHandler) evaluate(r *http.Request, g *models.Goal) {
+	if h.Notifications == nil {
+		return
+	}
+	_ = jobs.GoalMilestoneJob(r.Context(), h.goals, h.Notifications, g)
+}
+
+// evaluateHouseholdGoals notifies milestones newly reached by goals tracking
+// the household's accounts or categories, after a transaction write.
+func evaluateHouseholdGoals(r *http.Request, gdb *gorm.DB, notifications *db.NotificationStore, householdID uint) {
+	if notifications == nil {
+		return
+	}
+	goalStore := db.GoalStore{DB: gdb}
+	_ = jobs.HouseholdGoalsJob(r.Context(), &goalStore, notifications, householdID)
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"time"
 
+	"bookkeeper-backend/internal/db"
 	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
 	"bookkeeper-backend/middleware"
 
 	"gorm.io/gorm"
@@ -21,6 +23,10 @@ func NewHouseholdHandler(db *gorm.DB) *HouseholdHandler {
 
 type createHouseholdRequest struct {
 	Name string `json:"name"`
+	// Locale picks the default category set (e.g. "es"); empty means English.
+	Locale string `json:"locale"`
+	// SkipDefaultCategories starts the household with no categories.
+	SkipDefaultCategories bool `json:"skip_default_categories"`
 }
 
 func (h *HouseholdHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -38,17 +44,30 @@ func (h *HouseholdHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Name:      sanitizeString(req.Name),
 		CreatedBy: user.ID,
 	}
-	if err := h.db.Create(house).Error; err != nil {
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(house).Error; err != nil {
+			return err
+		}
+		member := &models.HouseholdMember{
+			HouseholdID: house.ID,
+			UserID:      user.ID,
+			Role:        models.RoleOwner,
+			CreatedAt:   time.Now(),
+		}
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		if req.SkipDefaultCategories {
+			return nil
+		}
+		categoryStore := db.CategoryStore{DB: tx}
+		_, err := categoryStore.SeedDefaultCategories(house.ID, req.Locale, false)
+		return err
+	})
+	if err != nil {
 		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
 		return
 	}
-	member := &models.HouseholdMember{
-		HouseholdID: house.ID,
-		UserID:      user.ID,
-		Role:        "owner",
-		CreatedAt:   time.Now(),
-	}
-	_ = h.db.Create(member).Error
 
 	writeJSONSuccess(r, w, "created", map[string]any{
 		"id":   house.ID,
@@ -81,4 +100,32 @@ func userIsHouseholdMember(db *gorm.DB, userID uint, householdID uint) (bool, st
 		return false, ""
 	}
 	return true, hm.Role
-}
\ No newline at end of file
+}
+
+// authorizeHousehold resolves the caller and household id and checks the
+// caller's role allows action, writing the error response itself when it
+// returns false.
+func authorizeHousehold(db *gorm.DB, w http.ResponseWriter, r *http.Request, householdIDStr string, action permissions.Action) (*middleware.UserContext, uint, bool) {
+	user, ok := middleware.UserFrom(r.Context())
+	if !ok {
+		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
+		return nil, 0, false
+	}
+	hID, valid := parseUintString(householdIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid household id", http.StatusBadRequest)
+		return nil, 0, false
+	}
+	if !userCan(db, user.ID, hID, action) {
+		writeJSONError(r, w, "forbidden", http.StatusForbidden)
+		return nil, 0, false
+	}
+	return user, hID, true
+}
+
+// userCan reports whether the user belongs to the household with a role
+// that permits action.
+func userCan(db *gorm.DB, userID uint, householdID uint, action permissions.Action) bool {
+	isMember, role := userIsHouseholdMember(db, userID, householdID)
+	return isMember && permissions.Can(role, action)
+}
@@ -0,0 +1,423 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/imports"
+	"bookkeeper-backend/internal/jobs"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+	"bookkeeper-backend/middleware"
+
+	"gorm.io/gorm"
+)
+
+// maxImportBytes bounds the size of an uploaded statement file.
+const maxImportBytes = 10 << 20
+
+type ImportHandler struct {
+	db            *gorm.DB
+	Notifications *db.NotificationStore
+}
+
+func NewImportHandler(gdb *gorm.DB, notifications *db.NotificationStore) *ImportHandler {
+	return &ImportHandler{db: gdb, Notifications: notifications}
+}
+
+// importRowReport is the per-line outcome returned to the client.
+type importRowReport struct {
+	Line          int    `json:"line"`
+	Status        string `json:"status"` // created, skipped, rejected
+	Reason        string `json:"reason,omitempty"`
+	TransactionID uint   `json:"transaction_id,omitempty"`
+	DuplicateOf   []uint `json:"duplicate_of,omitempty"`
+}
+
+type importReport struct {
+	AccountID      uint                  `json:"account_id"`
+	Format         string                `json:"format"`
+	Created        int                   `json:"created"`
+	Skipped        int                   `json:"skipped"`
+	Rejected       int                   `json:"rejected"`
+	Flagged        int                   `json:"flagged_duplicates"`
+	Rows           []importRowReport     `json:"rows"`
+	Reconciliation *reconciliationReport `json:"reconciliation,omitempty"`
+}
+
+// reconciliationReport compares a statement's reported ledger balance with
+// the balance computed from our own legs at the same point in time.
+type reconciliationReport struct {
+	AsOf          time.Time `json:"as_of"`
+	ReportedCents int64     `json:"reported_cents"`
+	ComputedCents int64     `json:"computed_cents"`
+	DiffCents     int64     `json:"difference_cents"`
+	Matched       bool      `json:"matched"`
+}
+
+func (h *ImportHandler) CreateProfile(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	var req models.ImportProfile
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || sanitizeString(req.Name) == "" {
+		writeJSONError(r, w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if err := imports.ValidateProfile(req); err != nil {
+		writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Delimiter == "" {
+		req.Delimiter = ","
+	}
+	if req.SignConvention == "" {
+		req.SignConvention = models.SignPositiveInflow
+	}
+	req.ID = 0
+	req.HouseholdID = hID
+	req.Name = sanitizeString(req.Name)
+	if err := h.db.Create(&req).Error; err != nil {
+		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "created", req)
+}
+
+func (h *ImportHandler) ListProfiles(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	var profiles []models.ImportProfile
+	h.db.Where("household_id = ?", hID).Order("name asc").Find(&profiles)
+	writeJSONSuccess(r, w, "ok", profiles)
+}
+
+func (h *ImportHandler) DeleteProfile(w http.ResponseWriter, r *http.Request, householdIDStr, profileIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	pID, valid := parseUintString(profileIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	if err := h.db.Where("id = ? AND household_id = ?", pID, hID).Delete(&models.ImportProfile{}).Error; err != nil {
+		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "deleted", map[string]any{"id": pID})
+}
+
+// Import accepts a multipart upload ("file") and creates transactions on the
+// account. CSV files are mapped with the saved profile named by "profile_id";
+// OFX/QFX files are deduplicated by FITID and reconciled against LEDGERBAL.
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request, accountIDStr string) {
+	user, ok := middleware.UserFrom(r.Context())
+	if !ok {
+		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	accID, valid := parseUintString(accountIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid account id", http.StatusBadRequest)
+		return
+	}
+	var acc models.Account
+	if err := h.db.Where("type <> ?", models.AccountTypeExternal).First(&acc, accID).Error; err != nil {
+		writeJSONError(r, w, "account not found", http.StatusNotFound)
+		return
+	}
+	if !userCan(h.db, user.ID, acc.HouseholdID, permissions.EditTransactions) {
+		writeJSONError(r, w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	if err := r.ParseMultipartForm(maxImportBytes); err != nil {
+		writeJSONError(r, w, "multipart upload with a file field required", http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeJSONError(r, w, "file required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	var (
+		rows      []imports.Row
+		statement *imports.Statement
+	)
+	format := importFormat(r.FormValue("format"), header.Filename, file)
+	switch format {
+	case "ofx":
+		statement, err = imports.ParseOFX(file)
+		if err == nil {
+			rows = statement.Rows
+		}
+	case "csv":
+		pID, valid := parseUintString(r.FormValue("profile_id"))
+		if !valid {
+			writeJSONError(r, w, "profile_id required for csv imports", http.StatusBadRequest)
+			return
+		}
+		var profile models.ImportProfile
+		if err := h.db.Where("id = ? AND household_id = ?", pID, acc.HouseholdID).First(&profile).Error; err != nil {
+			writeJSONError(r, w, "import profile not found", http.StatusNotFound)
+			return
+		}
+		rows, err = imports.ParseCSV(file, profile)
+	default:
+		writeJSONError(r, w, "unsupported format", http.StatusBadRequest)
+		return
+	}
+	if err == nil && statement != nil {
+		err = h.checkStatementAccount(&acc, statement)
+	}
+	if err != nil {
+		h.notifyFailure(r, user.ID, fmt.Sprintf("%s could not be read: %v", header.Filename, err))
+		writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.commitRows(&acc, user.ID, rows)
+	if err != nil {
+		h.notifyFailure(r, user.ID, fmt.Sprintf("%s was not imported: %v", header.Filename, err))
+		writeJSONError(r, w, "import failed", http.StatusInternalServerError)
+		return
+	}
+	report.Format = format
+	if report.Created > 0 {
+		times := make([]time.Time, 0, len(rows))
+		for _, row := range rows {
+			times = append(times, row.OccurredAt)
+		}
+		afterTransactionWrite(r, h.db, h.Notifications, acc.HouseholdID, times...)
+	}
+	if report.Rejected > 0 {
+		h.notifyFailure(r, user.ID, fmt.Sprintf("%s: %d of %d rows rejected", header.Filename, report.Rejected, len(report.Rows)))
+	}
+	if statement != nil && statement.LedgerBalanceCents != nil {
+		rec, err := h.reconcile(&acc, statement)
+		if err == nil {
+			report.Reconciliation = rec
+			if !rec.Matched {
+				h.notifyFailure(r, user.ID, fmt.Sprintf("%s: statement balance $%.2f differs from computed balance $%.2f",
+					header.Filename, float64(rec.ReportedCents)/100, float64(rec.ComputedCents)/100))
+			}
+		}
+	}
+	writeJSONSuccess(r, w, "imported", report)
+}
+
+// checkStatementAccount rejects an OFX statement whose ACCTID or CURDEF does
+// not match the account. An account without a bank account id takes the
+// ACCTID of the first statement imported into it.
+func (h *ImportHandler) checkStatementAccount(acc *models.Account, st *imports.Statement) error {
+	if st.Currency != "" && !strings.EqualFold(st.Currency, acc.Currency) {
+		return fmt.Errorf("statement currency %s does not match account currency %s", st.Currency, acc.Currency)
+	}
+	if st.AccountID == "" {
+		return nil
+	}
+	if acc.BankAccountID == "" {
+		acc.BankAccountID = st.AccountID
+		return h.db.Model(acc).Update("bank_account_id", st.AccountID).Error
+	}
+	if st.AccountID != acc.BankAccountID {
+		return fmt.Errorf("statement is for bank account %s, not %s", st.AccountID, acc.BankAccountID)
+	}
+	return nil
+}
+
+// commitRows posts every valid row to the account inside one database
+// transaction. Unparseable rows are rejected; zero-amount rows and rows whose
+// bank id was already imported are skipped; any database error rolls the
+// whole import back.
+func (h *ImportHandler) commitRows(acc *models.Account, userID uint, rows []imports.Row) (*importReport, error) {
+	report := &importReport{AccountID: acc.ID, Rows: make([]importRowReport, 0, len(rows))}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		ledger := db.LedgerStore{DB: tx}
+		var created []*models.Transaction
+		seen, err := importedExternalIDs(tx, acc.ID, rows)
+		if err != nil {
+			return err
+		}
+		ruleStore := db.CategoryRuleStore{DB: tx}
+		engine, err := ruleStore.Engine(acc.HouseholdID)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			rep := importRowReport{Line: row.Line}
+			_, duplicate := seen[row.ExternalID]
+			switch {
+			case row.Err != nil:
+				rep.Status, rep.Reason = "rejected", row.Err.Error()
+				report.Rejected++
+			case row.AmountCents == 0:
+				rep.Status, rep.Reason = "skipped", "zero amount"
+				report.Skipped++
+			case row.ExternalID != "" && duplicate:
+				rep.Status, rep.Reason = "skipped", "already imported"
+				report.Skipped++
+			default:
+				uid := userID
+				leg := &models.Transaction{
+					AccountID:   acc.ID,
+					UserID:      &uid,
+					AmountCents: row.AmountCents,
+					Currency:    acc.Currency,
+					Memo:        sanitizeString(row.Memo),
+					OccurredAt:  row.OccurredAt,
+				}
+				if row.ExternalID != "" {
+					extID := row.ExternalID
+					leg.ExternalID = &extID
+					seen[extID] = struct{}{}
+				}
+				engine.Apply(leg)
+				if _, err := ledger.PostStandard(acc.HouseholdID, leg); err != nil {
+					return fmt.Errorf("line %d: %w", row.Line, err)
+				}
+				auditStore := db.TransactionStore{DB: tx}
+				if err := auditStore.RecordAudit(acc.HouseholdID, userID, leg.ID, models.AuditCreate, map[string][2]any{"transaction": {nil, leg}}); err != nil {
+					return err
+				}
+				rep.Status, rep.TransactionID = "created", leg.ID
+				report.Created++
+				created = append(created, leg)
+			}
+			report.Rows = append(report.Rows, rep)
+		}
+		return flagImportDuplicates(tx, acc.HouseholdID, created, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// flagImportDuplicates checks each created leg against what was already on
+// the account. Legs from the same file are never matched with each other.
+func flagImportDuplicates(tx *gorm.DB, householdID uint, created []*models.Transaction, report *importReport) error {
+	if len(created) == 0 {
+		return nil
+	}
+	dups := db.DuplicateStore{DB: tx}
+	ids := make([]uint, len(created))
+	byID := make(map[uint]*importRowReport, len(created))
+	for i, leg := range created {
+		ids[i] = leg.ID
+	}
+	for i := range report.Rows {
+		if report.Rows[i].TransactionID != 0 {
+			byID[report.Rows[i].TransactionID] = &report.Rows[i]
+		}
+	}
+	for _, leg := range created {
+		suspects, err := dups.Detect(householdID, leg, db.DefaultDuplicateRules, ids...)
+		if err != nil {
+			return err
+		}
+		if len(suspects) == 0 {
+			continue
+		}
+		report.Flagged++
+		for _, s := range suspects {
+			byID[leg.ID].DuplicateOf = append(byID[leg.ID].DuplicateOf, s.DuplicateOfID)
+		}
+	}
+	return nil
+}
+
+// importedExternalIDs returns the bank ids in rows that already exist on the account.
+func importedExternalIDs(tx *gorm.DB, accountID uint, rows []imports.Row) (map[string]struct{}, error) {
+	seen := map[string]struct{}{}
+	var ids []string
+	for _, row := range rows {
+		if row.ExternalID != "" {
+			ids = append(ids, row.ExternalID)
+		}
+	}
+	if len(ids) == 0 {
+		return seen, nil
+	}
+	var existing []string
+	err := tx.Model(&models.Transaction{}).
+		Where("account_id = ? AND external_id IN ?", accountID, ids).
+		Pluck("external_id", &existing).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range existing {
+		seen[id] = struct{}{}
+	}
+	return seen, nil
+}
+
+// reconcile checks the statement's LEDGERBAL against our computed balance.
+func (h *ImportHandler) reconcile(acc *models.Account, st *imports.Statement) (*reconciliationReport, error) {
+	asOf := st.LedgerBalanceAsOf
+	if asOf.IsZero() {
+		asOf = time.Now().UTC()
+	}
+	store := db.AccountStore{DB: h.db}
+	computed, err := store.BalanceAsOf(acc, asOf)
+	if err != nil {
+		return nil, err
+	}
+	reported := *st.LedgerBalanceCents
+	return &reconciliationReport{
+		AsOf:          asOf,
+		ReportedCents: reported,
+		ComputedCents: computed,
+		DiffCents:     reported - computed,
+		Matched:       reported == computed,
+	}, nil
+}
+
+// importFormat picks the parser from an explicit ?format, the file
+// extension, or by sniffing for an OFX header.
+func importFormat(explicit, filename string, f io.ReadSeeker) string {
+	switch strings.ToLower(explicit) {
+	case "csv":
+		return "csv"
+	case "ofx", "qfx":
+		return "ofx"
+	case "":
+	default:
+		return explicit
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ofx", ".qfx":
+		return "ofx"
+	case ".csv":
+		return "csv"
+	}
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	_, _ = f.Seek(0, io.SeekStart)
+	upper := strings.ToUpper(string(head[:n]))
+	if strings.Contains(upper, "OFXHEADER") || strings.Contains(upper, "<OFX>") {
+		return "ofx"
+	}
+	return "csv"
+}
+
+func (h *ImportHandler) notifyFailure(r *http.Request, userID uint, details string) {
+	if h.Notifications == nil {
+		return
+	}
+	_ = jobs.ImportSyncFailureJob(r.Context(), h.Notifications, userID, details)
+}
@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/middleware"
+)
+
+func ofxUpload(t *testing.T, acctID, currency string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "statement.ofx")
+	fw.Write([]byte("OFXHEADER:100\n<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS>\n<CURDEF>" + currency +
+		"\n<BANKACCTFROM><BANKID>123<ACCTID>" + acctID + "<ACCTTYPE>CHECKING</BANKACCTFROM>\n" +
+		"<BANKTRANLIST></BANKTRANLIST>\n</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\n"))
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req.WithContext(middleware.WithUser(req.Context(), &middleware.UserContext{ID: 8}))
+}
+
+func TestImportOFXChecksStatementAccount(t *testing.T) {
+	gdb := newTestDB(t, models.RoleOwner, &models.CategoryRule{})
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&checking)
+	h := NewImportHandler(gdb, nil)
+
+	rr := httptest.NewRecorder()
+	h.Import(rr, ofxUpload(t, "000111", "EUR"), "1")
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "currency") {
+		t.Fatalf("expected a currency mismatch, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.Import(rr, ofxUpload(t, "000111", "usd"), "1")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("first import: %d %s", rr.Code, rr.Body.String())
+	}
+	gdb.First(&checking, checking.ID)
+	if checking.BankAccountID != "000111" {
+		t.Fatalf("expected the account pinned to 000111, got %q", checking.BankAccountID)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Import(rr, ofxUpload(t, "999888", "USD"), "1")
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "999888") {
+		t.Fatalf("expected an account mismatch, got %d %s", rr.Code, rr.Body.String())
+	}
+}
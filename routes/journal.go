@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+
+	"gorm.io/gorm"
+)
+
+// JournalHandler exposes multi-leg journal entries (splits across accounts,
+// credit-card payments with fees, etc.) for a household.
+type JournalHandler struct {
+	db     *gorm.DB
+	ledger *db.LedgerStore
+}
+
+func NewJournalHandler(gdb *gorm.DB) *JournalHandler {
+	return &JournalHandler{db: gdb, ledger: &db.LedgerStore{DB: gdb}}
+}
+
+type journalLegRequest struct {
+	// AccountID 0 posts against the household's external account.
+	AccountID   uint   `json:"account_id"`
+	AmountCents int64  `json:"amount_cents"`
+	CategoryID  *uint  `json:"category_id"`
+	Memo        string `json:"memo"`
+}
+
+type createJournalEntryRequest struct {
+	Memo       string              `json:"memo"`
+	Currency   string              `json:"currency"`
+	OccurredAt *string             `json:"occurred_at"`
+	Legs       []journalLegRequest `json:"legs"`
+}
+
+func (h *JournalHandler) Create(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	var req createJournalEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Currency == "" {
+		req.Currency = "USD"
+	}
+	occ := time.Now()
+	if req.OccurredAt != nil {
+		if t, err := time.Parse(time.RFC3339, *req.OccurredAt); err == nil {
+			occ = t
+		}
+	}
+	entry := &models.JournalEntry{
+		HouseholdID: hID,
+		UserID:      &user.ID,
+		Kind:        models.EntryKindCompound,
+		Memo:        sanitizeString(req.Memo),
+		OccurredAt:  occ,
+	}
+	var ext *models.Account
+	for _, leg := range req.Legs {
+		accountID := leg.AccountID
+		if accountID == 0 {
+			if ext == nil {
+				acc, err := h.ledger.ExternalAccount(hID, req.Currency)
+				if err != nil {
+					writeJSONError(r, w, "create failed", http.StatusInternalServerError)
+					return
+				}
+				ext = acc
+			}
+			accountID = ext.ID
+		}
+		entry.Lines = append(entry.Lines, models.Transaction{
+			AccountID:   accountID,
+			AmountCents: leg.AmountCents,
+			Currency:    req.Currency,
+			CategoryID:  leg.CategoryID,
+			Memo:        sanitizeString(leg.Memo),
+		})
+	}
+	// Each leg's creation is audited in the same database transaction.
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		ledger := db.LedgerStore{DB: tx}
+		if err := ledger.Post(entry); err != nil {
+			return err
+		}
+		auditStore := db.TransactionStore{DB: tx}
+		for i := range entry.Lines {
+			if err := auditStore.RecordAudit(hID, user.ID, entry.Lines[i].ID, models.AuditCreate, map[string][2]any{"transaction": {nil, &entry.Lines[i]}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if isLedgerError(err) {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "created", entry)
+}
+
+func (h *JournalHandler) Get(w http.ResponseWriter, r *http.Request, householdIDStr, entryIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	eID, valid := parseUintString(entryIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	entry, err := h.ledger.GetEntry(hID, eID)
+	if err != nil {
+		writeJSONError(r, w, "entry not found", http.StatusNotFound)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", entry)
+}
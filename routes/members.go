@@ -0,0 +1,313 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+	"bookkeeper-backend/middleware"
+
+	"gorm.io/gorm"
+)
+
+// MembershipHandler manages household invitations and members.
+type MembershipHandler struct {
+	db            *gorm.DB
+	households    *db.HouseholdStore
+	Notifications *db.NotificationStore
+}
+
+func NewMembershipHandler(gdb *gorm.DB, notifications *db.NotificationStore) *MembershipHandler {
+	return &MembershipHandler{db: gdb, households: &db.HouseholdStore{DB: gdb}, Notifications: notifications}
+}
+
+type createInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// Invite creates a single-use invite token. The token is only returned
+// here; existing users with that email also get an in-app notification.
+func (h *MembershipHandler) Invite(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageMembers)
+	if !ok {
+		return
+	}
+	var req createInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	req.Email = sanitizeString(req.Email)
+	if !strings.Contains(req.Email, "@") {
+		writeJSONError(r, w, "valid email required", http.StatusBadRequest)
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.RoleEditor
+	}
+	if !permissions.Assignable(req.Role) {
+		writeJSONError(r, w, "invalid role", http.StatusBadRequest)
+		return
+	}
+	var invitee models.User
+	found := h.db.Where("LOWER(email) = LOWER(?)", req.Email).First(&invitee).Error == nil
+	if found {
+		if isMember, _ := userIsHouseholdMember(h.db, invitee.ID, hID); isMember {
+			writeJSONError(r, w, db.ErrAlreadyMember.Error(), http.StatusConflict)
+			return
+		}
+	}
+	inv, token, err := h.households.CreateInvite(hID, user.ID, req.Email, req.Role)
+	if err != nil {
+		writeJSONError(r, w, "invite failed", http.StatusInternalServerError)
+		return
+	}
+	if found && h.Notifications != nil {
+		var house models.Household
+		h.db.First(&house, hID)
+		n := &models.Notification{
+			UserID:    int64(invitee.ID),
+			Type:      models.NotificationTypeHouseholdInvite,
+			Title:     "Household invitation",
+			Message:   fmt.Sprintf("%s invited you to join the household '%s'.", user.Email, house.Name),
+			CreatedAt: time.Now(),
+		}
+		h.Notifications.CreateNotification(r.Context(), n)
+	}
+	writeJSONSuccess(r, w, "created", map[string]any{"invite": inv, "token": token})
+}
+
+func (h *MembershipHandler) ListInvites(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageMembers)
+	if !ok {
+		return
+	}
+	invites, err := h.households.ListPendingInvites(hID)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", invites)
+}
+
+func (h *MembershipHandler) RevokeInvite(w http.ResponseWriter, r *http.Request, householdIDStr, inviteIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageMembers)
+	if !ok {
+		return
+	}
+	iID, valid := parseUintString(inviteIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid invite id", http.StatusBadRequest)
+		return
+	}
+	if err := h.households.RevokeInvite(hID, iID); err != nil {
+		if errors.Is(err, db.ErrInviteNotPending) {
+			writeJSONError(r, w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSONError(r, w, "revoke failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "revoked", map[string]any{"id": iID})
+}
+
+// MyInvites lists pending invites addressed to the caller's email.
+func (h *MembershipHandler) MyInvites(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.UserFrom(r.Context())
+	if !ok {
+		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var u models.User
+	if err := h.db.First(&u, user.ID).Error; err != nil {
+		writeJSONError(r, w, "user not found", http.StatusNotFound)
+		return
+	}
+	invites, err := h.households.ListInvitesForEmail(u.Email)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", invites)
+}
+
+type respondInviteRequest struct {
+	Token    string `json:"token"`
+	InviteID uint   `json:"invite_id"`
+}
+
+// Respond accepts or declines an invite identified by its token or, for
+// in-app invites, by id. The invite must be addressed to the caller's email.
+func (h *MembershipHandler) Respond(w http.ResponseWriter, r *http.Request, action string) {
+	user, ok := middleware.UserFrom(r.Context())
+	if !ok {
+		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req respondInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.Token == "" && req.InviteID == 0) {
+		writeJSONError(r, w, "token or invite_id required", http.StatusBadRequest)
+		return
+	}
+	var u models.User
+	if err := h.db.First(&u, user.ID).Error; err != nil {
+		writeJSONError(r, w, "user not found", http.StatusNotFound)
+		return
+	}
+	var inv *models.HouseholdInvite
+	if req.Token != "" {
+		found, err := h.households.FindInviteByToken(req.Token)
+		if err != nil {
+			writeJSONError(r, w, "invite not found", http.StatusNotFound)
+			return
+		}
+		inv = found
+	} else {
+		var found models.HouseholdInvite
+		if err := h.db.Where("id = ? AND LOWER(email) = LOWER(?)", req.InviteID, u.Email).First(&found).Error; err != nil {
+			writeJSONError(r, w, "invite not found", http.StatusNotFound)
+			return
+		}
+		inv = &found
+	}
+
+	var err error
+	if action == "accept" {
+		err = h.households.AcceptInvite(inv, &u)
+	} else {
+		err = h.households.DeclineInvite(inv, &u)
+	}
+	switch {
+	case errors.Is(err, db.ErrInviteEmailMismatch):
+		writeJSONError(r, w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, db.ErrInviteNotPending), errors.Is(err, db.ErrAlreadyMember):
+		writeJSONError(r, w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		writeJSONError(r, w, action+" failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, action+"ed", map[string]any{"household_id": inv.HouseholdID, "role": inv.Role})
+}
+
+func (h *MembershipHandler) Members(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	members, err := h.households.ListMembers(hID)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", members)
+}
+
+// RemoveMember lets the owner remove another member.
+func (h *MembershipHandler) RemoveMember(w http.ResponseWriter, r *http.Request, householdIDStr, userIDStr string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageMembers)
+	if !ok {
+		return
+	}
+	uID, valid := parseUintString(userIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	if uID == user.ID {
+		writeJSONError(r, w, "use leave to remove yourself", http.StatusBadRequest)
+		return
+	}
+	h.writeRemoval(w, r, hID, uID)
+}
+
+// Leave removes the caller from the household.
+func (h *MembershipHandler) Leave(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	h.writeRemoval(w, r, hID, user.ID)
+}
+
+func (h *MembershipHandler) writeRemoval(w http.ResponseWriter, r *http.Request, hID, userID uint) {
+	err := h.households.RemoveMember(hID, userID)
+	switch {
+	case errors.Is(err, db.ErrNotMember):
+		writeJSONError(r, w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, db.ErrLastOwner):
+		writeJSONError(r, w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		writeJSONError(r, w, "remove failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "removed", map[string]any{"household_id": hID, "user_id": userID})
+}
+
+// SetRole switches another member between editor and viewer.
+func (h *MembershipHandler) SetRole(w http.ResponseWriter, r *http.Request, householdIDStr, userIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageMembers)
+	if !ok {
+		return
+	}
+	uID, valid := parseUintString(userIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !permissions.Assignable(req.Role) {
+		writeJSONError(r, w, "role must be editor or viewer", http.StatusBadRequest)
+		return
+	}
+	err := h.households.SetRole(hID, uID, req.Role)
+	switch {
+	case errors.Is(err, db.ErrNotMember):
+		writeJSONError(r, w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, db.ErrLastOwner):
+		writeJSONError(r, w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "updated", map[string]any{"household_id": hID, "user_id": uID, "role": req.Role})
+}
+
+// TransferOwnership hands the owner role to another member; the caller
+// stays on as an editor.
+func (h *MembershipHandler) TransferOwnership(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageMembers)
+	if !ok {
+		return
+	}
+	var req struct {
+		UserID uint `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == 0 || req.UserID == user.ID {
+		writeJSONError(r, w, "user_id of another member required", http.StatusBadRequest)
+		return
+	}
+	if err := h.households.TransferOwnership(hID, user.ID, req.UserID); err != nil {
+		if errors.Is(err, db.ErrNotMember) {
+			writeJSONError(r, w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSONError(r, w, "transfer failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "transferred", map[string]any{"household_id": hID, "owner_id": req.UserID})
+}
@@ -0,0 +1,100 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/middleware"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an in-memory database with one household where user 8 has
+// role.
+func newTestDB(t *testing.T, role string, extra ...any) *gorm.DB {
+	t.Helper()
+	gdb, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, _ := gdb.DB()
+	sqlDB.SetMaxOpenConns(1)
+	tables := append([]any{&models.Household{}, &models.HouseholdMember{}, &models.Account{}, &models.Transaction{}}, extra...)
+	if err := gdb.AutoMigrate(tables...); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	household := models.Household{Name: "Home"}
+	gdb.Create(&household)
+	gdb.Create(&models.HouseholdMember{HouseholdID: household.ID, UserID: 8, Role: role})
+	return gdb
+}
+
+// asUser returns a request authenticated as user 8.
+func asUser(method, body string) *http.Request {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	return req.WithContext(middleware.WithUser(req.Context(), &middleware.UserContext{ID: 8}))
+}
+
+func TestViewerCannotWrite(t *testing.T) {
+	gdb := newTestDB(t, models.RoleViewer)
+	checking := models.Account{HouseholdID: 1, Name: "Checking", Type: "checking", Currency: "USD"}
+	gdb.Create(&checking)
+	hID := "1"
+	accID := "1"
+
+	accounts := NewAccountHandler(gdb)
+	bills := NewBillHandler(gdb)
+	budgets := NewBudgetHandler(gdb, nil)
+	categories := NewCategoryHandler(gdb)
+	duplicates := NewDuplicateHandler(gdb)
+	imports := NewImportHandler(gdb, nil)
+	journal := NewJournalHandler(gdb)
+	members := NewMembershipHandler(gdb, nil)
+	rules := NewRuleHandler(gdb)
+	scheduled := NewScheduledTransactionHandler(gdb)
+	subscriptions := NewSubscriptionHandler(gdb, nil)
+	transactions := NewTransactionHandler(gdb, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		call   func(w http.ResponseWriter, r *http.Request)
+	}{
+		{"accounts create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { accounts.Create(w, r, hID) }},
+		{"accounts update", http.MethodPatch, func(w http.ResponseWriter, r *http.Request) { accounts.Update(w, r, accID) }},
+		{"accounts close", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { accounts.Close(w, r, accID) }},
+		{"bills create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { bills.Create(w, r, hID) }},
+		{"budgets create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { budgets.Create(w, r, hID) }},
+		{"budget alerts update", http.MethodPut, func(w http.ResponseWriter, r *http.Request) { budgets.BudgetAlerts(w, r, hID) }},
+		{"budget templates create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { budgets.CreateTemplate(w, r, hID) }},
+		{"categories create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { categories.Create(w, r, hID) }},
+		{"duplicates resolve", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { duplicates.Resolve(w, r, hID, "1", "merge") }},
+		{"import profiles create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { imports.CreateProfile(w, r, hID) }},
+		{"imports", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { imports.Import(w, r, accID) }},
+		{"journal create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { journal.Create(w, r, hID) }},
+		{"members invite", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { members.Invite(w, r, hID) }},
+		{"rules create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { rules.Create(w, r, hID) }},
+		{"scheduled create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { scheduled.Create(w, r, hID) }},
+		{"subscriptions scan", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { subscriptions.Scan(w, r, hID) }},
+		{"transactions create", http.MethodPost, func(w http.ResponseWriter, r *http.Request) { transactions.Create(w, r, accID) }},
+	}
+	for _, tc := range tests {
+		rr := httptest.NewRecorder()
+		tc.call(rr, asUser(tc.method, "{}"))
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 for a viewer, got %d: %s", tc.name, rr.Code, rr.Body.String())
+		}
+	}
+
+	// A viewer can still read the account.
+	rr := httptest.NewRecorder()
+	accounts.Get(rr, asUser(http.MethodGet, ""), accID)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected a viewer to read the account, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
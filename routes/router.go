@@ -43,6 +43,16 @@ func BuildRouter(cfg *config.Config, gdb *gorm.DB, logger *slog.Logger) http.Han
 	transactions := NewTransactionHandler(gdb, &notificationStore)
 	categories := NewCategoryHandler(gdb)
 	budgets := NewBudgetHandler(gdb, &notificationStore)
+	journal := NewJournalHandler(gdb)
+	importer := NewImportHandler(gdb, &notificationStore)
+	duplicates := NewDuplicateHandler(gdb)
+	categoryRules := NewRuleHandler(gdb)
+	members := NewMembershipHandler(gdb, &notificationStore)
+	goals := NewGoalHandler(gdb, &notificationStore)
+	bills := NewBillHandler(gdb)
+	subscriptions := NewSubscriptionHandler(gdb, &notificationStore)
+	scheduled := NewScheduledTransactionHandler(gdb)
+	forecasts := NewForecastHandler(gdb)
 	// calculators are implemented as package-level handlers
 
 	protected := middleware.AuthMiddleware(cfg)
@@ -99,6 +109,56 @@ func BuildRouter(cfg *config.Config, gdb *gorm.DB, logger *slog.Logger) http.Han
 		}
 	})))
 
+	mux.Handle("/v1/goals", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			goals.Create(w, r)
+		case http.MethodGet:
+			goals.List(w, r)
+		default:
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	mux.Handle("/v1/goals/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/goals/"), "/")
+		if len(parts) == 2 && parts[1] == "projection" {
+			if r.Method == http.MethodGet {
+				goals.Projection(w, r, parts[0])
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if len(parts) != 1 {
+			writeJSONError(r, w, "not found", http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			goals.Get(w, r, parts[0])
+		case http.MethodPatch:
+			goals.Update(w, r, parts[0])
+		case http.MethodDelete:
+			goals.Delete(w, r, parts[0])
+		default:
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/v1/invites", protected(http.HandlerFunc(members.MyInvites)))
+	mux.Handle("/v1/invites/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		action := strings.TrimPrefix(r.URL.Path, "/v1/invites/")
+		if action != "accept" && action != "decline" {
+			writeJSONError(r, w, "not found", http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodPost {
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		members.Respond(w, r, action)
+	})))
+
 	mux.Handle("/v1/households/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/v1/households/")
 		parts := strings.Split(path, "/")
@@ -118,15 +178,65 @@ func BuildRouter(cfg *config.Config, gdb *gorm.DB, logger *slog.Logger) http.Han
 				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
 			}
 			return
+		case "balances":
+			if len(parts) == 2 && r.Method == http.MethodGet {
+				accounts.Totals(w, r, householdID)
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
 		case "categories":
-			switch r.Method {
-			case http.MethodPost:
-				categories.Create(w, r, householdID)
-			case http.MethodGet:
-				categories.List(w, r, householdID)
-			default:
+			if len(parts) == 2 {
+				switch r.Method {
+				case http.MethodPost:
+					categories.Create(w, r, householdID)
+				case http.MethodGet:
+					categories.List(w, r, householdID)
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 3 && parts[2] == "defaults" {
+				if r.Method == http.MethodGet || r.Method == http.MethodPost {
+					categories.Defaults(w, r, householdID)
+					return
+				}
 				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			if len(parts) == 3 && (parts[2] == "tree" || parts[2] == "count") {
+				if r.Method != http.MethodGet {
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+					return
+				}
+				if parts[2] == "tree" {
+					categories.Tree(w, r, householdID)
+				} else {
+					categories.Count(w, r, householdID)
+				}
+				return
 			}
+			if len(parts) == 3 {
+				switch r.Method {
+				case http.MethodPatch:
+					categories.Update(w, r, householdID, parts[2])
+				case http.MethodDelete:
+					categories.Delete(w, r, householdID, parts[2])
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 4 && parts[3] == "merge" {
+				if r.Method == http.MethodPost {
+					categories.Merge(w, r, householdID, parts[2])
+					return
+				}
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			writeJSONError(r, w, "not found", http.StatusNotFound)
 			return
 		case "budgets":
 			if len(parts) == 2 {
@@ -142,6 +252,25 @@ func BuildRouter(cfg *config.Config, gdb *gorm.DB, logger *slog.Logger) http.Han
 				}
 				return
 			}
+			if len(parts) == 3 && parts[2] == "apply" {
+				if r.Method == http.MethodPost {
+					budgets.Apply(w, r, householdID)
+					return
+				}
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			if len(parts) == 3 && parts[2] == "moves" {
+				switch r.Method {
+				case http.MethodPost:
+					budgets.Move(w, r, householdID)
+				case http.MethodGet:
+					budgets.Moves(w, r, householdID)
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
 			if len(parts) == 3 {
 				if r.Method == http.MethodDelete {
 					budgets.Delete(w, r, householdID, parts[2])
@@ -152,6 +281,262 @@ func BuildRouter(cfg *config.Config, gdb *gorm.DB, logger *slog.Logger) http.Han
 			}
 			writeJSONError(r, w, "not found", http.StatusNotFound)
 			return
+		case "entries":
+			if len(parts) == 2 && r.Method == http.MethodPost {
+				journal.Create(w, r, householdID)
+				return
+			}
+			if len(parts) == 3 && r.Method == http.MethodGet {
+				journal.Get(w, r, householdID, parts[2])
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		case "import_profiles":
+			if len(parts) == 2 {
+				switch r.Method {
+				case http.MethodPost:
+					importer.CreateProfile(w, r, householdID)
+				case http.MethodGet:
+					importer.ListProfiles(w, r, householdID)
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 3 && r.Method == http.MethodDelete {
+				importer.DeleteProfile(w, r, householdID, parts[2])
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		case "transactions":
+			if len(parts) == 2 && r.Method == http.MethodGet {
+				transactions.Search(w, r, householdID)
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		case "rules":
+			if len(parts) == 2 {
+				switch r.Method {
+				case http.MethodPost:
+					categoryRules.Create(w, r, householdID)
+				case http.MethodGet:
+					categoryRules.List(w, r, householdID)
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 3 && parts[2] == "apply" {
+				if r.Method == http.MethodPost {
+					categoryRules.Apply(w, r, householdID)
+					return
+				}
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			if len(parts) == 3 {
+				switch r.Method {
+				case http.MethodPut:
+					categoryRules.Update(w, r, householdID, parts[2])
+				case http.MethodDelete:
+					categoryRules.Delete(w, r, householdID, parts[2])
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			writeJSONError(r, w, "not found", http.StatusNotFound)
+			return
+		case "duplicates":
+			if len(parts) == 2 && r.Method == http.MethodGet {
+				duplicates.List(w, r, householdID)
+				return
+			}
+			if len(parts) == 4 && (parts[3] == "merge" || parts[3] == "dismiss") {
+				if r.Method == http.MethodPost {
+					duplicates.Resolve(w, r, householdID, parts[2], parts[3])
+					return
+				}
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			writeJSONError(r, w, "not found", http.StatusNotFound)
+			return
+		case "invites":
+			if len(parts) == 2 {
+				switch r.Method {
+				case http.MethodPost:
+					members.Invite(w, r, householdID)
+				case http.MethodGet:
+					members.ListInvites(w, r, householdID)
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 3 && r.Method == http.MethodDelete {
+				members.RevokeInvite(w, r, householdID, parts[2])
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		case "members":
+			if len(parts) == 2 && r.Method == http.MethodGet {
+				members.Members(w, r, householdID)
+				return
+			}
+			if len(parts) == 3 {
+				switch r.Method {
+				case http.MethodPatch:
+					members.SetRole(w, r, householdID, parts[2])
+				case http.MethodDelete:
+					members.RemoveMember(w, r, householdID, parts[2])
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		case "leave":
+			if len(parts) == 2 && r.Method == http.MethodPost {
+				members.Leave(w, r, householdID)
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		case "transfer_ownership":
+			if len(parts) == 2 && r.Method == http.MethodPost {
+				members.TransferOwnership(w, r, householdID)
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		case "budget_templates":
+			if len(parts) == 2 {
+				switch r.Method {
+				case http.MethodPost:
+					budgets.CreateTemplate(w, r, householdID)
+				case http.MethodGet:
+					budgets.ListTemplates(w, r, householdID)
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 3 && r.Method == http.MethodDelete {
+				budgets.DeleteTemplate(w, r, householdID, parts[2])
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		case "bills":
+			if len(parts) == 2 {
+				switch r.Method {
+				case http.MethodPost:
+					bills.Create(w, r, householdID)
+				case http.MethodGet:
+					bills.List(w, r, householdID)
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 3 {
+				switch r.Method {
+				case http.MethodGet:
+					bills.Get(w, r, householdID, parts[2])
+				case http.MethodPatch:
+					bills.Update(w, r, householdID, parts[2])
+				case http.MethodDelete:
+					bills.Delete(w, r, householdID, parts[2])
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 4 && parts[3] == "pay" && r.Method == http.MethodPost {
+				bills.Pay(w, r, householdID, parts[2])
+				return
+			}
+			if len(parts) == 4 && parts[3] == "payments" && r.Method == http.MethodGet {
+				bills.Payments(w, r, householdID, parts[2])
+				return
+			}
+			writeJSONError(r, w, "not found", http.StatusNotFound)
+			return
+		case "scheduled_transactions":
+			if len(parts) == 2 {
+				switch r.Method {
+				case http.MethodPost:
+					scheduled.Create(w, r, householdID)
+				case http.MethodGet:
+					scheduled.List(w, r, householdID)
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 3 {
+				switch r.Method {
+				case http.MethodGet:
+					scheduled.Get(w, r, householdID, parts[2])
+				case http.MethodPatch:
+					scheduled.Update(w, r, householdID, parts[2])
+				case http.MethodDelete:
+					scheduled.Delete(w, r, householdID, parts[2])
+				default:
+					writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if len(parts) == 4 && parts[3] == "preview" && r.Method == http.MethodGet {
+				scheduled.Preview(w, r, householdID, parts[2])
+				return
+			}
+			if len(parts) == 4 && parts[3] == "skip" && r.Method == http.MethodPost {
+				scheduled.Skip(w, r, householdID, parts[2])
+				return
+			}
+			if len(parts) == 4 && parts[3] == "next" {
+				if r.Method == http.MethodPut || r.Method == http.MethodDelete {
+					scheduled.Next(w, r, householdID, parts[2])
+					return
+				}
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			writeJSONError(r, w, "not found", http.StatusNotFound)
+			return
+		case "subscriptions":
+			if len(parts) == 2 && r.Method == http.MethodGet {
+				subscriptions.List(w, r, householdID)
+				return
+			}
+			if len(parts) == 3 && parts[2] == "scan" && r.Method == http.MethodPost {
+				subscriptions.Scan(w, r, householdID)
+				return
+			}
+			if len(parts) == 4 && (parts[3] == "confirm" || parts[3] == "dismiss") {
+				if r.Method == http.MethodPost {
+					subscriptions.Resolve(w, r, householdID, parts[2], parts[3])
+					return
+				}
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			writeJSONError(r, w, "not found", http.StatusNotFound)
+			return
+		case "budget_alerts":
+			if len(parts) == 2 && (r.Method == http.MethodGet || r.Method == http.MethodPut) {
+				budgets.BudgetAlerts(w, r, householdID)
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
 		case "budget_summary":
 			if r.Method == http.MethodGet {
 				budgets.Summary(w, r, householdID)
@@ -159,6 +544,13 @@ func BuildRouter(cfg *config.Config, gdb *gorm.DB, logger *slog.Logger) http.Han
 			}
 			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
 			return
+		case "forecast":
+			if len(parts) == 2 && r.Method == http.MethodGet {
+				forecasts.Get(w, r, householdID)
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
 		default:
 			writeJSONError(r, w, "not found", http.StatusNotFound)
 			return
@@ -168,6 +560,58 @@ func BuildRouter(cfg *config.Config, gdb *gorm.DB, logger *slog.Logger) http.Han
 	mux.Handle("/v1/accounts/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/v1/accounts/")
 		parts := strings.Split(path, "/")
+		if len(parts) == 1 {
+			switch r.Method {
+			case http.MethodGet:
+				accounts.Get(w, r, parts[0])
+			case http.MethodPatch:
+				accounts.Update(w, r, parts[0])
+			case http.MethodDelete:
+				accounts.Delete(w, r, parts[0])
+			default:
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+			return
+		}
+		if len(parts) == 2 && (parts[1] == "archive" || parts[1] == "unarchive" || parts[1] == "close") {
+			if r.Method != http.MethodPost {
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			switch parts[1] {
+			case "archive":
+				accounts.Archive(w, r, parts[0])
+			case "unarchive":
+				accounts.Unarchive(w, r, parts[0])
+			default:
+				accounts.Close(w, r, parts[0])
+			}
+			return
+		}
+		if len(parts) >= 2 && parts[1] == "balance" {
+			if r.Method != http.MethodGet {
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			if len(parts) == 2 {
+				accounts.Balance(w, r, parts[0])
+				return
+			}
+			if len(parts) == 3 && parts[2] == "history" {
+				accounts.BalanceHistory(w, r, parts[0])
+				return
+			}
+			writeJSONError(r, w, "not found", http.StatusNotFound)
+			return
+		}
+		if len(parts) == 2 && parts[1] == "imports" {
+			if r.Method == http.MethodPost {
+				importer.Import(w, r, parts[0])
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 		if len(parts) >= 2 && parts[1] == "transactions" {
 			accountID := parts[0]
 			switch r.Method {
@@ -183,6 +627,49 @@ func BuildRouter(cfg *config.Config, gdb *gorm.DB, logger *slog.Logger) http.Han
 		writeJSONError(r, w, "not found", http.StatusNotFound)
 	})))
 
+	mux.Handle("/v1/transactions/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v1/transactions/")
+		parts := strings.Split(path, "/")
+		if len(parts) == 1 && parts[0] == "bulk" {
+			if r.Method == http.MethodPost {
+				transactions.Bulk(w, r)
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if len(parts) == 1 {
+			switch r.Method {
+			case http.MethodGet:
+				transactions.Get(w, r, parts[0])
+			case http.MethodPatch:
+				transactions.Update(w, r, parts[0])
+			case http.MethodDelete:
+				transactions.Delete(w, r, parts[0])
+			default:
+				writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+			return
+		}
+		if len(parts) == 2 && parts[1] == "audit" {
+			if r.Method == http.MethodGet {
+				transactions.Audit(w, r, parts[0])
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if len(parts) == 2 && parts[1] == "splits" {
+			if r.Method == http.MethodPut {
+				transactions.SetSplits(w, r, parts[0])
+				return
+			}
+			writeJSONError(r, w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSONError(r, w, "not found", http.StatusNotFound)
+	})))
+
 	userSettingsStore := db.UserSettingsStore{DB: gdb}
 	userSettingsHandler := &UserSettingsHandler{Store: &userSettingsStore}
 	mux.Handle("/v1/user/settings", protected(http.HandlerFunc(userSettingsHandler.Get)))
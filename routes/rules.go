@@ -0,0 +1,174 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+	"bookkeeper-backend/internal/rules"
+
+	"gorm.io/gorm"
+)
+
+// RuleHandler manages a household's auto-categorization rules.
+type RuleHandler struct {
+	db    *gorm.DB
+	rules *db.CategoryRuleStore
+}
+
+func NewRuleHandler(gdb *gorm.DB) *RuleHandler {
+	return &RuleHandler{db: gdb, rules: &db.CategoryRuleStore{DB: gdb}}
+}
+
+type ruleRequest struct {
+	Name           string `json:"name"`
+	Priority       int    `json:"priority"`
+	MemoContains   string `json:"memo_contains"`
+	MemoRegex      string `json:"memo_regex"`
+	MinAmountCents *int64 `json:"min_amount_cents"`
+	MaxAmountCents *int64 `json:"max_amount_cents"`
+	AccountID      *uint  `json:"account_id"`
+	CategoryID     uint   `json:"category_id"`
+	Enabled        *bool  `json:"enabled"`
+}
+
+// decodeRule parses and validates the payload into rule, checking that the
+// category and account belong to the household. rule is either new or the
+// stored rule being updated.
+func (h *RuleHandler) decodeRule(w http.ResponseWriter, r *http.Request, hID uint, rule *models.CategoryRule) bool {
+	var req ruleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || sanitizeString(req.Name) == "" {
+		writeJSONError(r, w, "invalid payload", http.StatusBadRequest)
+		return false
+	}
+	rule.HouseholdID = hID
+	rule.Name = sanitizeString(req.Name)
+	rule.Priority = req.Priority
+	rule.MemoContains = req.MemoContains
+	rule.MemoRegex = req.MemoRegex
+	rule.MinAmountCents = req.MinAmountCents
+	rule.MaxAmountCents = req.MaxAmountCents
+	rule.AccountID = req.AccountID
+	rule.CategoryID = req.CategoryID
+	// New rules start enabled; an update leaves enabled alone unless sent.
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	} else if rule.ID == 0 {
+		rule.Enabled = true
+	}
+	if err := rules.Validate(*rule); err != nil {
+		writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	var n int64
+	h.db.Model(&models.Category{}).Where("id = ? AND household_id = ?", rule.CategoryID, hID).Count(&n)
+	if n == 0 {
+		writeJSONError(r, w, "invalid category_id", http.StatusBadRequest)
+		return false
+	}
+	if rule.AccountID != nil {
+		h.db.Model(&models.Account{}).Where("id = ? AND household_id = ? AND type <> ?", *rule.AccountID, hID, models.AccountTypeExternal).Count(&n)
+		if n == 0 {
+			writeJSONError(r, w, "invalid account_id", http.StatusBadRequest)
+			return false
+		}
+	}
+	return true
+}
+
+func (h *RuleHandler) Create(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageCategories)
+	if !ok {
+		return
+	}
+	var rule models.CategoryRule
+	if !h.decodeRule(w, r, hID, &rule) {
+		return
+	}
+	if err := h.db.Create(&rule).Error; err != nil {
+		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "created", rule)
+}
+
+func (h *RuleHandler) List(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	rs, err := h.rules.ListByHousehold(hID)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", rs)
+}
+
+func (h *RuleHandler) Update(w http.ResponseWriter, r *http.Request, householdIDStr, ruleIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageCategories)
+	if !ok {
+		return
+	}
+	rID, valid := parseUintString(ruleIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid rule id", http.StatusBadRequest)
+		return
+	}
+	var rule models.CategoryRule
+	if err := h.db.Where("id = ? AND household_id = ?", rID, hID).First(&rule).Error; err != nil {
+		writeJSONError(r, w, "rule not found", http.StatusNotFound)
+		return
+	}
+	if !h.decodeRule(w, r, hID, &rule) {
+		return
+	}
+	if err := h.db.Save(&rule).Error; err != nil {
+		writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "updated", rule)
+}
+
+func (h *RuleHandler) Delete(w http.ResponseWriter, r *http.Request, householdIDStr, ruleIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageCategories)
+	if !ok {
+		return
+	}
+	rID, valid := parseUintString(ruleIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid rule id", http.StatusBadRequest)
+		return
+	}
+	if err := h.db.Where("id = ? AND household_id = ?", rID, hID).Delete(&models.CategoryRule{}).Error; err != nil {
+		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "deleted", map[string]any{"id": rID})
+}
+
+// Apply re-runs the rules over existing transactions. With {"overwrite": true}
+// already-categorized transactions are recategorized as well.
+func (h *RuleHandler) Apply(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	user, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageCategories)
+	if !ok {
+		return
+	}
+	var req struct {
+		Overwrite bool `json:"overwrite"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+			return
+		}
+	}
+	n, err := h.rules.Reapply(hID, user.ID, req.Overwrite)
+	if err != nil {
+		writeJSONError(r, w, "apply failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "applied", map[string]any{"updated": n})
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bookkeeper-backend/internal/models"
+)
+
+func TestRuleUpdateKeepsEnabledWhenOmitted(t *testing.T) {
+	gdb := newTestDB(t, models.RoleOwner, &models.Category{}, &models.CategoryRule{})
+	cat := models.Category{HouseholdID: 1, Name: "Groceries"}
+	gdb.Create(&cat)
+	rule := models.CategoryRule{HouseholdID: 1, Name: "Market", MemoContains: "market", CategoryID: cat.ID}
+	gdb.Create(&rule)
+	h := NewRuleHandler(gdb)
+
+	rr := httptest.NewRecorder()
+	h.Update(rr, asUser(http.MethodPut, `{"name":"Farmers market","memo_contains":"market","category_id":1}`), "1", "1")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", rr.Code, rr.Body.String())
+	}
+	var got models.CategoryRule
+	gdb.First(&got, rule.ID)
+	if got.Enabled || got.Name != "Farmers market" {
+		t.Fatalf("expected a renamed, still disabled rule, got %+v", got)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Update(rr, asUser(http.MethodPut, `{"name":"Farmers market","memo_contains":"market","category_id":1,"enabled":true}`), "1", "1")
+	gdb.First(&got, rule.ID)
+	if rr.Code != http.StatusOK || !got.Enabled {
+		t.Fatalf("expected the rule enabled, got %d %+v", rr.Code, got)
+	}
+}
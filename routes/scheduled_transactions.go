@@ -0,0 +1,391 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+	"bookkeeper-backend/middleware"
+
+	"gorm.io/gorm"
+)
+
+// ScheduledTransactionHandler manages recurring transaction templates. The
+// scheduler posts their occurrences as they come due.
+type ScheduledTransactionHandler struct {
+	db        *gorm.DB
+	scheduled *db.ScheduledTransactionStore
+}
+
+func NewScheduledTransactionHandler(gdb *gorm.DB) *ScheduledTransactionHandler {
+	return &ScheduledTransactionHandler{db: gdb, scheduled: &db.ScheduledTransactionStore{DB: gdb}}
+}
+
+// scheduledRequest is used for create and update. starts_on and ends_on are
+// YYYY-MM-DD dates and rrule an iCalendar rule such as
+// "FREQ=MONTHLY;BYMONTHDAY=1". amount_cents is signed like a transaction on
+// account_id; counter_account_id makes it a transfer. On update, omitted
+// fields are left unchanged and nullable fields set to null clear them.
+// Occurrences already due are not posted unless backfill is set, and then
+// only those from the last db.ScheduledBackfillDays days.
+type scheduledRequest struct {
+	AccountID        *uint           `json:"account_id"`
+	CounterAccountID json.RawMessage `json:"counter_account_id"`
+	CategoryID       json.RawMessage `json:"category_id"`
+	AmountCents      *int64          `json:"amount_cents"`
+	Memo             *string         `json:"memo"`
+	RRule            *string         `json:"rrule"`
+	StartsOn         *string         `json:"starts_on"`
+	EndsOn           json.RawMessage `json:"ends_on"`
+	Backfill         bool            `json:"backfill"`
+}
+
+// nextOccurrenceRequest overrides the next occurrence only.
+type nextOccurrenceRequest struct {
+	Date        *string `json:"date"`
+	AmountCents *int64  `json:"amount_cents"`
+	Memo        *string `json:"memo"`
+}
+
+const (
+	defaultScheduledPreview = 5
+	maxScheduledPreview     = 100
+)
+
+// Create adds a scheduled transaction. Its first occurrence is the first on
+// or after today; with backfill, recent past ones are posted by the next
+// scheduler run.
+func (h *ScheduledTransactionHandler) Create(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	user, _ := middleware.UserFrom(r.Context())
+	var req scheduledRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.AccountID == nil || req.AmountCents == nil || req.RRule == nil || req.StartsOn == nil {
+		writeJSONError(r, w, "account_id, amount_cents, rrule and starts_on required", http.StatusBadRequest)
+		return
+	}
+	st := &models.ScheduledTransaction{HouseholdID: hID, UserID: user.ID}
+	if !h.applyRequest(w, r, st, &req) {
+		return
+	}
+	if err := h.scheduled.Create(st); err != nil {
+		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "created", map[string]any{"scheduled_transaction": st, "upcoming": db.Preview(st, defaultScheduledPreview)})
+}
+
+// List returns the household's active scheduled transactions;
+// ?include_closed=true adds ended ones.
+func (h *ScheduledTransactionHandler) List(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	out, err := h.scheduled.ListByHousehold(hID, r.URL.Query().Get("include_closed") == "true")
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", out)
+}
+
+func (h *ScheduledTransactionHandler) Get(w http.ResponseWriter, r *http.Request, householdIDStr, scheduledIDStr string) {
+	st, ok := h.loadScheduled(w, r, householdIDStr, scheduledIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	writeJSONSuccess(r, w, "ok", map[string]any{"scheduled_transaction": st, "upcoming": db.Preview(st, defaultScheduledPreview)})
+}
+
+// Preview lists the next ?count=N occurrences (default 5, up to 100) with
+// any override of the next one applied.
+func (h *ScheduledTransactionHandler) Preview(w http.ResponseWriter, r *http.Request, householdIDStr, scheduledIDStr string) {
+	st, ok := h.loadScheduled(w, r, householdIDStr, scheduledIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	n := defaultScheduledPreview
+	if v := r.URL.Query().Get("count"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxScheduledPreview {
+			writeJSONError(r, w, "count must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		n = parsed
+	}
+	writeJSONSuccess(r, w, "ok", db.Preview(st, n))
+}
+
+// Update edits the template. Changing rrule, starts_on or ends_on
+// re-derives the next occurrence and drops its override.
+func (h *ScheduledTransactionHandler) Update(w http.ResponseWriter, r *http.Request, householdIDStr, scheduledIDStr string) {
+	st, ok := h.loadScheduled(w, r, householdIDStr, scheduledIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	var req scheduledRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	read := *st
+	if !h.applyRequest(w, r, st, &req) {
+		return
+	}
+	if !h.update(w, r, st, read, req.columns()...) {
+		return
+	}
+	writeJSONSuccess(r, w, "updated", map[string]any{"scheduled_transaction": st, "upcoming": db.Preview(st, defaultScheduledPreview)})
+}
+
+func (h *ScheduledTransactionHandler) Delete(w http.ResponseWriter, r *http.Request, householdIDStr, scheduledIDStr string) {
+	st, ok := h.loadScheduled(w, r, householdIDStr, scheduledIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	if err := h.scheduled.Delete(st); err != nil {
+		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "deleted", map[string]any{"id": st.ID})
+}
+
+// Skip drops the next occurrence without posting it.
+func (h *ScheduledTransactionHandler) Skip(w http.ResponseWriter, r *http.Request, householdIDStr, scheduledIDStr string) {
+	st, ok := h.loadScheduled(w, r, householdIDStr, scheduledIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	if err := h.scheduled.Skip(st); err != nil {
+		switch {
+		case errors.Is(err, db.ErrScheduleEnded), errors.Is(err, db.ErrOccurrenceHandled):
+			writeJSONError(r, w, err.Error(), http.StatusConflict)
+		default:
+			writeJSONError(r, w, "skip failed", http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSONSuccess(r, w, "skipped", map[string]any{"scheduled_transaction": st, "upcoming": db.Preview(st, defaultScheduledPreview)})
+}
+
+// Next overrides the date, amount or memo of the next occurrence only (PUT)
+// or clears the override (DELETE).
+func (h *ScheduledTransactionHandler) Next(w http.ResponseWriter, r *http.Request, householdIDStr, scheduledIDStr string) {
+	st, ok := h.loadScheduled(w, r, householdIDStr, scheduledIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	if st.ClosedAt != nil {
+		writeJSONError(r, w, db.ErrScheduleEnded.Error(), http.StatusConflict)
+		return
+	}
+	read := *st
+	if r.Method == http.MethodDelete {
+		st.NextDate, st.NextAmountCents, st.NextMemo = nil, nil, nil
+	} else {
+		var req nextOccurrenceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		if req.Date == nil && req.AmountCents == nil && req.Memo == nil {
+			writeJSONError(r, w, "date, amount_cents or memo required", http.StatusBadRequest)
+			return
+		}
+		if req.Date != nil {
+			t, err := time.Parse("2006-01-02", *req.Date)
+			if err != nil {
+				writeJSONError(r, w, "date must be YYYY-MM-DD", http.StatusBadRequest)
+				return
+			}
+			st.NextDate = &t
+		}
+		if req.AmountCents != nil {
+			if *req.AmountCents == 0 {
+				writeJSONError(r, w, "amount_cents cannot be zero", http.StatusBadRequest)
+				return
+			}
+			st.NextAmountCents = req.AmountCents
+		}
+		if req.Memo != nil {
+			memo := sanitizeString(*req.Memo)
+			st.NextMemo = &memo
+		}
+	}
+	if !h.update(w, r, st, read, "next_date", "next_amount_cents", "next_memo") {
+		return
+	}
+	writeJSONSuccess(r, w, "updated", map[string]any{"scheduled_transaction": st, "upcoming": db.Preview(st, defaultScheduledPreview)})
+}
+
+func (h *ScheduledTransactionHandler) loadScheduled(w http.ResponseWriter, r *http.Request, householdIDStr, scheduledIDStr string, action permissions.Action) (*models.ScheduledTransaction, bool) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, action)
+	if !ok {
+		return nil, false
+	}
+	id, valid := parseUintString(scheduledIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid scheduled transaction id", http.StatusBadRequest)
+		return nil, false
+	}
+	st, err := h.scheduled.Get(hID, id)
+	if err != nil {
+		writeJSONError(r, w, "scheduled transaction not found", http.StatusNotFound)
+		return nil, false
+	}
+	return st, true
+}
+
+// update writes the changed columns of st, answering 409 when the scheduler
+// handled the next occurrence since st was read. It writes the error response
+// itself when it returns false.
+func (h *ScheduledTransactionHandler) update(w http.ResponseWriter, r *http.Request, st *models.ScheduledTransaction, read models.ScheduledTransaction, columns ...string) bool {
+	if err := h.scheduled.Update(st, read, columns...); err != nil {
+		if errors.Is(err, db.ErrOccurrenceHandled) {
+			writeJSONError(r, w, err.Error(), http.StatusConflict)
+		} else {
+			writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		}
+		return false
+	}
+	return true
+}
+
+// columns lists the template columns the request changes.
+func (req *scheduledRequest) columns() []string {
+	var cols []string
+	if req.AccountID != nil {
+		cols = append(cols, "account_id", "currency")
+	}
+	if len(req.CounterAccountID) > 0 {
+		cols = append(cols, "counter_account_id")
+	}
+	if len(req.CategoryID) > 0 {
+		cols = append(cols, "category_id")
+	}
+	if req.AmountCents != nil {
+		cols = append(cols, "amount_cents")
+	}
+	if req.Memo != nil {
+		cols = append(cols, "memo")
+	}
+	if req.RRule != nil || req.StartsOn != nil || len(req.EndsOn) > 0 {
+		cols = append(cols, "rrule", "starts_on", "ends_on", "next_due", "next_date", "next_amount_cents", "next_memo", "closed_at")
+	}
+	return cols
+}
+
+// applyRequest copies the request's fields onto st and re-derives its next
+// occurrence when the schedule changed. It writes the error response itself
+// when it returns false.
+func (h *ScheduledTransactionHandler) applyRequest(w http.ResponseWriter, r *http.Request, st *models.ScheduledTransaction, req *scheduledRequest) bool {
+	if req.AccountID != nil {
+		var acc models.Account
+		if err := h.db.Where("household_id = ? AND type <> ? AND closed_at IS NULL", st.HouseholdID, models.AccountTypeExternal).
+			First(&acc, *req.AccountID).Error; err != nil {
+			writeJSONError(r, w, "invalid account_id", http.StatusBadRequest)
+			return false
+		}
+		st.AccountID, st.Currency = acc.ID, acc.Currency
+	}
+	if len(req.CounterAccountID) > 0 {
+		st.CounterAccountID = nil
+		if !isJSONNull(req.CounterAccountID) {
+			var id uint
+			var counter models.Account
+			if err := json.Unmarshal(req.CounterAccountID, &id); err != nil ||
+				h.db.Where("household_id = ? AND type <> ? AND closed_at IS NULL", st.HouseholdID, models.AccountTypeExternal).First(&counter, id).Error != nil {
+				writeJSONError(r, w, "invalid counter_account_id", http.StatusBadRequest)
+				return false
+			}
+			st.CounterAccountID = &counter.ID
+		}
+	}
+	if st.CounterAccountID != nil && *st.CounterAccountID == st.AccountID {
+		writeJSONError(r, w, "invalid counter_account_id", http.StatusBadRequest)
+		return false
+	}
+	if len(req.CategoryID) > 0 {
+		st.CategoryID = nil
+		if !isJSONNull(req.CategoryID) {
+			var id uint
+			var cat models.Category
+			if err := json.Unmarshal(req.CategoryID, &id); err != nil ||
+				h.db.Where("household_id = ?", st.HouseholdID).First(&cat, id).Error != nil {
+				writeJSONError(r, w, "invalid category_id", http.StatusBadRequest)
+				return false
+			}
+			st.CategoryID = &cat.ID
+		}
+	}
+	if st.CounterAccountID != nil && st.CategoryID != nil {
+		writeJSONError(r, w, "transfers cannot be categorized", http.StatusBadRequest)
+		return false
+	}
+	if req.AmountCents != nil {
+		if *req.AmountCents == 0 {
+			writeJSONError(r, w, "amount_cents cannot be zero", http.StatusBadRequest)
+			return false
+		}
+		st.AmountCents = *req.AmountCents
+	}
+	if req.Memo != nil {
+		st.Memo = sanitizeString(*req.Memo)
+	}
+	if req.RRule == nil && req.StartsOn == nil && len(req.EndsOn) == 0 {
+		return true
+	}
+
+	rrule, startsOn, endsOn := st.RRule, st.StartsOn, st.EndsOn
+	if req.RRule != nil {
+		rrule = *req.RRule
+	}
+	if req.StartsOn != nil {
+		t, err := time.Parse("2006-01-02", *req.StartsOn)
+		if err != nil {
+			writeJSONError(r, w, "starts_on must be YYYY-MM-DD", http.StatusBadRequest)
+			return false
+		}
+		startsOn = t
+	}
+	if len(req.EndsOn) > 0 {
+		endsOn = nil
+		if !isJSONNull(req.EndsOn) {
+			var s string
+			t, err := time.Time{}, json.Unmarshal(req.EndsOn, &s)
+			if err == nil {
+				t, err = time.Parse("2006-01-02", s)
+			}
+			if err != nil {
+				writeJSONError(r, w, "ends_on must be YYYY-MM-DD", http.StatusBadRequest)
+				return false
+			}
+			endsOn = &t
+		}
+	}
+	if err := db.SetScheduledRule(st, rrule, startsOn, endsOn); err != nil {
+		writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	notBefore := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.Backfill {
+		notBefore = notBefore.AddDate(0, 0, -db.ScheduledBackfillDays)
+	}
+	if err := h.scheduled.Reschedule(st, notBefore); err != nil {
+		writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
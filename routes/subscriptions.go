@@ -0,0 +1,103 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/jobs"
+	"bookkeeper-backend/internal/models"
+	"bookkeeper-backend/internal/permissions"
+	"bookkeeper-backend/middleware"
+
+	"gorm.io/gorm"
+)
+
+// SubscriptionHandler lists detected subscriptions and confirms them as
+// bills or dismisses them.
+type SubscriptionHandler struct {
+	db            *gorm.DB
+	subscriptions *db.SubscriptionStore
+	Notifications *db.NotificationStore
+}
+
+func NewSubscriptionHandler(gdb *gorm.DB, ns *db.NotificationStore) *SubscriptionHandler {
+	return &SubscriptionHandler{db: gdb, subscriptions: &db.SubscriptionStore{DB: gdb}, Notifications: ns}
+}
+
+// List returns the household's subscription candidates; ?status= filters by
+// pending, confirmed or dismissed.
+func (h *SubscriptionHandler) List(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.SubscriptionPending, models.SubscriptionConfirmed, models.SubscriptionDismissed:
+	default:
+		writeJSONError(r, w, "invalid status", http.StatusBadRequest)
+		return
+	}
+	out, err := h.subscriptions.List(hID, status)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", out)
+}
+
+// Scan runs detection now instead of waiting for the nightly sweep and
+// returns the pending candidates.
+func (h *SubscriptionHandler) Scan(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBills)
+	if !ok {
+		return
+	}
+	households := &db.HouseholdStore{DB: h.db}
+	if err := jobs.SubscriptionDetectionJob(r.Context(), h.subscriptions, households, h.Notifications, hID); err != nil {
+		writeJSONError(r, w, "scan failed", http.StatusInternalServerError)
+		return
+	}
+	out, err := h.subscriptions.List(hID, models.SubscriptionPending)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", out)
+}
+
+// Resolve confirms (action "confirm") a candidate as a recurring bill or
+// dismisses it (action "dismiss").
+func (h *SubscriptionHandler) Resolve(w http.ResponseWriter, r *http.Request, householdIDStr, candidateIDStr, action string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.ManageBills)
+	if !ok {
+		return
+	}
+	user, _ := middleware.UserFrom(r.Context())
+	id, valid := parseUintString(candidateIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid subscription id", http.StatusBadRequest)
+		return
+	}
+	c, err := h.subscriptions.Get(hID, id)
+	if err != nil {
+		writeJSONError(r, w, "subscription not found", http.StatusNotFound)
+		return
+	}
+	var bill *models.Bill
+	if action == "confirm" {
+		bill, err = h.subscriptions.Confirm(c, user.ID)
+	} else {
+		err = h.subscriptions.Dismiss(c)
+	}
+	switch {
+	case errors.Is(err, db.ErrSubscriptionResolved):
+		writeJSONError(r, w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		writeJSONError(r, w, action+" failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, c.Status, map[string]any{"subscription": c, "bill": bill})
+}
@@ -2,13 +2,16 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"bookkeeper-backend/internal/models"
 	"bookkeeper-backend/internal/db"
+	"bookkeeper-backend/internal/permissions"
 	"bookkeeper-backend/middleware"
 
 	"gorm.io/gorm"
@@ -16,11 +19,13 @@ import (
 
 type TransactionHandler struct {
 	db *gorm.DB
+	duplicates *db.DuplicateStore
+	rules *db.CategoryRuleStore
 	Notifications *db.NotificationStore
 }
 
-func NewTransactionHandler(db *gorm.DB, notifications *db.NotificationStore) *TransactionHandler {
-	return &TransactionHandler{db: db, Notifications: notifications}
+func NewTransactionHandler(gdb *gorm.DB, notifications *db.NotificationStore) *TransactionHandler {
+	return &TransactionHandler{db: gdb, duplicates: &db.DuplicateStore{DB: gdb}, rules: &db.CategoryRuleStore{DB: gdb}, Notifications: notifications}
 }
 
 type createTransactionRequest struct {
@@ -29,6 +34,17 @@ type createTransactionRequest struct {
 	CategoryID  *uint   `json:"category_id"`
 	Memo        string  `json:"memo"`
 	OccurredAt  *string `json:"occurred_at"`
+	// CounterAccountID turns the request into a transfer: the counter
+	// account receives the opposite amount.
+	CounterAccountID *uint `json:"counter_account_id"`
+	// Splits divides the amount across categories instead of CategoryID.
+	Splits []splitRequest `json:"splits"`
+}
+
+type splitRequest struct {
+	CategoryID  uint   `json:"category_id"`
+	AmountCents int64  `json:"amount_cents"`
+	Memo        string `json:"memo"`
 }
 
 func (h *TransactionHandler) Create(w http.ResponseWriter, r *http.Request, accountIDStr string) {
@@ -43,12 +59,11 @@ func (h *TransactionHandler) Create(w http.ResponseWriter, r *http.Request, acco
 		return
 	}
 	var acc models.Account
-	if err := h.db.First(&acc, uint(accID)).Error; err != nil {
+	if err := h.db.Where("type <> ?", models.AccountTypeExternal).First(&acc, uint(accID)).Error; err != nil {
 		writeJSONError(r, w, "account not found", http.StatusNotFound)
 		return
 	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, acc.HouseholdID)
-	if !isMember {
+	if !userCan(h.db, user.ID, acc.HouseholdID, permissions.EditTransactions) {
 		writeJSONError(r, w, "forbidden", http.StatusForbidden)
 		return
 	}
@@ -79,10 +94,67 @@ func (h *TransactionHandler) Create(w http.ResponseWriter, r *http.Request, acco
 		Memo:        sanitizeString(req.Memo),
 		OccurredAt:  occ,
 	}
-	if err := h.db.Create(trx).Error; err != nil {
+	var splits []models.TransactionSplit
+	var counter models.Account
+	if len(req.Splits) > 0 {
+		if req.CategoryID != nil || req.CounterAccountID != nil {
+			writeJSONError(r, w, "splits cannot be combined with category_id or counter_account_id", http.StatusBadRequest)
+			return
+		}
+		var ok bool
+		splits, ok = h.householdSplits(acc.HouseholdID, req.Splits)
+		if !ok {
+			writeJSONError(r, w, "invalid split category", http.StatusBadRequest)
+			return
+		}
+	} else if req.CounterAccountID != nil {
+		if req.CategoryID != nil {
+			writeJSONError(r, w, "transfers cannot be categorized", http.StatusBadRequest)
+			return
+		}
+		if err := h.db.Where("household_id = ? AND type <> ?", acc.HouseholdID, models.AccountTypeExternal).
+			First(&counter, *req.CounterAccountID).Error; err != nil || counter.ID == acc.ID {
+			writeJSONError(r, w, "invalid counter_account_id", http.StatusBadRequest)
+			return
+		}
+	} else if engine, err := h.rules.Engine(acc.HouseholdID); err == nil {
+		engine.Apply(trx)
+	}
+	// The create is audited in the same database transaction as the posting.
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		ledger := db.LedgerStore{DB: tx}
+		var err error
+		if counter.ID != 0 {
+			_, err = ledger.PostTransfer(acc.HouseholdID, counter.ID, trx)
+		} else {
+			_, err = ledger.PostStandard(acc.HouseholdID, trx)
+		}
+		if err != nil {
+			return err
+		}
+		if len(splits) > 0 {
+			splitStore := db.SplitStore{DB: tx}
+			if err := splitStore.Replace(trx, splits); err != nil {
+				return err
+			}
+		}
+		auditStore := db.TransactionStore{DB: tx}
+		return auditStore.RecordAudit(acc.HouseholdID, user.ID, trx.ID, models.AuditCreate, map[string][2]any{"transaction": {nil, trx}})
+	})
+	if err != nil {
+		if isLedgerError(err) {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		writeJSONError(r, w, "create failed", http.StatusInternalServerError)
 		return
 	}
+	if suspects, err := h.duplicates.Detect(acc.HouseholdID, trx, db.DefaultDuplicateRules); err == nil {
+		for _, s := range suspects {
+			trx.DuplicateOf = append(trx.DuplicateOf, s.DuplicateOfID)
+		}
+	}
+	afterTransactionWrite(r, h.db, h.Notifications, acc.HouseholdID, trx.OccurredAt)
 
 	// Notification for large transaction
 	threshold := int64(25000) // $250 in cents
@@ -106,41 +178,6 @@ func (h *TransactionHandler) Create(w http.ResponseWriter, r *http.Request, acco
 		}
 	}
 
-	// Goal progress notifications (50% and 100%)
-	goalStore := db.GoalStore{DB: h.db}
-	goals, _ := goalStore.ListByUser(user.ID)
-	for _, goal := range goals {
-		if goal.TargetCents == 0 {
-			continue
-		}
-		progress := float64(goal.CurrentCents) / float64(goal.TargetCents)
-		if progress >= 0.5 && progress < 1.0 {
-			msg := "Goal '" + goal.Name + "' is 50% complete!"
-			n := &models.Notification{
-				UserID:  int64(user.ID),
-				Type:    models.NotificationTypeGoal,
-				Message: msg,
-				Read:    false,
-				CreatedAt: time.Now(),
-			}
-			if h.Notifications != nil {
-				h.Notifications.CreateNotification(r.Context(), n)
-			}
-		} else if progress >= 1.0 {
-			msg := "Goal '" + goal.Name + "' is complete!"
-			n := &models.Notification{
-				UserID:  int64(user.ID),
-				Type:    models.NotificationTypeGoal,
-				Message: msg,
-				Read:    false,
-				CreatedAt: time.Now(),
-			}
-			if h.Notifications != nil {
-				h.Notifications.CreateNotification(r.Context(), n)
-			}
-		}
-	}
-
 	writeJSONSuccess(r, w, "created", trx)
 }
 
@@ -156,32 +193,461 @@ func (h *TransactionHandler) List(w http.ResponseWriter, r *http.Request, accoun
 		return
 	}
 	var acc models.Account
-	if err := h.db.First(&acc, uint(accID)).Error; err != nil {
+	if err := h.db.Where("type <> ?", models.AccountTypeExternal).First(&acc, uint(accID)).Error; err != nil {
 		writeJSONError(r, w, "account not found", http.StatusNotFound)
 		return
 	}
-	isMember, _ := userIsHouseholdMember(h.db, user.ID, acc.HouseholdID)
-	if !isMember {
+	if !userCan(h.db, user.ID, acc.HouseholdID, permissions.View) {
 		writeJSONError(r, w, "forbidden", http.StatusForbidden)
 		return
 	}
 
-	query := h.db.Where("account_id = ?", acc.ID)
+	filter, err := transactionFilterFromQuery(r.URL.Query())
+	if err != nil {
+		writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.AccountID = acc.ID
+	h.writePage(w, r, filter)
+}
+
+// Search lists transactions across every account in the household, with
+// the same filters and pagination as List.
+func (h *TransactionHandler) Search(w http.ResponseWriter, r *http.Request, householdIDStr string) {
+	_, hID, ok := authorizeHousehold(h.db, w, r, householdIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	filter, err := transactionFilterFromQuery(r.URL.Query())
+	if err != nil {
+		writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.HouseholdID = hID
+	h.writePage(w, r, filter)
+}
+
+func (h *TransactionHandler) writePage(w http.ResponseWriter, r *http.Request, filter db.TransactionFilter) {
+	store := db.TransactionStore{DB: h.db}
+	txs, next, err := store.Search(filter)
+	if errors.Is(err, db.ErrInvalidCursor) {
+		writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", map[string]any{"items": txs, "next_cursor": next})
+}
+
+// transactionFilterFromQuery reads start, end (RFC3339), category_id,
+// min_amount, max_amount (cents), uncategorized, user_id, q, cursor and limit.
+func transactionFilterFromQuery(q url.Values) (db.TransactionFilter, error) {
+	var f db.TransactionFilter
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"start", &f.Start}, {"end", &f.End}} {
+		if v := q.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return f, fmt.Errorf("%s must be RFC3339", p.name)
+			}
+			*p.dst = &t
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **uint
+	}{{"category_id", &f.CategoryID}, {"user_id", &f.UserID}} {
+		if v := q.Get(p.name); v != "" {
+			id, ok := parseUintString(v)
+			if !ok {
+				return f, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = &id
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **int64
+	}{{"min_amount", &f.MinAmount}, {"max_amount", &f.MaxAmount}} {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return f, fmt.Errorf("%s must be an integer number of cents", p.name)
+			}
+			*p.dst = &n
+		}
+	}
+	if v := q.Get("uncategorized"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New("uncategorized must be true or false")
+		}
+		f.Uncategorized = b
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return f, errors.New("limit must be a positive integer")
+		}
+		f.Limit = n
+	}
+	f.Query = q.Get("q")
+	f.Cursor = q.Get("cursor")
+	return f, nil
+}
+
+// Get returns a single transaction leg with its splits.
+func (h *TransactionHandler) Get(w http.ResponseWriter, r *http.Request, transactionIDStr string) {
+	leg, _, ok := h.loadLeg(w, r, transactionIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	writeJSONSuccess(r, w, "ok", leg)
+}
+
+type patchTransactionRequest struct {
+	AmountCents *int64  `json:"amount_cents"`
+	Memo        *string `json:"memo"`
+	OccurredAt  *string `json:"occurred_at"`
+	// CategoryID may be null to clear the category; omit it to leave it unchanged.
+	CategoryID json.RawMessage `json:"category_id"`
+}
 
-	start := r.URL.Query().Get("start")
-	end := r.URL.Query().Get("end")
-	if start != "" {
-		if t, err := time.Parse(time.RFC3339, start); err == nil {
-			query = query.Where("occurred_at >= ?", t)
+func (h *TransactionHandler) Update(w http.ResponseWriter, r *http.Request, transactionIDStr string) {
+	leg, acc, ok := h.loadLeg(w, r, transactionIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	user, _ := middleware.UserFrom(r.Context())
+	var req patchTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	patch := db.TransactionPatch{AmountCents: req.AmountCents}
+	if req.Memo != nil {
+		memo := sanitizeString(*req.Memo)
+		patch.Memo = &memo
+	}
+	if req.OccurredAt != nil {
+		t, err := time.Parse(time.RFC3339, *req.OccurredAt)
+		if err != nil {
+			writeJSONError(r, w, "occurred_at must be RFC3339", http.StatusBadRequest)
+			return
 		}
+		patch.OccurredAt = &t
 	}
-	if end != "" {
-		if t, err := time.Parse(time.RFC3339, end); err == nil {
-			query = query.Where("occurred_at <= ?", t)
+	if len(req.CategoryID) > 0 {
+		patch.SetCategory = true
+		if string(req.CategoryID) != "null" {
+			var id uint
+			if err := json.Unmarshal(req.CategoryID, &id); err != nil || !h.householdCategory(acc.HouseholdID, id) {
+				writeJSONError(r, w, "invalid category_id", http.StatusBadRequest)
+				return
+			}
+			patch.CategoryID = &id
 		}
 	}
+	store := db.TransactionStore{DB: h.db}
+	before := leg.OccurredAt
+	if err := store.Update(acc.HouseholdID, user.ID, leg, patch); err != nil {
+		if isLedgerError(err) {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		return
+	}
+	afterTransactionWrite(r, h.db, h.Notifications, acc.HouseholdID, before, leg.OccurredAt)
+	writeJSONSuccess(r, w, "updated", leg)
+}
 
-	var txs []models.Transaction
-	query.Order("occurred_at desc").Limit(500).Find(&txs)
-	writeJSONSuccess(r, w, "ok", txs)
-}
\ No newline at end of file
+// Delete removes the transaction together with the rest of its journal entry.
+func (h *TransactionHandler) Delete(w http.ResponseWriter, r *http.Request, transactionIDStr string) {
+	leg, acc, ok := h.loadLeg(w, r, transactionIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	user, _ := middleware.UserFrom(r.Context())
+	store := db.TransactionStore{DB: h.db}
+	if err := store.Delete(acc.HouseholdID, user.ID, leg); err != nil {
+		writeJSONError(r, w, "delete failed", http.StatusInternalServerError)
+		return
+	}
+	afterTransactionWrite(r, h.db, h.Notifications, acc.HouseholdID, leg.OccurredAt)
+	writeJSONSuccess(r, w, "deleted", map[string]any{"id": leg.ID, "entry_id": leg.EntryID})
+}
+
+// Audit returns the change history of a transaction.
+func (h *TransactionHandler) Audit(w http.ResponseWriter, r *http.Request, transactionIDStr string) {
+	leg, _, ok := h.loadLeg(w, r, transactionIDStr, permissions.View)
+	if !ok {
+		return
+	}
+	store := db.TransactionStore{DB: h.db}
+	trail, err := store.ListAudit(leg.ID)
+	if err != nil {
+		writeJSONError(r, w, "list failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSONSuccess(r, w, "ok", trail)
+}
+
+// maxBulkTransactions bounds how many transactions one bulk request may touch.
+const maxBulkTransactions = 500
+
+type bulkTransactionRequest struct {
+	IDs        []uint  `json:"ids"`
+	Action     string  `json:"action"` // recategorize, redate, delete
+	CategoryID *uint   `json:"category_id"`
+	OccurredAt *string `json:"occurred_at"`
+}
+
+// Bulk recategorizes, re-dates or deletes many transactions at once. It is
+// all-or-nothing: one inaccessible or invalid transaction fails the request.
+func (h *TransactionHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.UserFrom(r.Context())
+	if !ok {
+		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req bulkTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) == 0 || len(req.IDs) > maxBulkTransactions {
+		writeJSONError(r, w, fmt.Sprintf("ids must list 1 to %d transactions", maxBulkTransactions), http.StatusBadRequest)
+		return
+	}
+	patch := db.TransactionPatch{}
+	switch req.Action {
+	case "recategorize":
+		patch.SetCategory, patch.CategoryID = true, req.CategoryID
+	case "redate":
+		if req.OccurredAt == nil {
+			writeJSONError(r, w, "occurred_at required", http.StatusBadRequest)
+			return
+		}
+		t, err := time.Parse(time.RFC3339, *req.OccurredAt)
+		if err != nil {
+			writeJSONError(r, w, "occurred_at must be RFC3339", http.StatusBadRequest)
+			return
+		}
+		patch.OccurredAt = &t
+	case "delete":
+	default:
+		writeJSONError(r, w, "action must be recategorize, redate or delete", http.StatusBadRequest)
+		return
+	}
+
+	var legs []models.Transaction
+	h.db.Joins("JOIN accounts a ON a.id = transactions.account_id").
+		Where("transactions.id IN ? AND a.type <> ?", req.IDs, models.AccountTypeExternal).
+		Find(&legs)
+	if len(legs) != len(uniqueUints(req.IDs)) {
+		writeJSONError(r, w, "transaction not found", http.StatusNotFound)
+		return
+	}
+	households := map[uint]uint{}
+	for _, leg := range legs {
+		if _, seen := households[leg.AccountID]; seen {
+			continue
+		}
+		var acc models.Account
+		h.db.First(&acc, leg.AccountID)
+		if !userCan(h.db, user.ID, acc.HouseholdID, permissions.EditTransactions) {
+			writeJSONError(r, w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if patch.CategoryID != nil && !h.householdCategory(acc.HouseholdID, *patch.CategoryID) {
+			writeJSONError(r, w, "invalid category_id", http.StatusBadRequest)
+			return
+		}
+		households[leg.AccountID] = acc.HouseholdID
+	}
+
+	touched := map[uint][]time.Time{}
+	for _, leg := range legs {
+		hID := households[leg.AccountID]
+		touched[hID] = append(touched[hID], leg.OccurredAt)
+		if patch.OccurredAt != nil {
+			touched[hID] = append(touched[hID], *patch.OccurredAt)
+		}
+	}
+
+	var failedID uint
+	updated := 0
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		store := db.TransactionStore{DB: tx}
+		deletedEntries := map[uint]bool{}
+		updated = 0
+		for i := range legs {
+			leg := &legs[i]
+			failedID = leg.ID
+			hID := households[leg.AccountID]
+			if req.Action == "delete" {
+				// Two selected legs of one transfer share an entry.
+				if leg.EntryID != 0 {
+					if deletedEntries[leg.EntryID] {
+						continue
+					}
+					deletedEntries[leg.EntryID] = true
+				}
+				if err := store.Delete(hID, user.ID, leg); err != nil {
+					return err
+				}
+				updated++
+				continue
+			}
+			if err := store.Update(hID, user.ID, leg, patch); err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		if isLedgerError(err) {
+			writeJSONError(r, w, fmt.Sprintf("transaction %d: %v", failedID, err), http.StatusBadRequest)
+			return
+		}
+		writeJSONError(r, w, "bulk update failed", http.StatusInternalServerError)
+		return
+	}
+	for hID, times := range touched {
+		afterTransactionWrite(r, h.db, h.Notifications, hID, times...)
+	}
+	writeJSONSuccess(r, w, "ok", map[string]any{"action": req.Action, "updated": updated})
+}
+
+// householdCategory reports whether the category belongs to the household.
+func (h *TransactionHandler) householdCategory(householdID, categoryID uint) bool {
+	var n int64
+	h.db.Model(&models.Category{}).Where("id = ? AND household_id = ?", categoryID, householdID).Count(&n)
+	return n > 0
+}
+
+// afterTransactionWrite evaluates budget alerts for the months touched and
+// the milestones of goals tracking the household.
+func afterTransactionWrite(r *http.Request, gdb *gorm.DB, notifications *db.NotificationStore, householdID uint, times ...time.Time) {
+	evaluateBudgetAlerts(r, gdb, notifications, householdID, times...)
+	evaluateHouseholdGoals(r, gdb, notifications, householdID)
+}
+
+func uniqueUints(ids []uint) map[uint]struct{} {
+	out := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		out[id] = struct{}{}
+	}
+	return out
+}
+
+// SetSplits replaces a transaction's category splits. An empty list removes
+// the split and leaves the transaction uncategorized.
+func (h *TransactionHandler) SetSplits(w http.ResponseWriter, r *http.Request, transactionIDStr string) {
+	leg, acc, ok := h.loadLeg(w, r, transactionIDStr, permissions.EditTransactions)
+	if !ok {
+		return
+	}
+	var req struct {
+		Splits []splitRequest `json:"splits"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(r, w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	splits, ok := h.householdSplits(acc.HouseholdID, req.Splits)
+	if !ok {
+		writeJSONError(r, w, "invalid split category", http.StatusBadRequest)
+		return
+	}
+	user, _ := middleware.UserFrom(r.Context())
+	before, category := leg.Splits, leg.CategoryID
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		splitStore := db.SplitStore{DB: tx}
+		if err := splitStore.Replace(leg, splits); err != nil {
+			return err
+		}
+		changes := map[string][2]any{"splits": {before, leg.Splits}}
+		if category != nil && leg.CategoryID == nil {
+			changes["category_id"] = [2]any{category, leg.CategoryID}
+		}
+		auditStore := db.TransactionStore{DB: tx}
+		return auditStore.RecordAudit(acc.HouseholdID, user.ID, leg.ID, models.AuditUpdate, changes)
+	})
+	if err != nil {
+		if isLedgerError(err) {
+			writeJSONError(r, w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSONError(r, w, "update failed", http.StatusInternalServerError)
+		return
+	}
+	afterTransactionWrite(r, h.db, h.Notifications, acc.HouseholdID, leg.OccurredAt)
+	writeJSONSuccess(r, w, "updated", leg)
+}
+
+// loadLeg fetches a transaction on a real account the user may act on,
+// writing the error response itself when it returns false.
+func (h *TransactionHandler) loadLeg(w http.ResponseWriter, r *http.Request, transactionIDStr string, action permissions.Action) (*models.Transaction, *models.Account, bool) {
+	user, ok := middleware.UserFrom(r.Context())
+	if !ok {
+		writeJSONError(r, w, "unauthorized", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+	txID, valid := parseUintString(transactionIDStr)
+	if !valid {
+		writeJSONError(r, w, "invalid transaction id", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	var leg models.Transaction
+	if err := h.db.Preload("Splits").First(&leg, txID).Error; err != nil {
+		writeJSONError(r, w, "transaction not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	var acc models.Account
+	if err := h.db.Where("type <> ?", models.AccountTypeExternal).First(&acc, leg.AccountID).Error; err != nil {
+		writeJSONError(r, w, "transaction not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	if !userCan(h.db, user.ID, acc.HouseholdID, action) {
+		writeJSONError(r, w, "forbidden", http.StatusForbidden)
+		return nil, nil, false
+	}
+	return &leg, &acc, true
+}
+
+// householdSplits converts split requests, checking every category belongs
+// to the household.
+func (h *TransactionHandler) householdSplits(householdID uint, reqs []splitRequest) ([]models.TransactionSplit, bool) {
+	splits := make([]models.TransactionSplit, 0, len(reqs))
+	ids := map[uint]struct{}{}
+	for _, s := range reqs {
+		splits = append(splits, models.TransactionSplit{CategoryID: s.CategoryID, AmountCents: s.AmountCents, Memo: sanitizeString(s.Memo)})
+		ids[s.CategoryID] = struct{}{}
+	}
+	if len(ids) == 0 {
+		return splits, true
+	}
+	list := make([]uint, 0, len(ids))
+	for id := range ids {
+		list = append(list, id)
+	}
+	var n int64
+	h.db.Model(&models.Category{}).Where("id IN ? AND household_id = ?", list, householdID).Count(&n)
+	return splits, int(n) == len(list)
+}
+
+// isLedgerError reports whether err is a client-side double-entry or split violation.
+func isLedgerError(err error) bool {
+	return errors.Is(err, db.ErrUnbalancedEntry) || errors.Is(err, db.ErrInvalidEntry) ||
+		errors.Is(err, db.ErrMixedCurrency) || errors.Is(err, db.ErrForeignAccount) ||
+		errors.Is(err, db.ErrSplitMismatch) || errors.Is(err, db.ErrInvalidSplit) || errors.Is(err, db.ErrTransferSplit) ||
+		errors.Is(err, db.ErrAmountNotEditable) || errors.Is(err, db.ErrTransferCategory) || errors.Is(err, db.ErrSplitCategorized) ||
+		errors.Is(err, db.ErrAccountClosed)
+}
@@ -35,7 +35,8 @@ func (h *UserSettingsHandler) Upsert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var req struct {
-		LargeTransactionThreshold int64 `json:"large_transaction_threshold"`
+		LargeTransactionThreshold int64  `json:"large_transaction_threshold"`
+		LowBalanceThreshold       *int64 `json:"low_balance_threshold"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request", http.StatusBadRequest)
@@ -46,5 +47,11 @@ func (h *UserSettingsHandler) Upsert(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "update failed", http.StatusInternalServerError)
 		return
 	}
+	if req.LowBalanceThreshold != nil {
+		if err := store.SetLowBalanceThreshold(user.ID, *req.LowBalanceThreshold); err != nil {
+			http.Error(w, "update failed", http.StatusInternalServerError)
+			return
+		}
+	}
 	w.WriteHeader(http.StatusNoContent)
 }